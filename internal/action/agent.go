@@ -0,0 +1,71 @@
+package action
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/agent"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/server"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+)
+
+// Agent runs the session agent that keeps unlocked age identities in memory
+// until interrupted.
+func (s *Action) Agent(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	cfg := agent.Config{
+		CacheSecrets: c.Bool("cache-secrets"),
+	}
+
+	for flag, d := range map[string]*time.Duration{
+		"timeout":      &cfg.IdleTimeout,
+		"max-lifetime": &cfg.MaxLifetime,
+	} {
+		v := c.String(flag)
+		td, err := time.ParseDuration(v)
+		if err != nil || td < time.Second {
+			return exit.Error(exit.Usage, err, "Invalid duration for --%s: %q", flag, v)
+		}
+		*d = td
+	}
+
+	if err := agent.LockMemory(); err != nil {
+		out.Warningf(ctx, "Failed to lock memory, secrets may be swapped to disk: %s", err)
+	}
+
+	path := agent.DefaultSocket()
+	l, err := server.Listen(path)
+	if err != nil {
+		return exit.Error(exit.IO, err, "failed to create socket: %s", err)
+	}
+
+	out.Printf(ctx, "Agent listening on %s", path)
+	if err := agent.New(cfg).Serve(ctx, l); err != nil {
+		return exit.Error(exit.IO, err, "failed to serve: %s", err)
+	}
+
+	return nil
+}
+
+// AgentLock removes all identities and secrets from the running agent.
+func (s *Action) AgentLock(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if err := agent.NewClient(agent.DefaultSocket()).Lock(ctx); err != nil {
+		if errors.Is(err, agent.ErrNotRunning) {
+			out.Noticef(ctx, "Agent is not running")
+
+			return nil
+		}
+
+		return exit.Error(exit.IO, err, "failed to lock agent: %s", err)
+	}
+
+	out.OKf(ctx, "Agent locked")
+
+	return nil
+}
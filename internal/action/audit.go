@@ -1,8 +1,13 @@
 package action
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/kpitt/gopass/internal/action/exit"
 	"github.com/kpitt/gopass/internal/audit"
+	"github.com/kpitt/gopass/internal/hibp"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/tree"
 	"github.com/kpitt/gopass/pkg/ctxutil"
@@ -15,6 +20,27 @@ func (s *Action) Audit(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 
 	expiry := c.Int("expiry")
+
+	failOn, err := audit.ParseCategories(c.String("fail-on"))
+	if err != nil {
+		return exit.Error(exit.Usage, err, "%s", err)
+	}
+	policy := audit.Policy{
+		FailOn:   failOn,
+		MinScore: c.Int("min-score"),
+	}
+
+	output := c.String("output")
+	format := reportFormat(c.String("format"), output)
+	if format != "" && !isReportFormat(format) {
+		return exit.Error(exit.Usage, nil, "Unknown report format %q. Use one of %v", format, audit.Formats)
+	}
+
+	// a report on stdout replaces the summary.
+	if format != "" && output == "" {
+		ctx = ctxutil.WithHidden(ctx, true)
+	}
+
 	if expiry <= 0 {
 		_ = s.rem.Reset("audit")
 	}
@@ -40,5 +66,88 @@ func (s *Action) Audit(c *cli.Context) error {
 		return nil
 	}
 
-	return audit.Batch(ctx, list, s.Store, expiry)
+	opts := audit.Options{
+		Expiration: expiry,
+	}
+
+	switch {
+	case c.String("hibp-dump") != "":
+		dump, err := hibp.OpenDump(c.String("hibp-dump"))
+		if err != nil {
+			return exit.Error(exit.IO, err, "failed to open HIBP dump: %s", err)
+		}
+		defer func() {
+			_ = dump.Close()
+		}()
+
+		opts.Breaches = dump
+	case c.Bool("hibp"):
+		opts.Breaches = hibp.NewClient()
+	}
+
+	report := audit.Batch(ctx, list, s.Store, opts)
+
+	switch {
+	case output != "":
+		if err := writeReport(output, format, report); err != nil {
+			return exit.Error(exit.IO, err, "failed to write report: %s", err)
+		}
+		report.PrintResults(ctx)
+		out.OKf(ctx, "Wrote audit report to %s", output)
+	case format != "":
+		if err := report.Write(stdout, format); err != nil {
+			return exit.Error(exit.IO, err, "failed to write report: %s", err)
+		}
+	default:
+		report.PrintResults(ctx)
+	}
+
+	if err := report.Check(policy); err != nil {
+		return exit.Error(exit.Audit, err, "%s", err)
+	}
+
+	return nil
+}
+
+// reportFormat returns the report format. Without an explicit format it is
+// derived from the extension of the output file.
+func reportFormat(format, output string) string {
+	if format != "" || output == "" {
+		return strings.ToLower(format)
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(output)), ".")
+	if ext == "htm" {
+		ext = "html"
+	}
+	if isReportFormat(ext) {
+		return ext
+	}
+
+	return "json"
+}
+
+func isReportFormat(format string) bool {
+	for _, f := range audit.Formats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeReport(fn, format string, report *audit.Report) error {
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := report.Write(fh, format); err != nil {
+		_ = fh.Close()
+
+		return err
+	}
+
+	return fh.Close()
 }
@@ -3,9 +3,13 @@ package action
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/kpitt/gopass/internal/audit"
+	"github.com/kpitt/gopass/internal/hibp"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/pkg/ctxutil"
 	"github.com/kpitt/gopass/pkg/gopass/secrets"
@@ -49,6 +53,48 @@ func TestAudit(t *testing.T) { //nolint:paralleltest
 		buf.Reset()
 	})
 
+	t.Run("breached passwords from a dump", func(t *testing.T) { //nolint:paralleltest
+		fn := filepath.Join(u.Dir, "pwned.txt")
+		require.NoError(t, os.WriteFile(fn, []byte(hibp.Hash("123")+":42\r\n"), 0o644))
+
+		assert.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"hibp-dump": fn})))
+		assert.Contains(t, buf.String(), "Found in data breaches:\n\t- bar (seen 42 times)\n\t- baz (seen 42 times)\n")
+		buf.Reset()
+
+		assert.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"hibp-dump": fn + ".missing"})))
+		buf.Reset()
+	})
+
+	t.Run("report and policies", func(t *testing.T) { //nolint:paralleltest
+		fn := filepath.Join(u.Dir, "report.csv")
+		err := act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"output": fn}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate")
+
+		rows, err := os.ReadFile(fn)
+		require.NoError(t, err)
+		assert.Contains(t, string(rows), "name,score,changed,breaches,duplicates,findings,error\n")
+		assert.Contains(t, string(rows), "bar,0,")
+		buf.Reset()
+
+		// only fail on things that are not there.
+		assert.NoError(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "expired", "format": "json"})))
+		var report audit.Report
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		require.Len(t, report.Secrets, 3)
+		assert.Equal(t, []string{"baz"}, report.Secrets[0].Duplicates)
+		buf.Reset()
+
+		err = act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "none", "min-score": "1"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3 with a score below 1")
+		buf.Reset()
+
+		assert.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "foo"})))
+		assert.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "pdf"})))
+		buf.Reset()
+	})
+
 	t.Run("test empty store", func(t *testing.T) { //nolint:paralleltest
 		for _, v := range []string{"foo", "bar", "baz"} {
 			assert.NoError(t, act.Store.Delete(ctx, v))
@@ -29,6 +29,9 @@ func (s *Action) Clone(c *cli.Context) error {
 	if c.IsSet("crypto") {
 		ctx = backend.WithCryptoBackendString(ctx, c.String("crypto"))
 	}
+	if c.IsSet("storage") {
+		ctx = backend.WithStorageBackendString(ctx, c.String("storage"))
+	}
 
 	path := c.String("path")
 
@@ -111,7 +114,11 @@ func (s *Action) clone(ctx context.Context, repo, mount, path string) error {
 
 	// clone repo.
 	out.Noticef(ctx, "Cloning repository %q to %q...", repo, path)
-	if _, err := backend.Clone(ctx, backend.GitFS, repo, path); err != nil {
+	be := backend.GitFS
+	if backend.HasStorageBackend(ctx) {
+		be = backend.GetStorageBackend(ctx)
+	}
+	if _, err := backend.Clone(ctx, be, repo, path); err != nil {
 		return exit.Error(exit.Git, err, "failed to clone repo %q to %q: %s", repo, path, err)
 	}
 
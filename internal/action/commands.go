@@ -3,7 +3,11 @@ package action
 import (
 	"fmt"
 
+	"github.com/kpitt/gopass/internal/audit"
 	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/exporter"
+	"github.com/kpitt/gopass/internal/importer"
+	"github.com/kpitt/gopass/internal/keygen"
 	"github.com/kpitt/gopass/pkg/debug"
 	"github.com/urfave/cli/v2"
 )
@@ -36,6 +40,10 @@ func ShowFlags() []cli.Flag {
 			Aliases: []string{"r"},
 			Usage:   "Show a past revision. Does NOT support Git shortcuts. Use exact revision or -<N> to select the Nth oldest revision of this entry.",
 		},
+		&cli.StringFlag{
+			Name:  "at",
+			Usage: "Show the revision that was current at this date, e.g. 2023-01-31 or 2023-01-31 18:00. A date without a time means the end of that day",
+		},
 		&cli.BoolFlag{
 			Name:    "noparsing",
 			Aliases: []string{"n"},
@@ -45,19 +53,58 @@ func ShowFlags() []cli.Flag {
 			Name:  "chars",
 			Usage: "Print specific characters from the secret",
 		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print a machine-readable JSON document",
+		},
 	}
 }
 
 // GetCommands returns the cli commands exported by this module.
 func (s *Action) GetCommands() []*cli.Command {
 	cmds := []*cli.Command{
+		{
+			Name:  "agent",
+			Usage: "Keep unlocked age identities in memory",
+			Description: "" +
+				"Run a session agent on a Unix domain socket that keeps the unlocked age " +
+				"identities, and optionally decrypted secrets, in memory. Other gopass " +
+				"processes use it automatically, so the passphrase is only asked for once. " +
+				"Entries are removed after the idle timeout, the maximum lifetime or when " +
+				"the agent is locked.",
+			Action: s.Agent,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "timeout",
+					Usage: "Remove entries that were not used for this duration",
+					Value: "15m",
+				},
+				&cli.StringFlag{
+					Name:  "max-lifetime",
+					Usage: "Remove entries this long after they were added",
+					Value: "8h",
+				},
+				&cli.BoolFlag{
+					Name:  "cache-secrets",
+					Usage: "Cache decrypted secrets, too",
+				},
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:        "lock",
+					Usage:       "Remove all entries from the agent",
+					Description: "Remove all unlocked identities and secrets from the running agent.",
+					Action:      s.AgentLock,
+				},
+			},
+		},
 		{
 			Name:      "audit",
 			Usage:     "Decrypt all secrets and scan for weak or leaked passwords",
 			ArgsUsage: "[filter]",
 			Description: "" +
 				"This command decrypts all secrets and checks for common flaws and (optionally) " +
-				"against a list of previously leaked passwords.",
+				"against a list of previously leaked passwords from Have I Been Pwned.",
 			Before: s.IsInitialized,
 			Action: s.Audit,
 			Flags: []cli.Flag{
@@ -65,6 +112,31 @@ func (s *Action) GetCommands() []*cli.Command {
 					Name:  "expiry",
 					Usage: "Age in days before a password is considered expired. Setting this will only check expiration.",
 				},
+				&cli.BoolFlag{
+					Name:  "hibp",
+					Usage: "Check passwords against the Have I Been Pwned API. Only the first 5 characters of the SHA1 hash are sent",
+				},
+				&cli.StringFlag{
+					Name:  "hibp-dump",
+					Usage: "Check passwords against a local Have I Been Pwned dump (SHA1, ordered by hash) instead of the API",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: fmt.Sprintf("Write a report in this format %v. Without --output it is printed instead of the summary", audit.Formats),
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Write the report to this file. The format defaults to the file extension",
+				},
+				&cli.StringFlag{
+					Name:  "fail-on",
+					Usage: fmt.Sprintf("Only fail on these comma separated categories %v, 'all' or 'none'. Default: all", audit.Categories),
+				},
+				&cli.IntFlag{
+					Name:  "min-score",
+					Usage: "Fail if any password has a lower zxcvbn score (0-4)",
+				},
 			},
 		},
 		{
@@ -102,6 +174,10 @@ func (s *Action) GetCommands() []*cli.Command {
 					Name:  "crypto",
 					Usage: fmt.Sprintf("Select crypto backend %v", backend.CryptoRegistry.BackendNames()),
 				},
+				&cli.StringFlag{
+					Name:  "storage",
+					Usage: fmt.Sprintf("Select storage backend %v", backend.StorageRegistry.BackendNames()),
+				},
 				&cli.BoolFlag{
 					Name:  "check-keys",
 					Usage: "Check for valid decryption keys. Generate new keys if none are found.",
@@ -117,9 +193,53 @@ func (s *Action) GetCommands() []*cli.Command {
 				"This command allows for easy printing and editing of the configuration. " +
 				"Without argument, the entire config is printed. " +
 				"With a single argument, a single key can be printed. " +
-				"With two arguments a setting specified by key can be set to value.",
+				"With two arguments a setting specified by key can be set to value. " +
+				"With --store the effective config of that mount is printed or changed.",
 			Action:       s.Config,
 			BashComplete: s.ConfigComplete,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "store",
+					Aliases: []string{"s"},
+					Usage:   "Show or change the config of this mount. Use 'root' for the root store",
+				},
+			},
+		},
+		{
+			Name:  "convert",
+			Usage: "Convert a store to another crypto backend",
+			Description: "" +
+				"This command re-encrypts all secrets of a store with another crypto backend, " +
+				"e.g. to move a store from GPG to age. The recipients are replaced using a " +
+				"mapping file with one '<old recipient> <new recipient>' pair per line. All " +
+				"changes are committed at once. An interrupted conversion is resumed by " +
+				"running the same command again. With --keep the secrets are encrypted with " +
+				"both backends until the conversion is finished without --keep.",
+			Before: s.IsInitialized,
+			Action: s.Convert,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "store",
+					Usage: "Store to convert",
+				},
+				&cli.StringFlag{
+					Name:  "crypto",
+					Usage: fmt.Sprintf("Crypto backend to convert to %v", backend.CryptoRegistry.BackendNames()),
+				},
+				&cli.StringFlag{
+					Name:  "mapping",
+					Usage: "File that maps the old recipients to the new ones",
+				},
+				&cli.BoolFlag{
+					Name:  "keep",
+					Usage: "Keep the old backend and encrypt all secrets with both backends",
+				},
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Always answer yes to yes/no questions",
+				},
+			},
 		},
 		{
 			Name:      "copy",
@@ -189,6 +309,19 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "docker-credential",
+			Usage:     "Use gopass as docker credential helper",
+			ArgsUsage: "get|store|erase|list",
+			Description: "" +
+				"This command implements the docker credential helper protocol. " +
+				"Credentials are stored as YAML secrets under the prefix set by the " +
+				"dockercredentialprefix config option (default: docker). Symlink the " +
+				"gopass binary to docker-credential-gopass somewhere in your PATH and " +
+				"set \"credsStore\": \"gopass\" in ~/.docker/config.json.",
+			Before: s.IsInitialized,
+			Action: s.DockerCredential,
+		},
 		{
 			Name:      "edit",
 			Usage:     "Edit new or existing secrets",
@@ -219,6 +352,105 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "env",
+			Usage:     "Run a command with secrets in its environment",
+			ArgsUsage: "<secret-or-folder> -- <command> [args...]",
+			Description: "" +
+				"This command decrypts a secret, or all secrets below a folder, and runs " +
+				"the command with the passwords and keys as environment variables. The " +
+				"password is named after the path of the secret below the folder, e.g. " +
+				"PROD for db/prod when running with db, and keys are prefixed with the " +
+				"same name, e.g. PROD_USER. The values never appear on the command line.",
+			Before:       s.IsInitialized,
+			Action:       s.Env,
+			BashComplete: s.Complete,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Prefix for all variable names",
+				},
+				&cli.BoolFlag{
+					Name:  "keep-case",
+					Usage: "Keep the case of secret names and keys instead of converting them to upper case",
+				},
+				&cli.StringSliceFlag{
+					Name:  "map",
+					Usage: "Use VAR for a password or key, given as key=VAR. Can be given multiple times, e.g. --map prod/user=DB_USER",
+				},
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Override variables that are already set in the environment",
+				},
+			},
+		},
+		{
+			Name:      "expiring",
+			Usage:     "List secrets that are expired or expire soon",
+			ArgsUsage: "[prefix]",
+			Description: "" +
+				"This command lists all secrets that are expired or expire within the " +
+				"given number of days, across all mounts. A secret expires on the date " +
+				"in its 'expires' key or after the interval in its 'rotate-every' key " +
+				"since it was last changed. Secrets without either key expire --expiry " +
+				"days after their last revision.",
+			Before: s.IsInitialized,
+			Action: s.Expiring,
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "days",
+					Usage: "Include secrets that expire within this many days",
+					Value: 30,
+				},
+				&cli.IntFlag{
+					Name:  "expiry",
+					Usage: "Age in days before a secret without expiration keys is considered expired",
+					Value: 365,
+				},
+				&cli.StringFlag{
+					Name:    "store",
+					Aliases: []string{"s"},
+					Usage:   "Only check this mount",
+				},
+			},
+		},
+		{
+			Name:      "export",
+			Usage:     "Export decrypted secrets",
+			ArgsUsage: "[prefix]",
+			Description: "" +
+				"Write the decrypted secrets and templates of the store, a single " +
+				"mount or a folder to a JSON, CSV or KeePass (KDBX 4) file or to an " +
+				"age encrypted tar archive. JSON and age exports can include the " +
+				"history of every secret.",
+			Before: s.IsInitialized,
+			Action: s.Export,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: fmt.Sprintf("Format of the export %v", exporter.Formats()),
+					Value: "json",
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Write the export to this file instead of stdout",
+				},
+				&cli.StringFlag{
+					Name:    "store",
+					Aliases: []string{"s"},
+					Usage:   "Only export this mount",
+				},
+				&cli.BoolFlag{
+					Name:  "history",
+					Usage: "Include all past revisions of every secret",
+				},
+				&cli.StringSliceFlag{
+					Name:  "recipient",
+					Usage: "Encrypt age archives for this age or SSH public key. Can be given multiple times",
+				},
+			},
+		},
 		{
 			Name:      "find",
 			Usage:     "Search for secrets",
@@ -229,6 +461,12 @@ func (s *Action) GetCommands() []*cli.Command {
 			Action:       s.Find,
 			Aliases:      []string{"search"},
 			BashComplete: s.Complete,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print a machine-readable JSON document",
+				},
+			},
 		},
 		{
 			Name:      "fsck",
@@ -285,7 +523,9 @@ func (s *Action) GetCommands() []*cli.Command {
 			ArgsUsage: "[secret [key [length]|length]]",
 			Description: "" +
 				"Dialog to generate a new password and write it into a new or existing secret. " +
-				"By default, the new password will replace the first line of an existing secret (or create a new one).",
+				"By default, the new password will replace the first line of an existing secret (or create a new one). " +
+				"With --type a new secret with typed key material is created instead. It stores the public " +
+				"part in the public key, so 'gopass show <secret> public' can be shared safely.",
 			Before:       s.IsInitialized,
 			Action:       s.Generate,
 			BashComplete: s.CompleteGenerate,
@@ -318,7 +558,7 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.StringFlag{
 					Name:    "generator",
 					Aliases: []string{"g"},
-					Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd or external. Default: cryptic",
+					Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd or external. Default: the generator config option (cryptic)",
 				},
 				&cli.BoolFlag{
 					Name:  "strict",
@@ -336,6 +576,11 @@ func (s *Action) GetCommands() []*cli.Command {
 					Usage:   "Language to generate password from, currently only en (english, default) is supported",
 					Value:   "en",
 				},
+				&cli.StringFlag{
+					Name:    "type",
+					Aliases: []string{"t"},
+					Usage:   fmt.Sprintf("Generate key material of this type %v instead of a password. The length sets the bits of RSA keys and the bytes of tokens", keygen.Types()),
+				},
 			},
 		},
 		{
@@ -377,13 +622,74 @@ Use the "git init" command if the store does not yet have a git repository.`,
 				},
 			},
 		},
+		{
+			Name:  "git-credential",
+			Usage: "Use gopass as git credential helper",
+			Description: "" +
+				"These commands implement the git credential helper protocol. They read " +
+				"a request from stdin and store the credentials under the prefix set by " +
+				"the gitcredentialprefix config option (default: git), e.g. " +
+				"git/https/github.com. Enable the helper with: " +
+				"git config --global credential.helper '!gopass git-credential'",
+			Before: s.IsInitialized,
+			Subcommands: []*cli.Command{
+				{
+					Name:        "get",
+					Usage:       "Get a credential",
+					Description: "Print the username and password of a matching secret, if any.",
+					Before:      s.IsInitialized,
+					Action:      s.GitCredentialGet,
+				},
+				{
+					Name:        "store",
+					Usage:       "Store a credential",
+					Description: "Save the username and password git used successfully.",
+					Before:      s.IsInitialized,
+					Action:      s.GitCredentialStore,
+				},
+				{
+					Name:        "erase",
+					Usage:       "Erase a credential",
+					Description: "Remove a credential that git rejected.",
+					Before:      s.IsInitialized,
+					Action:      s.GitCredentialErase,
+				},
+			},
+		},
+		{
+			Name:  "git-merge-driver",
+			Usage: "Merge two versions of an encrypted secret",
+			Description: "" +
+				"This command is a git merge driver. It decrypts the common ancestor and both " +
+				"versions of a secret, merges the password, each key and the body separately " +
+				"and encrypts the result for the current recipients. Conflicting changes are " +
+				"resolved in an editor if a terminal is available. Stores initialized by " +
+				"gopass are configured to use it.",
+			ArgsUsage: "<base> <ours> <theirs> <path>",
+			Hidden:    true,
+			Before:    s.IsInitialized,
+			Action:    s.GitMergeDriver,
+		},
+		{
+			Name:  "git-textconv",
+			Usage: "Print the decrypted content of a secret file",
+			Description: "" +
+				"This command is a git textconv filter. It decrypts a secret file so " +
+				"git diff and git log -p show the changes in clear text. Stores initialized " +
+				"by gopass are configured to use it.",
+			ArgsUsage: "<file>",
+			Hidden:    true,
+			Before:    s.IsInitialized,
+			Action:    s.GitTextconv,
+		},
 		{
 			Name:      "grep",
 			Usage:     "Search for secrets files containing search-string when decrypted.",
-			ArgsUsage: "[needle]",
+			ArgsUsage: "[needle] [prefix]",
 			Description: "" +
-				"This command decrypts all secrets and performs a pattern matching on the " +
-				"content.",
+				"This command decrypts all secrets below prefix and performs a pattern matching on the " +
+				"content. The matching lines are printed. The search can be restricted to single " +
+				"keys, the password or the body of the secrets.",
 			Before: s.IsInitialized,
 			Action: s.Grep,
 			Flags: []cli.Flag{
@@ -392,6 +698,31 @@ Use the "git init" command if the store does not yet have a git repository.`,
 					Aliases: []string{"r"},
 					Usage:   "Interpret pattern as RE2 regular expression",
 				},
+				&cli.BoolFlag{
+					Name:    "ignore-case",
+					Aliases: []string{"i"},
+					Usage:   "Ignore case distinctions",
+				},
+				&cli.StringFlag{
+					Name:    "field",
+					Aliases: []string{"f"},
+					Usage:   "Only search these comma separated keys. Use 'password' and 'body' for the password and the body",
+				},
+				&cli.StringFlag{
+					Name:    "store",
+					Aliases: []string{"s"},
+					Usage:   "Only search this mount",
+				},
+				&cli.IntFlag{
+					Name:    "context",
+					Aliases: []string{"C"},
+					Usage:   "Print this many lines of context around every match",
+				},
+				&cli.BoolFlag{
+					Name:    "files-with-matches",
+					Aliases: []string{"l"},
+					Usage:   "Only print the names of matching secrets",
+				},
 			},
 		},
 		{
@@ -400,7 +731,8 @@ Use the "git init" command if the store does not yet have a git repository.`,
 			ArgsUsage: "[secret]",
 			Aliases:   []string{"hist"},
 			Description: "" +
-				"Display the change history for a secret",
+				"Display the change history for a secret. With --patch the changes of each " +
+				"revision are shown key by key, with all values masked unless --reveal is given.",
 			Before:       s.IsInitialized,
 			Action:       s.History,
 			BashComplete: s.Complete,
@@ -410,6 +742,52 @@ Use the "git init" command if the store does not yet have a git repository.`,
 					Aliases: []string{"p"},
 					Usage:   "Include passwords in output",
 				},
+				&cli.BoolFlag{
+					Name:  "patch",
+					Usage: "Show the keys changed by each revision",
+				},
+				&cli.BoolFlag{
+					Name:  "reveal",
+					Usage: "Show the values in patches instead of masking them",
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print a machine-readable JSON document",
+				},
+			},
+		},
+		{
+			Name:      "import",
+			Usage:     "Import secrets from other password managers",
+			ArgsUsage: "[file]",
+			Description: "" +
+				"Import the entries of a KeePass (KDBX 4), Bitwarden (unencrypted JSON) " +
+				"or 1Password (CSV) export. The format is detected from the file " +
+				"extension unless --format is given.",
+			Before: s.IsInitialized,
+			Action: s.Import,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: fmt.Sprintf("Format of the export %v", importer.Formats()),
+				},
+				&cli.StringFlag{
+					Name:  "folder",
+					Usage: "Import all secrets below this folder",
+				},
+				&cli.StringFlag{
+					Name:  "conflict",
+					Usage: "What to do with existing secrets: skip, overwrite or suffix",
+					Value: "skip",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only show what would be imported",
+				},
+				&cli.StringFlag{
+					Name:  "keyfile",
+					Usage: "Key file to unlock a KeePass database",
+				},
 			},
 		},
 		{
@@ -481,6 +859,29 @@ Use the "git init" command if the store does not yet have a git repository.`,
 				},
 			},
 		},
+		{
+			Name:  "jsonapi",
+			Usage: "Run the native messaging host for browser extensions",
+			Description: "" +
+				"The browser starts the native messaging host and exchanges length " +
+				"prefixed JSON messages with it to look up, fill and create logins. " +
+				"Secrets are matched by the host of the page and its known alias domains.",
+			Subcommands: []*cli.Command{
+				{
+					Name:        "listen",
+					Usage:       "Answer requests on stdin",
+					Description: "Answer the requests of a browser extension on stdin and stdout until the browser closes the connection.",
+					Before:      s.IsInitialized,
+					Action:      s.JSONAPIListen,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "path-only",
+							Usage: "Only match secrets by their name. This avoids decrypting all secrets on each query",
+						},
+					},
+				},
+			},
+		},
 		{
 			Name:      "link",
 			Usage:     "Create a symlink",
@@ -526,6 +927,10 @@ Use the "git init" command if the store does not yet have a git repository.`,
 					Aliases: []string{"s"},
 					Usage:   "Strip this prefix from filtered entries",
 				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print a machine-readable JSON document",
+				},
 			},
 		},
 		{
@@ -648,13 +1053,36 @@ Use the "git init" command if the store does not yet have a git repository.`,
 			},
 		},
 		{
-			Name:  "process",
-			Usage: "Process a template file",
+			Name:      "process",
+			Usage:     "Process a template file or directory",
+			ArgsUsage: "<FILE|DIR>",
 			Description: "" +
 				"This command processes a template file. It will read the template file " +
-				"and replace all variables with their values.",
+				"and replace all variables with their values. If given a directory, all " +
+				"*.tpl files below it are rendered into the output directory, keeping " +
+				"their relative paths and file modes.",
 			Before: s.IsInitialized,
 			Action: s.Process,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Output directory for rendered templates",
+				},
+				&cli.BoolFlag{
+					Name:  "check",
+					Usage: "Only report rendered files that differ from the files in the output directory",
+				},
+				&cli.BoolFlag{
+					Name:  "watch",
+					Usage: "Keep running, sync the stores and re-render templates when their secrets change",
+				},
+				&cli.StringFlag{
+					Name:  "interval",
+					Usage: "Time between syncs in watch mode",
+					Value: "5m",
+				},
+			},
 		},
 		{
 			Name:  "recipients",
@@ -664,6 +1092,12 @@ Use the "git init" command if the store does not yet have a git repository.`,
 				"The subcommands allow adding or removing recipients.",
 			Before: s.IsInitialized,
 			Action: s.RecipientsPrint,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print a machine-readable JSON document",
+				},
+			},
 			Subcommands: []*cli.Command{
 				{
 					Name:    "add",
@@ -717,6 +1151,46 @@ Use the "git init" command if the store does not yet have a git repository.`,
 				},
 			},
 		},
+		{
+			Name:      "restore",
+			Usage:     "Roll a secret back to an older revision",
+			ArgsUsage: "<secret> <revision>",
+			Description: "" +
+				"Write the content of an older revision of a secret as a new revision. The " +
+				"history is kept, so the restore can be undone. Use the history command to " +
+				"find the revision, or -<N> to select the Nth oldest revision.",
+			Before:       s.IsInitialized,
+			Action:       s.Restore,
+			BashComplete: s.Complete,
+		},
+		{
+			Name:  "serve",
+			Usage: "Serve the store API on a Unix socket",
+			Description: "" +
+				"Expose the secret store as a JSON API on a Unix domain socket. " +
+				"Only connections from the current user (or the users given with --uid) " +
+				"are accepted. Use --read-only and --allow to restrict what clients can do.",
+			Before: s.IsInitialized,
+			Action: s.Serve,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "socket",
+					Usage: "Path to the socket. Defaults to gopass/gopass.sock in the user runtime dir",
+				},
+				&cli.BoolFlag{
+					Name:  "read-only",
+					Usage: "Reject all requests that modify the store",
+				},
+				&cli.StringSliceFlag{
+					Name:  "allow",
+					Usage: "Only allow access to secrets below this prefix. Can be given multiple times",
+				},
+				&cli.IntSliceFlag{
+					Name:  "uid",
+					Usage: "Allow connections from this user ID. Defaults to the current user. Can be given multiple times",
+				},
+			},
+		},
 		{
 			Name:      "show",
 			Usage:     "Display the content of a secret",
@@ -729,6 +1203,35 @@ Use the "git init" command if the store does not yet have a git repository.`,
 			BashComplete: s.Complete,
 			Flags:        ShowFlags(),
 		},
+		{
+			Name:  "ssh-agent",
+			Usage: "Serve SSH keys from the store to SSH clients",
+			Description: "" +
+				"Run an SSH agent on a Unix domain socket that serves the private keys stored " +
+				"in gopass. Keys are loaded from all secrets below --prefix, or from all secrets " +
+				"marked with 'type: ssh-key' if no prefix is given. Encrypted keys are unlocked " +
+				"with the 'passphrase' key of the secret. Keys are only kept in memory.",
+			Before: s.IsInitialized,
+			Action: s.SSHAgent,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "socket",
+					Usage: "Path to the socket. Defaults to gopass/ssh-agent.sock in the user runtime dir",
+				},
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Load the keys from all secrets below this folder",
+				},
+				&cli.BoolFlag{
+					Name:  "confirm",
+					Usage: "Ask for confirmation before every use of a key",
+				},
+				&cli.StringFlag{
+					Name:  "lifetime",
+					Usage: "Remove the keys from the agent after this duration, e.g. 1h",
+				},
+			},
+		},
 		{
 			Name:      "sum",
 			Usage:     "Compute the SHA256 checksum",
@@ -813,6 +1316,16 @@ Use the "git init" command if the store does not yet have a git repository.`,
 				},
 			},
 		},
+		{
+			Name:      "undelete",
+			Usage:     "List or recover deleted secrets",
+			ArgsUsage: "[secret]",
+			Description: "" +
+				"Without arguments list all secrets that were deleted in the git history. " +
+				"With a secret name recover the content it had before it was deleted.",
+			Before: s.IsInitialized,
+			Action: s.Undelete,
+		},
 		{
 			Name:  "version",
 			Usage: "Display version",
@@ -6,6 +6,7 @@ import (
 	"sort"
 
 	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/config"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/pkg/ctxutil"
 	"github.com/urfave/cli/v2"
@@ -14,6 +15,10 @@ import (
 // Config handles changes to the gopass configuration.
 func (s *Action) Config(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	if c.IsSet("store") {
+		return s.mountConfigCmd(ctx, c)
+	}
+
 	if c.Args().Len() < 1 {
 		s.printConfigValues(ctx)
 
@@ -57,6 +62,95 @@ func (s *Action) printConfigValues(ctx context.Context, needles ...string) {
 	}
 }
 
+// mountConfigCmd prints or changes the config of a single mount.
+func (s *Action) mountConfigCmd(ctx context.Context, c *cli.Context) error {
+	mp := c.String("store")
+	if mp == "root" {
+		mp = ""
+	}
+
+	if mp != "" && !s.hasMount(mp) {
+		return exit.Error(exit.Mount, nil, "Store %q does not exist", c.String("store"))
+	}
+
+	switch c.Args().Len() {
+	case 0, 1:
+		return s.printMountConfigValues(ctx, mp, c.Args().Slice()...)
+	case 2:
+		if mp == "" {
+			return exit.Error(exit.Usage, nil, "Use '%s config key value' to change the config of the root store", s.Name)
+		}
+
+		key := c.Args().Get(0)
+		if err := s.cfg.SetMountConfigValue(mp, key, c.Args().Get(1)); err != nil {
+			return exit.Error(exit.Config, err, "Error setting config value: %s", err)
+		}
+
+		return s.printMountConfigValues(ctx, mp, key)
+	default:
+		return exit.Error(exit.Usage, nil, "Usage: %s config --store mount [key [value]]", s.Name)
+	}
+}
+
+// printMountConfigValues prints the effective config of a mount. Values that
+// are not inherited from the global config are annotated with their
+// origin.
+func (s *Action) printMountConfigValues(ctx context.Context, mp string, needles ...string) error {
+	sub, err := s.Store.GetSubStore(mp)
+	if err != nil {
+		return exit.Error(exit.Mount, err, "failed to get store %q: %s", mp, err)
+	}
+
+	inStore, err := sub.StoreConfig(ctx)
+	if err != nil {
+		return exit.Error(exit.Config, err, "failed to read config of store %q: %s", mp, err)
+	}
+
+	storeVals := inStore.Map()
+	mountVals := s.cfg.MountConfig[mp].Map()
+
+	m := s.cfg.ForMount(mp, inStore).ConfigMap()
+	for _, k := range filterMap(m, needles) {
+		if len(needles) == 1 {
+			out.Printf(ctx, "%s", m[k])
+
+			continue
+		}
+
+		origin := ""
+		if _, found := mountVals[k]; found {
+			origin = " (mountconfig)"
+		} else if _, found := storeVals[k]; found {
+			origin = fmt.Sprintf(" (%s)", config.StoreConfigFile)
+		}
+		out.Printf(ctx, "%s: %s%s", k, m[k], origin)
+	}
+
+	return nil
+}
+
+// configFor returns the effective config for the mount holding name.
+func (s *Action) configFor(ctx context.Context, name string) *config.Config {
+	return s.Store.MountConfig(ctx, s.Store.MountPoint(name))
+}
+
+// withMountContext applies the settings of the mount holding name to the
+// context, if they differ from the global config. Values set by flags must
+// be applied afterwards.
+func (s *Action) withMountContext(ctx context.Context, name string) context.Context {
+	mcfg := s.configFor(ctx, name)
+
+	if mcfg.Parsing != s.cfg.Parsing {
+		ctx = ctxutil.WithShowParsing(ctx, mcfg.Parsing)
+	}
+
+	if mcfg.ExportKeys != s.cfg.ExportKeys {
+		ctx = ctxutil.WithExportKeys(ctx, mcfg.ExportKeys)
+	}
+
+	return ctx
+}
+
 func filterMap(haystack map[string]string, needles []string) []string {
 	out := make([]string, 0, len(haystack))
 	for k := range haystack {
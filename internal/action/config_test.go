@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/kpitt/gopass/internal/config"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/pkg/ctxutil"
 	"github.com/kpitt/gopass/tests/gptest"
@@ -38,12 +40,17 @@ func TestConfig(t *testing.T) { //nolint:paralleltest
 		c := gptest.CliCtx(ctx, t)
 		assert.NoError(t, act.Config(c))
 		want := `autoimport: true
+autosync: true
 cliptimeout: 45
+dockercredentialprefix: 
 exportkeys: true
+generator: cryptic
+gitcredentialprefix: 
 nopager: false
 parsing: true
 `
 		want += "path: " + u.StoreDir("") + "\n"
+		want += "pwlength: 0\n"
 		assert.Equal(t, want, buf.String())
 	})
 
@@ -74,12 +81,17 @@ parsing: true
 
 		act.printConfigValues(ctx)
 		want := `autoimport: true
+autosync: true
 cliptimeout: 45
+dockercredentialprefix: 
 exportkeys: true
+generator: cryptic
+gitcredentialprefix: 
 nopager: true
 parsing: true
 `
-		want += "path: " + u.StoreDir("")
+		want += "path: " + u.StoreDir("") + "\n"
+		want += "pwlength: 0"
 		assert.Equal(t, want, strings.TrimSpace(buf.String()), "action.printConfigValues")
 
 		delete(act.cfg.Mounts, "foo")
@@ -106,11 +118,16 @@ parsing: true
 
 		act.ConfigComplete(gptest.CliCtx(ctx, t))
 		want := `autoimport
+autosync
 cliptimeout
+dockercredentialprefix
 exportkeys
+generator
+gitcredentialprefix
 nopager
 parsing
 path
+pwlength
 remote
 `
 		assert.Equal(t, want, buf.String())
@@ -122,4 +139,38 @@ remote
 		c := gptest.CliCtx(ctx, t, "autoimport", "false", "42")
 		assert.Error(t, act.Config(c))
 	})
+
+	t.Run("mount config", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, u.InitStore("work"))
+		require.NoError(t, act.Store.AddMount(ctx, "work", u.StoreDir("work")))
+		require.NoError(t, os.WriteFile(filepath.Join(u.StoreDir("work"), config.StoreConfigFile), []byte("cliptimeout: 10\nparsing: false\n"), 0o644))
+
+		assert.NoError(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"})))
+		assert.Contains(t, buf.String(), "cliptimeout: 10 (.gopass.yml)\n")
+		assert.Contains(t, buf.String(), "parsing: false (.gopass.yml)\n")
+		assert.Contains(t, buf.String(), "autosync: true\n")
+		buf.Reset()
+
+		assert.NoError(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"}, "cliptimeout", "5")))
+		assert.Equal(t, "5", strings.TrimSpace(buf.String()))
+		buf.Reset()
+
+		assert.NoError(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"})))
+		assert.Contains(t, buf.String(), "cliptimeout: 5 (mountconfig)\n")
+		buf.Reset()
+
+		assert.Equal(t, 5, act.configFor(ctx, "work/foo").ClipTimeout)
+		assert.False(t, ctxutil.IsShowParsing(act.withMountContext(ctx, "work/foo")))
+		assert.Equal(t, 45, act.configFor(ctx, "foo").ClipTimeout)
+
+		// the root store uses the global config.
+		assert.NoError(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "root"}, "cliptimeout")))
+		assert.Equal(t, "45", strings.TrimSpace(buf.String()))
+
+		assert.Error(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "root"}, "cliptimeout", "5")))
+		assert.Error(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"}, "path", "/tmp")))
+		assert.Error(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "nope"})))
+	})
 }
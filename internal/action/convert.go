@@ -0,0 +1,67 @@
+package action
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/recipients"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/termio"
+	"github.com/urfave/cli/v2"
+)
+
+// Convert re-encrypts all secrets of a store with another crypto backend.
+func (s *Action) Convert(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+
+	cb, err := backend.CryptoRegistry.Backend(c.String("crypto"))
+	if err != nil {
+		return exit.Error(exit.Usage, err, "Unknown crypto backend %q. Use one of %v", c.String("crypto"), backend.CryptoRegistry.BackendNames())
+	}
+
+	sub, err := s.Store.GetSubStore(store)
+	if err != nil || sub == nil {
+		return exit.Error(exit.NotFound, err, "failed to get sub store %s: %s", store, err)
+	}
+
+	// finishing a transition uses the existing recipients of the new backend.
+	var mapping map[string][]string
+	mf := c.String("mapping")
+	switch {
+	case mf != "":
+		buf, err := os.ReadFile(mf)
+		if err != nil {
+			return exit.Error(exit.IO, err, "failed to read mapping %s: %s", mf, err)
+		}
+
+		mapping, err = recipients.UnmarshalMapping(buf)
+		if err != nil {
+			return exit.Error(exit.Usage, err, "failed to parse mapping %s: %s", mf, err)
+		}
+	case sub.Secondary() == nil:
+		return exit.Error(exit.Usage, nil, "Usage: %s convert --crypto <backend> --mapping <file> [--store <store>] [--keep]", s.Name)
+	}
+
+	if !termio.AskForConfirmation(ctx, fmt.Sprintf("Convert all secrets in %s from %s to %s?", sub.Path(), sub.Crypto().Name(), cb)) {
+		return exit.Error(exit.Aborted, nil, "user aborted")
+	}
+
+	keep := c.Bool("keep")
+	if err := sub.Convert(ctx, cb, mapping, keep); err != nil {
+		return exit.Error(exit.Unknown, err, "failed to convert store %s: %s", sub.Path(), err)
+	}
+
+	if keep {
+		out.OKf(ctx, "Store %s is encrypted with %s and %s now", sub.Path(), sub.Crypto().Name(), cb)
+
+		return nil
+	}
+
+	out.OKf(ctx, "Converted store %s to %s", sub.Path(), cb)
+
+	return nil
+}
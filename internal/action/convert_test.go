@@ -0,0 +1,75 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	agecrypto "github.com/kpitt/gopass/internal/backend/crypto/age"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	// the store must be converted to one of our own age identities.
+	ctx = ctxutil.WithPasswordCallback(ctx, func(string, bool) ([]byte, error) {
+		return []byte("test"), nil
+	})
+	a, err := agecrypto.New()
+	require.NoError(t, err)
+	require.NoError(t, a.GenerateIdentity(ctx, "", "", "test"))
+	ids, err := a.IdentityRecipients(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	mf := filepath.Join(u.Dir, "mapping")
+	require.NoError(t, os.WriteFile(mf, []byte(fmt.Sprintf("# plain to age\n0xDEADBEEF %s\n", ids[0])), 0o600))
+
+	t.Run("unknown backend", func(t *testing.T) { //nolint:paralleltest
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"crypto": "rot13", "mapping": mf})
+		assert.Error(t, act.Convert(c))
+	})
+
+	t.Run("no mapping", func(t *testing.T) { //nolint:paralleltest
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"crypto": "age"})
+		assert.Error(t, act.Convert(c))
+	})
+
+	t.Run("same backend", func(t *testing.T) { //nolint:paralleltest
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"crypto": "plain", "mapping": mf})
+		assert.Error(t, act.Convert(c))
+	})
+
+	t.Run("to age", func(t *testing.T) { //nolint:paralleltest
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"crypto": "age", "mapping": mf})
+		require.NoError(t, act.Convert(c))
+		assert.Contains(t, buf.String(), "Converted store")
+
+		assert.FileExists(t, filepath.Join(u.StoreDir(""), ".age-recipients"))
+		assert.NoFileExists(t, filepath.Join(u.StoreDir(""), ".plain-id"))
+		assert.FileExists(t, filepath.Join(u.StoreDir(""), "foo.age"))
+	})
+}
@@ -0,0 +1,48 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/dockercredential"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/api"
+	"github.com/urfave/cli/v2"
+)
+
+// DockerCredential runs an action of the docker credential helper protocol.
+// stdin and stdout are reserved for the protocol.
+func (s *Action) DockerCredential(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	action := c.Args().First()
+	if action == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s docker-credential <%s>", s.Name, strings.Join(dockercredential.Actions, "|"))
+	}
+
+	gp, err := api.New(ctx)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "failed to initialize store: %s", err)
+	}
+	defer func() {
+		_ = gp.Close(ctx)
+	}()
+
+	h := dockercredential.New(gp, s.cfg.DockerCredentialPrefix)
+	if err := h.Run(ctx, action, stdin, stdout); err != nil {
+		// docker expects the error message on stdout.
+		fmt.Fprintln(stdout, err)
+
+		if errors.Is(err, dockercredential.ErrNotFound) {
+			return exit.Error(exit.NotFound, err, "%s", err)
+		}
+
+		return exit.Error(exit.Unknown, err, "%s", err)
+	}
+
+	return nil
+}
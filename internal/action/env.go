@@ -0,0 +1,234 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/tree"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	reEnvInvalid = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+	reEnvName    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// envNaming controls how secrets are mapped to environment variables.
+type envNaming struct {
+	// prefix is prepended to every variable.
+	prefix string
+	// keepCase keeps the case of names and keys instead of converting
+	// them to upper case.
+	keepCase bool
+	// rename maps the path of a value, e.g. "prod/user", to a variable name.
+	// It takes precedence over all other rules.
+	rename map[string]string
+}
+
+// parseEnvMap parses the key=VAR rules of --map.
+func parseEnvMap(rules []string) (map[string]string, error) {
+	rename := make(map[string]string, len(rules))
+	for _, r := range rules {
+		key, v, found := strings.Cut(r, "=")
+		key = strings.Trim(key, "/")
+		if !found || key == "" || !reEnvName.MatchString(v) {
+			return nil, fmt.Errorf("invalid rule %q, expected key=VAR", r)
+		}
+		rename[key] = v
+	}
+
+	return rename, nil
+}
+
+// variable returns the name of the variable for the value at the path given
+// by elems.
+func (n envNaming) variable(elems ...string) string {
+	if v, found := n.rename[path.Join(elems...)]; found {
+		return v
+	}
+
+	return n.name(elems...)
+}
+
+// name joins the elements with underscores. Characters that are not valid
+// in variable names are replaced by underscores, e.g. "db/prod" and "user"
+// become DB_PROD_USER.
+func (n envNaming) name(elems ...string) string {
+	parts := make([]string, 0, len(elems)+1)
+	if n.prefix != "" {
+		parts = append(parts, n.prefix)
+	}
+	for _, e := range elems {
+		if e = strings.Trim(reEnvInvalid.ReplaceAllString(e, "_"), "_"); e != "" {
+			parts = append(parts, e)
+		}
+	}
+
+	v := strings.Join(parts, "_")
+	if !n.keepCase {
+		v = strings.ToUpper(v)
+	}
+	if v != "" && v[0] >= '0' && v[0] <= '9' {
+		v = "_" + v
+	}
+
+	return v
+}
+
+// Env runs a command with the secrets below a prefix, or a single secret,
+// in its environment.
+func (s *Action) Env(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	args := c.Args().Slice()
+	if len(args) > 1 && args[1] == "--" {
+		args = append(args[:1], args[2:]...)
+	}
+	if len(args) < 2 {
+		return exit.Error(exit.Usage, nil, "Usage: %s env <secret-or-folder> -- <command> [args...]", s.Name)
+	}
+
+	rename, err := parseEnvMap(c.StringSlice("map"))
+	if err != nil {
+		return exit.Error(exit.Usage, err, "%s", err)
+	}
+
+	naming := envNaming{
+		prefix:   c.String("prefix"),
+		keepCase: c.Bool("keep-case"),
+		rename:   rename,
+	}
+
+	vars, err := s.envVars(ctx, strings.TrimSuffix(args[0], "/"), naming)
+	if err != nil {
+		return err
+	}
+
+	// a key like "path" must not silently replace PATH.
+	if !c.Bool("force") {
+		for _, kv := range vars {
+			k, _, _ := strings.Cut(kv, "=")
+			if _, found := os.LookupEnv(k); found {
+				return exit.Error(exit.Usage, nil, "Variable %s is already set. Use --map to rename it or --force to override it", k)
+			}
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, args[1], args[2:]...)
+	cmd.Env = append(os.Environ(), vars...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			// pass on the exit code of the command without another message.
+			return exit.Error(ee.ExitCode(), err, "")
+		}
+
+		return exit.Error(exit.Unknown, err, "failed to run %s: %s", args[1], err)
+	}
+
+	return nil
+}
+
+// envVars returns the variables for a secret or all secrets below a folder
+// as KEY=value. The password of a secret is mapped to the path of the secret
+// relative to the folder, or to its base name for a single secret. Keys are
+// prefixed with the same path, e.g. "db/prod" below "db" with the key "user"
+// becomes PROD_USER. All rename rules of naming must match a value.
+func (s *Action) envVars(ctx context.Context, name string, naming envNaming) ([]string, error) {
+	var names []string
+
+	switch {
+	case s.Store.IsDir(ctx, name):
+		all, err := s.Store.List(ctx, tree.INF)
+		if err != nil {
+			return nil, exit.Error(exit.List, err, "failed to list secrets: %s", err)
+		}
+		for _, n := range all {
+			if strings.HasPrefix(n, name+"/") || name == "" {
+				names = append(names, n)
+			}
+		}
+	case s.Store.Exists(ctx, name):
+		names = []string{name}
+	default:
+		return nil, exit.Error(exit.NotFound, nil, "Secret or folder %s not found", name)
+	}
+
+	vars := make(map[string]string, len(names)*2)
+	sources := make(map[string]string, len(names)*2)
+	seen := make(map[string]bool, len(names)*2)
+	set := func(key, value, source string) error {
+		if key == "" {
+			return exit.Error(exit.Usage, nil, "Can not map %s to a variable name", source)
+		}
+		if other, found := sources[key]; found {
+			return exit.Error(exit.Usage, nil, "Variable %s is set by %s and %s", key, other, source)
+		}
+		vars[key] = value
+		sources[key] = source
+
+		return nil
+	}
+
+	for _, n := range names {
+		sec, err := s.Store.Get(ctx, n)
+		if err != nil {
+			return nil, exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", n, err)
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(n, name), "/")
+		pwName := rel
+		if rel == "" {
+			pwName = path.Base(n)
+		}
+
+		if pw := sec.Password(); pw != "" {
+			if err := set(naming.variable(pwName), pw, n); err != nil {
+				return nil, err
+			}
+			seen[pwName] = true
+		}
+
+		for _, k := range sec.Keys() {
+			v, _ := sec.Get(k)
+			if err := set(naming.variable(rel, k), v, fmt.Sprintf("%s (%s)", n, k)); err != nil {
+				return nil, err
+			}
+			seen[path.Join(rel, k)] = true
+		}
+	}
+
+	for key := range naming.rename {
+		if !seen[key] {
+			return nil, exit.Error(exit.Usage, nil, "Rule --map %s=%s does not match any password or key", key, naming.rename[key])
+		}
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	debug.Log("setting variables %v", keys)
+
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+vars[k])
+	}
+
+	return env, nil
+}
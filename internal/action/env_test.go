@@ -0,0 +1,165 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestEnvNaming(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "DB_PROD_USER", envNaming{}.name("db/prod", "user"))
+	assert.Equal(t, "API_KEY", envNaming{}.name("", "api-key"))
+	assert.Equal(t, "APP_DB_HOST", envNaming{prefix: "app"}.name("db", "host"))
+	assert.Equal(t, "db_Host", envNaming{keepCase: true}.name("db", "Host"))
+	assert.Equal(t, "_1PASSWORD", envNaming{}.name("1password"))
+	assert.Equal(t, "", envNaming{}.name("--"))
+
+	n := envNaming{prefix: "app", rename: map[string]string{"prod/user": "DB_USER"}}
+	assert.Equal(t, "DB_USER", n.variable("prod", "user"))
+	assert.Equal(t, "APP_PROD", n.variable("prod"))
+}
+
+func TestParseEnvMap(t *testing.T) {
+	t.Parallel()
+
+	rename, err := parseEnvMap([]string{"user=DB_USER", "/prod/=prod_pw"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "DB_USER", "prod": "prod_pw"}, rename)
+
+	for _, r := range []string{"user", "=FOO", "user=", "user=1FOO", "user=FOO-BAR"} {
+		_, err := parseEnvMap([]string{r})
+		assert.Error(t, err, r)
+	}
+}
+
+// envCtx returns a context for env with the given --map rules.
+func envCtx(ctx context.Context, t *testing.T, rules []string, args ...string) *cli.Context {
+	t.Helper()
+
+	fs := flag.NewFlagSet("default", flag.ContinueOnError)
+	require.NoError(t, (&cli.StringSliceFlag{Name: "map"}).Apply(fs))
+	argl := make([]string, 0, len(rules)*2+len(args))
+	for _, r := range rules {
+		argl = append(argl, "--map", r)
+	}
+	require.NoError(t, fs.Parse(append(argl, args...)))
+
+	c := cli.NewContext(cli.NewApp(), fs, nil)
+	c.Context = ctx
+
+	return c
+}
+
+func TestEnv(t *testing.T) { //nolint:paralleltest
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires sh")
+	}
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	for name, kv := range map[string][2]string{
+		"db/prod":    {"s3cr3t", "prod-user"},
+		"db/staging": {"hunter2", "staging-user"},
+	} {
+		sec := secrets.NewKV()
+		sec.SetPassword(kv[0])
+		require.NoError(t, sec.Set("user", kv[1]))
+		require.NoError(t, act.Store.Set(ctx, name, sec))
+	}
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	t.Run("single secret", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		c := envCtx(ctx, t, []string{"user=DB_USER"}, "db/prod", "--", "sh", "-c", `echo "$PROD:$DB_USER"`)
+		require.NoError(t, act.Env(c))
+		assert.Equal(t, "s3cr3t:prod-user\n", buf.String())
+	})
+
+	t.Run("map folder", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		c := envCtx(ctx, t, []string{"prod=DB_PASSWORD", "staging/user=staging_login"}, "db", "sh", "-c", `echo "$DB_PASSWORD $staging_login $PROD_USER"`)
+		require.NoError(t, act.Env(c))
+		assert.Equal(t, "s3cr3t staging-user prod-user\n", buf.String())
+
+		assert.Error(t, act.Env(envCtx(ctx, t, []string{"missing=FOO"}, "db", "true")))
+		assert.Error(t, act.Env(envCtx(ctx, t, []string{"prod/user="}, "db", "true")))
+	})
+
+	t.Run("shadowed variable", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		t.Setenv("PROD", "outer")
+
+		assert.Error(t, act.Env(gptest.CliCtx(ctx, t, "db", "true")))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "db", "sh", "-c", `echo "$PROD"`)
+		require.NoError(t, act.Env(c))
+		assert.Equal(t, "s3cr3t\n", buf.String())
+	})
+
+	t.Run("folder with prefix", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"prefix": "app"}, "db", "sh", "-c", `echo "$APP_PROD $APP_STAGING_USER"`)
+		require.NoError(t, act.Env(c))
+		assert.Equal(t, "s3cr3t staging-user\n", buf.String())
+	})
+
+	t.Run("exit code", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		err := act.Env(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "db/prod", "--", "sh", "-c", "exit 3"))
+		require.Error(t, err)
+		var ec cli.ExitCoder
+		require.ErrorAs(t, err, &ec)
+		assert.Equal(t, 3, ec.ExitCode())
+	})
+
+	t.Run("conflict", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		sec := secrets.NewKV()
+		sec.SetPassword("other")
+		require.NoError(t, act.Store.Set(ctx, "db/prod-user", sec))
+
+		assert.Error(t, act.Env(gptest.CliCtx(ctx, t, "db", "--", "true")))
+	})
+
+	t.Run("errors", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		assert.Error(t, act.Env(gptest.CliCtx(ctx, t, "db/prod")))
+		assert.Error(t, act.Env(gptest.CliCtx(ctx, t, "missing", "--", "true")))
+	})
+}
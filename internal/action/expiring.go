@@ -0,0 +1,151 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/audit"
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/internal/tree"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/termio"
+	"github.com/urfave/cli/v2"
+)
+
+// Expiring lists all secrets that are expired or expire soon.
+func (s *Action) Expiring(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	days := c.Int("days")
+	if days < 0 {
+		return exit.Error(exit.Usage, nil, "--days must not be negative")
+	}
+
+	maxAge := audit.DefaultExpiration
+	if d := c.Int("expiry"); d > 0 {
+		maxAge = time.Duration(d) * 24 * time.Hour
+	}
+
+	mount := c.String("store")
+	if mount != "" && !s.hasMount(mount) {
+		return exit.Error(exit.Mount, nil, "Store %q does not exist", mount)
+	}
+
+	names, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return exit.Error(exit.List, err, "failed to list secrets: %s", err)
+	}
+
+	prefix := strings.TrimSuffix(c.Args().First(), "/")
+	selected := make([]string, 0, len(names))
+	for _, name := range names {
+		if inSelection(name, prefix, mount, s.Store.MountPoint(name)) {
+			selected = append(selected, name)
+		}
+	}
+
+	bar := termio.NewProgressBar("Checking secrets", int64(len(selected)))
+	bar.Hidden = ctxutil.IsHidden(ctx) || ctxutil.IsJSON(ctx)
+
+	now := time.Now()
+	window := time.Duration(days) * 24 * time.Hour
+	expiring := make([]audit.Expiry, 0, 10)
+	for _, name := range selected {
+		bar.Inc()
+
+		e, err := s.expiry(ctx, name, maxAge)
+		if err != nil {
+			out.Warningf(ctx, "%s: %s", name, err)
+
+			continue
+		}
+
+		if e.ExpiresWithin(now, window) {
+			expiring = append(expiring, e)
+		}
+	}
+	bar.Done()
+
+	sort.Slice(expiring, func(i, j int) bool {
+		if !expiring[i].Date.Equal(expiring[j].Date) {
+			return expiring[i].Date.Before(expiring[j].Date)
+		}
+
+		return expiring[i].Name < expiring[j].Name
+	})
+
+	if ctxutil.IsJSON(ctx) {
+		return s.printExpiringJSON(expiring, now)
+	}
+
+	if len(expiring) < 1 {
+		out.OKf(ctx, "No secrets expire within %d days", days)
+
+		return nil
+	}
+
+	for _, e := range expiring {
+		fmt.Fprintf(stdout, "%s  %s  %s (%s)\n", e.Date.Format("2006-01-02"), expiryStatus(e, now), e.Name, e.Source)
+	}
+
+	return nil
+}
+
+// expiry decrypts a secret and determines its expiration date.
+func (s *Action) expiry(ctx context.Context, name string, maxAge time.Duration) (audit.Expiry, error) {
+	sec, err := s.Store.Get(ctxutil.WithShowParsing(ctx, true), name)
+	if err != nil {
+		return audit.Expiry{}, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	revs, err := s.Store.ListRevisions(ctx, name)
+	if err != nil && !errors.Is(err, backend.ErrNotSupported) && !errors.Is(err, store.ErrGitNotInit) {
+		return audit.Expiry{}, fmt.Errorf("failed to list revisions: %w", err)
+	}
+
+	return audit.GetExpiry(name, sec, revs, maxAge)
+}
+
+// expiryStatus returns a fixed width, human readable status.
+func expiryStatus(e audit.Expiry, now time.Time) string {
+	if e.Expired(now) {
+		return color.RedString("%-8s", "expired")
+	}
+
+	days := int(math.Ceil(e.Date.Sub(now).Hours() / 24))
+
+	return color.YellowString("%-8s", fmt.Sprintf("in %dd", days))
+}
+
+func (s *Action) printExpiringJSON(expiring []audit.Expiry, now time.Time) error {
+	doc := expiringJSON{
+		Secrets: make([]expiringSecretJSON, 0, len(expiring)),
+	}
+
+	for _, e := range expiring {
+		es := expiringSecretJSON{
+			Name:    e.Name,
+			Mount:   s.Store.MountPoint(e.Name),
+			Expires: e.Date,
+			Expired: e.Expired(now),
+			Source:  e.Source,
+		}
+		if !e.Changed.IsZero() {
+			changed := e.Changed
+			es.Changed = &changed
+		}
+
+		doc.Secrets = append(doc.Secrets, es)
+	}
+
+	return printJSON(doc)
+}
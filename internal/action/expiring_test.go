@@ -0,0 +1,95 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiring(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	now := time.Now()
+	for name, expires := range map[string]string{
+		"web/expired": "2000-01-01",
+		"web/soon":    now.Add(10 * 24 * time.Hour).Format("2006-01-02"),
+		"web/later":   now.Add(100 * 24 * time.Hour).Format("2006-01-02"),
+		"db/soon":     now.Add(20 * 24 * time.Hour).Format("2006-01-02"),
+		"web/invalid": "someday",
+	} {
+		sec := secrets.NewKV()
+		sec.SetPassword("s3cr3t")
+		require.NoError(t, sec.Set("expires", expires))
+		require.NoError(t, act.Store.Set(ctx, name, sec))
+	}
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("all mounts", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		require.NoError(t, act.Expiring(gptest.CliCtxWithFlags(ctx, t, map[string]string{"days": "30"})))
+
+		assert.NotContains(t, buf.String(), "web/invalid")
+		assert.Contains(t, buf.String(), "2000-01-01  expired   web/expired (expires)\n")
+		assert.Regexp(t, `in \d+d\s+web/soon \(expires\)\n.*in \d+d\s+db/soon \(expires\)\n`, buf.String())
+		assert.NotContains(t, buf.String(), "web/later")
+	})
+
+	t.Run("prefix", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		require.NoError(t, act.Expiring(gptest.CliCtxWithFlags(ctx, t, map[string]string{"days": "5"}, "web")))
+
+		assert.Contains(t, buf.String(), "web/expired")
+		assert.NotContains(t, buf.String(), "soon")
+	})
+
+	t.Run("json", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		// --json is a global flag.
+		require.NoError(t, act.Expiring(gptest.CliCtxWithFlags(ctxutil.WithJSON(ctx, true), t, map[string]string{"days": "15"}, "web/")))
+
+		var doc expiringJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes()[bytes.IndexByte(buf.Bytes(), '{'):], &doc))
+		require.Len(t, doc.Secrets, 2)
+		assert.Equal(t, "web/expired", doc.Secrets[0].Name)
+		assert.True(t, doc.Secrets[0].Expired)
+		assert.Equal(t, "expires", doc.Secrets[0].Source)
+		assert.Equal(t, "web/soon", doc.Secrets[1].Name)
+		assert.False(t, doc.Secrets[1].Expired)
+	})
+
+	t.Run("unknown mount", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		assert.Error(t, act.Expiring(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "nope"})))
+	})
+}
@@ -0,0 +1,213 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/exporter"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/internal/tree"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/termio"
+	"github.com/urfave/cli/v2"
+)
+
+// Export writes the decrypted content of the store, or a part of it, to a
+// file.
+func (s *Action) Export(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	format := c.String("format")
+	if format == "" {
+		format = "json"
+	}
+
+	write, err := exporter.Get(format)
+	if err != nil {
+		return exit.Error(exit.Usage, err, "%s", err)
+	}
+
+	history := c.Bool("history")
+	if history && !exporter.SupportsHistory(format) {
+		return exit.Error(exit.Usage, nil, "The %s format can not include the history", format)
+	}
+
+	mount := c.String("store")
+	if mount != "" && !s.hasMount(mount) {
+		return exit.Error(exit.Mount, nil, "Store %q does not exist", mount)
+	}
+
+	output := c.String("output")
+	if output == "" && exporter.IsBinary(format) && ctxutil.IsTerminal(ctx) {
+		return exit.Error(exit.Usage, nil, "Refusing to write a %s export to the terminal. Use --output", format)
+	}
+
+	opts := exporter.Options{
+		Recipients: c.StringSlice("recipient"),
+	}
+	switch {
+	case format == "kdbx":
+		pw, err := termio.AskForPassword(ctx, "password for the KeePass database", true)
+		if err != nil {
+			return exit.Error(exit.Aborted, err, "failed to read password: %s", err)
+		}
+		opts.Password = pw
+	case format == "age" && len(opts.Recipients) < 1:
+		pw, err := termio.AskForPassword(ctx, "passphrase for the archive", true)
+		if err != nil {
+			return exit.Error(exit.Aborted, err, "failed to read passphrase: %s", err)
+		}
+		opts.Password = pw
+	}
+
+	e, err := s.collectExport(ctx, c.Args().First(), mount, history)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = stdout
+	if output != "" {
+		fh, err := os.OpenFile(output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return exit.Error(exit.IO, err, "failed to create %q: %s", output, err)
+		}
+		defer func() {
+			_ = fh.Close()
+		}()
+		w = fh
+	}
+
+	if err := write(w, e, opts); err != nil {
+		if output != "" {
+			_ = os.Remove(output)
+		}
+
+		return exit.Error(exit.IO, err, "failed to write export: %s", err)
+	}
+
+	if output != "" {
+		out.OKf(ctx, "Exported %d secrets and %d templates to %s", len(e.Secrets), len(e.Templates), output)
+	}
+
+	return nil
+}
+
+func (s *Action) hasMount(mount string) bool {
+	for _, mp := range s.Store.MountPoints() {
+		if mp == mount {
+			return true
+		}
+	}
+
+	return false
+}
+
+// collectExport decrypts all secrets below prefix. If mount is set only
+// secrets in that mount are included.
+func (s *Action) collectExport(ctx context.Context, prefix, mount string, history bool) (*exporter.Export, error) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	names, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return nil, exit.Error(exit.List, err, "failed to list secrets: %s", err)
+	}
+
+	e := &exporter.Export{}
+	for _, name := range names {
+		if !inSelection(name, prefix, mount, s.Store.MountPoint(name)) {
+			continue
+		}
+
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return nil, exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
+		}
+
+		es := exporter.Secret{
+			Name:   name,
+			Secret: sec,
+		}
+		if history {
+			es.Revisions = s.exportRevisions(ctx, name)
+		}
+
+		e.Secrets = append(e.Secrets, es)
+	}
+
+	mps := append([]string{""}, s.Store.MountPoints()...)
+	for _, mp := range mps {
+		if mount != "" && mp != mount {
+			continue
+		}
+
+		sub, err := s.Store.GetSubStore(mp)
+		if err != nil {
+			return nil, exit.Error(exit.Mount, err, "failed to get store %q: %s", mp, err)
+		}
+
+		for _, name := range sub.ListTemplates(ctx, mp) {
+			if !inSelection(name, prefix, mount, s.Store.MountPoint(name)) {
+				continue
+			}
+
+			content, err := s.Store.GetTemplate(ctx, name)
+			if err != nil {
+				return nil, exit.Error(exit.IO, err, "failed to read template %s: %s", name, err)
+			}
+
+			e.Templates = append(e.Templates, exporter.Template{
+				Name:    name,
+				Content: content,
+			})
+		}
+	}
+
+	debug.Log("collected %d secrets and %d templates", len(e.Secrets), len(e.Templates))
+
+	return e, nil
+}
+
+// inSelection returns true if name is below prefix and in the selected mount.
+func inSelection(name, prefix, mount, mp string) bool {
+	if mount != "" && mp != mount {
+		return false
+	}
+
+	return prefix == "" || name == prefix || strings.HasPrefix(name, prefix+"/")
+}
+
+// exportRevisions returns all revisions of a secret. Revisions that can not
+// be decrypted are included without content.
+func (s *Action) exportRevisions(ctx context.Context, name string) []exporter.Revision {
+	revs, err := s.Store.ListRevisions(ctx, name)
+	if err != nil {
+		if !errors.Is(err, backend.ErrNotSupported) && !errors.Is(err, store.ErrGitNotInit) {
+			out.Warningf(ctx, "Failed to list revisions of %s: %s", name, err)
+		}
+
+		return nil
+	}
+
+	res := make([]exporter.Revision, 0, len(revs))
+	for _, rev := range revs {
+		r := exporter.Revision{Revision: rev}
+
+		_, sec, err := s.Store.GetRevision(ctx, name, rev.Hash)
+		if err != nil {
+			debug.Log("failed to decrypt %s@%s: %s", name, rev.Hash, err)
+		} else {
+			r.Secret = sec
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
@@ -0,0 +1,108 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cr3t")
+	require.NoError(t, sec.Set("username", "alice"))
+	require.NoError(t, act.Store.Set(ctx, "web/example.org", sec))
+	require.NoError(t, act.Store.SetTemplate(ctx, "web", []byte("{{ .Name }}")))
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("json", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Export(gptest.CliCtx(ctx, t)))
+
+		var doc struct {
+			Secrets []struct {
+				Name     string              `json:"name"`
+				Password string              `json:"password"`
+				Keys     map[string][]string `json:"keys"`
+			} `json:"secrets"`
+			Templates []struct {
+				Name    string `json:"name"`
+				Content string `json:"content"`
+			} `json:"templates"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		require.Len(t, doc.Secrets, 2)
+		assert.Equal(t, "foo", doc.Secrets[0].Name)
+		assert.Equal(t, "web/example.org", doc.Secrets[1].Name)
+		assert.Equal(t, "s3cr3t", doc.Secrets[1].Password)
+		assert.Equal(t, []string{"alice"}, doc.Secrets[1].Keys["username"])
+		require.Len(t, doc.Templates, 1)
+		assert.Equal(t, "web", doc.Templates[0].Name)
+		assert.Equal(t, "{{ .Name }}", doc.Templates[0].Content)
+	})
+
+	t.Run("csv with prefix to file", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.Dir, "export.csv")
+		require.NoError(t, act.Export(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "csv", "output": fn}, "web")))
+
+		content, err := os.ReadFile(fn)
+		require.NoError(t, err)
+		assert.Equal(t, "name,password,username,body\nweb/example.org,s3cr3t,alice,\n.templates/web,,,{{ .Name }}\n", string(content))
+
+		fi, err := os.Stat(fn)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+	})
+
+	t.Run("json with history", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Export(gptest.CliCtxWithFlags(ctx, t, map[string]string{"history": "true"}, "web/example.org")))
+		assert.Contains(t, buf.String(), `"name": "web/example.org"`)
+		assert.NotContains(t, buf.String(), `"name": "foo"`)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Export(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "xml"})))
+		assert.Error(t, act.Export(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "csv", "history": "true"})))
+		assert.Error(t, act.Export(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "nope"})))
+	})
+
+	t.Run("kdbx without password", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.Dir, "export.kdbx")
+		assert.Error(t, act.Export(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "kdbx", "output": fn})))
+		assert.NoFileExists(t, fn)
+	})
+}
@@ -46,6 +46,10 @@ func (s *Action) find(ctx context.Context, c *cli.Context, needle string, cb sho
 	needle = strings.ToLower(needle)
 	choices := filter(haystack, needle)
 
+	if cb == nil && ctxutil.IsJSON(ctx) {
+		return printJSON(findJSON{Matches: choices})
+	}
+
 	// if we have an exact match print it.
 	if len(choices) == 1 {
 		if cb == nil {
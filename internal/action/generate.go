@@ -79,6 +79,11 @@ func (s *Action) Generate(c *cli.Context) error {
 		}
 	}
 
+	// generate typed key material instead of a password.
+	if typ := c.String("type"); typ != "" {
+		return s.generateKey(ctx, c, name, typ, key, length, kvps)
+	}
+
 	// generate password.
 	password, err := s.generatePassword(ctx, c, length, name)
 	if err != nil {
@@ -135,7 +140,7 @@ func (s *Action) generateCopyOrPrint(ctx context.Context, c *cli.Context, name,
 
 	// copy to clipboard if explicitly requested with -c
 	if IsClip(ctx) {
-		if err := clipboard.CopyTo(ctx, name, []byte(password), s.cfg.ClipTimeout); err != nil {
+		if err := clipboard.CopyTo(ctx, name, []byte(password), s.configFor(ctx, name).ClipTimeout); err != nil {
 			return exit.Error(exit.IO, err, "failed to copy to clipboard: %s", err)
 		}
 	}
@@ -178,9 +183,11 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 		symbols = c.Bool("symbols")
 	}
 
+	mcfg := s.configFor(ctx, name)
+
 	var pwlen int
 	if length == "" {
-		pwlength, err := getPwLengthFromEnvOrAskUser(ctx)
+		pwlength, err := getPwLengthFromEnvOrAskUser(ctx, mcfg.PwLength)
 		if err != nil {
 			return "", err
 		}
@@ -197,7 +204,12 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 		return "", exit.Error(exit.Usage, nil, "password length must not be zero")
 	}
 
-	switch c.String("generator") {
+	generator := c.String("generator")
+	if generator == "" {
+		generator = mcfg.Generator
+	}
+
+	switch generator {
 	case "xkcd":
 		return s.generatePasswordXKCD(ctx, c, length)
 	case "memorable":
@@ -218,13 +230,16 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 }
 
 // getPwLengthFromEnvOrAskUser either determines the password length through an
-// environment variable or asks the user to set one.
-// This function assumes that if the length is set via the environment variable,
-// the user has already made a conscious decision and does not need to be asked
-// again.
-func getPwLengthFromEnvOrAskUser(ctx context.Context) (int, error) {
+// environment variable, the config (if cfgLength is set) or asks the user to set one.
+// This function assumes that if the length is set via the environment variable
+// or the config, the user has already made a conscious decision and does not
+// need to be asked again.
+func getPwLengthFromEnvOrAskUser(ctx context.Context, cfgLength int) (int, error) {
 	var pwlen int
 	candidateLength, isCustom := defaultLengthFromEnv()
+	if !isCustom && cfgLength > 0 {
+		candidateLength, isCustom = cfgLength, true
+	}
 	if !isCustom {
 		question := "How long should the password be?"
 		iv, err := termio.AskForInt(ctx, question, candidateLength)
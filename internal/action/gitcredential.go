@@ -0,0 +1,154 @@
+package action
+
+import (
+	"context"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/gitcredential"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+// GitCredentialGet prints the credential for the request read from stdin.
+// Nothing is printed if there is no matching secret so that git can try
+// the next helper or prompt the user.
+func (s *Action) GitCredentialGet(c *cli.Context) error {
+	ctx, cred, name, err := s.gitCredential(c)
+	if err != nil {
+		return err
+	}
+
+	if !s.Store.Exists(ctx, name) {
+		debug.Log("no credential at %s", name)
+
+		return nil
+	}
+
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
+	}
+
+	if !matchesUsername(cred, sec) {
+		debug.Log("credential at %s is for a different user", name)
+
+		return nil
+	}
+
+	resp := &gitcredential.Credential{
+		Username: cred.Username,
+		Password: sec.Password(),
+	}
+	if username, found := sec.Get("username"); found {
+		resp.Username = username
+	}
+
+	if _, err := resp.WriteTo(stdout); err != nil {
+		return exit.Error(exit.IO, err, "failed to write credential: %s", err)
+	}
+
+	return nil
+}
+
+// GitCredentialStore saves a credential that git successfully used. The
+// secret is not touched if it already holds the same credential.
+func (s *Action) GitCredentialStore(c *cli.Context) error {
+	ctx, cred, name, err := s.gitCredential(c)
+	if err != nil {
+		return err
+	}
+
+	if cred.Password == "" {
+		return exit.Error(exit.Usage, nil, "no password given")
+	}
+
+	var sec gopass.Secret = secrets.NewYAML()
+	if s.Store.Exists(ctx, name) {
+		sec, err = s.Store.Get(ctx, name)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
+		}
+
+		if username, _ := sec.Get("username"); username == cred.Username && sec.Password() == cred.Password {
+			debug.Log("credential at %s is up to date", name)
+
+			return nil
+		}
+	}
+
+	sec.SetPassword(cred.Password)
+	if cred.Username != "" {
+		if err := sec.Set("username", cred.Username); err != nil {
+			return exit.Error(exit.Unknown, err, "failed to set username: %s", err)
+		}
+	}
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Stored git credential"), name, sec); err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to save credential to %s: %s", name, err)
+	}
+
+	return nil
+}
+
+// GitCredentialErase removes a credential that git rejected. The secret is
+// only removed if it still holds the rejected credential.
+func (s *Action) GitCredentialErase(c *cli.Context) error {
+	ctx, cred, name, err := s.gitCredential(c)
+	if err != nil {
+		return err
+	}
+
+	if !s.Store.Exists(ctx, name) {
+		return nil
+	}
+
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
+	}
+
+	if !matchesUsername(cred, sec) || (cred.Password != "" && cred.Password != sec.Password()) {
+		debug.Log("credential at %s has changed, not erasing", name)
+
+		return nil
+	}
+
+	if err := s.Store.Delete(ctxutil.WithCommitMessage(ctx, "Erased git credential"), name); err != nil {
+		return exit.Error(exit.IO, err, "failed to delete %s: %s", name, err)
+	}
+
+	return nil
+}
+
+// gitCredential reads the request from stdin and returns the name of the
+// secret it maps to. The returned context is non-interactive and hidden
+// since stdin and stdout are reserved for the protocol.
+func (s *Action) gitCredential(c *cli.Context) (context.Context, *gitcredential.Credential, string, error) {
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	cred, err := gitcredential.Read(stdin)
+	if err != nil {
+		return ctx, nil, "", exit.Error(exit.Usage, err, "%s", err)
+	}
+
+	name, err := cred.Name(s.cfg.GitCredentialPrefix)
+	if err != nil {
+		return ctx, nil, "", exit.Error(exit.Usage, err, "%s", err)
+	}
+	debug.Log("git credential for %s://%s/%s maps to %s", cred.Protocol, cred.Host, cred.Path, name)
+
+	return ctx, cred, name, nil
+}
+
+// matchesUsername returns false if git asked for a specific user and the
+// secret holds a different one.
+func matchesUsername(cred *gitcredential.Credential, sec gopass.Secret) bool {
+	username, found := sec.Get("username")
+
+	return cred.Username == "" || !found || username == cred.Username
+}
@@ -0,0 +1,117 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitCredential(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+		stdin = os.Stdin
+	}()
+
+	request := "protocol=https\nhost=example.com\n"
+
+	t.Run("get unknown credential", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		stdin = strings.NewReader(request + "\n")
+		require.NoError(t, act.GitCredentialGet(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("store credential", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		stdin = strings.NewReader(request + "username=alice\npassword=s3cr3t\n\n")
+		require.NoError(t, act.GitCredentialStore(gptest.CliCtx(ctx, t)))
+
+		sec, err := act.Store.Get(ctx, "git/https/example.com")
+		require.NoError(t, err)
+		assert.IsType(t, &secrets.YAML{}, sec)
+		assert.Equal(t, "s3cr3t", sec.Password())
+		username, _ := sec.Get("username")
+		assert.Equal(t, "alice", username)
+	})
+
+	t.Run("get credential", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		stdin = strings.NewReader(request)
+		require.NoError(t, act.GitCredentialGet(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "username=alice\npassword=s3cr3t\n", buf.String())
+	})
+
+	t.Run("get credential of other user", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		stdin = strings.NewReader(request + "username=bob\n")
+		require.NoError(t, act.GitCredentialGet(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("erase outdated credential", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		stdin = strings.NewReader(request + "username=alice\npassword=old\n")
+		require.NoError(t, act.GitCredentialErase(gptest.CliCtx(ctx, t)))
+		assert.True(t, act.Store.Exists(ctx, "git/https/example.com"))
+	})
+
+	t.Run("erase credential", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		stdin = strings.NewReader(request + "username=alice\npassword=s3cr3t\n")
+		require.NoError(t, act.GitCredentialErase(gptest.CliCtx(ctx, t)))
+		assert.False(t, act.Store.Exists(ctx, "git/https/example.com"))
+	})
+
+	t.Run("custom prefix", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		act.cfg.GitCredentialPrefix = "dev/creds"
+		defer func() {
+			act.cfg.GitCredentialPrefix = ""
+		}()
+
+		stdin = strings.NewReader("url=https://bob@example.org/org/repo.git\npassword=hunter2\n")
+		require.NoError(t, act.GitCredentialStore(gptest.CliCtx(ctx, t)))
+		assert.True(t, act.Store.Exists(ctx, "dev/creds/https/example.org/org/repo.git"))
+	})
+
+	t.Run("invalid request", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		stdin = strings.NewReader("host=example.com\n")
+		assert.Error(t, act.GitCredentialGet(gptest.CliCtx(ctx, t)))
+
+		stdin = strings.NewReader(request)
+		assert.Error(t, act.GitCredentialStore(gptest.CliCtx(ctx, t)))
+	})
+}
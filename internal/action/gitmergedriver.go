@@ -0,0 +1,136 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/editor"
+	"github.com/kpitt/gopass/internal/merge"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store/leaf"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// GitMergeDriver merges two versions of an encrypted secret. It is invoked
+// by git as `gopass git-merge-driver %O %A %B %P` from the root of the
+// store. The result is written to the file of the current version (%A).
+func (s *Action) GitMergeDriver(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if c.Args().Len() < 4 {
+		return exit.Error(exit.Usage, nil, "Usage: %s git-merge-driver <base> <ours> <theirs> <path>", s.Name)
+	}
+
+	baseFile, oursFile, theirsFile, name := c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), c.Args().Get(3)
+
+	sub, err := s.storeForDir(ctx)
+	if err != nil {
+		return exit.Error(exit.NotFound, err, "%s", err)
+	}
+
+	versions := make([][]byte, 0, 3)
+	for _, f := range []string{baseFile, oursFile, theirsFile} {
+		buf, err := os.ReadFile(f)
+		if err != nil {
+			return exit.Error(exit.IO, err, "failed to read %s: %s", f, err)
+		}
+
+		plaintext, err := sub.DecryptFile(ctx, name, buf)
+		if err != nil {
+			return exit.Error(exit.Decrypt, err, "failed to decrypt %s: %s", name, err)
+		}
+
+		versions = append(versions, plaintext)
+	}
+
+	content, ok := merge.Secrets(versions[0], versions[1], versions[2])
+	if !ok {
+		content, err = s.gitMergeResolve(ctx, c, name, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	ciphertext, err := sub.EncryptFile(ctx, name, content)
+	if err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to encrypt %s: %s", name, err)
+	}
+
+	if err := os.WriteFile(oursFile, ciphertext, 0o600); err != nil {
+		return exit.Error(exit.IO, err, "failed to write %s: %s", oursFile, err)
+	}
+
+	return nil
+}
+
+// gitMergeResolve lets the user resolve conflicts in an editor. Without a
+// terminal the merge fails and git leaves the conflict to the user.
+func (s *Action) gitMergeResolve(ctx context.Context, c *cli.Context, name string, content []byte) ([]byte, error) {
+	if !ctxutil.IsInteractive(ctx) || !ctxutil.IsTerminal(ctx) {
+		return nil, exit.Error(exit.Aborted, nil, "Conflicting changes in %s", name)
+	}
+
+	out.Noticef(ctx, "Conflicting changes in %s, please resolve them in the editor", name)
+
+	content, err := editor.Invoke(ctx, editor.Path(c), content)
+	if err != nil {
+		return nil, exit.Error(exit.Unknown, err, "failed to invoke editor: %s", err)
+	}
+
+	if merge.HasConflicts(content) {
+		return nil, exit.Error(exit.Aborted, nil, "Unresolved conflicts in %s", name)
+	}
+
+	return content, nil
+}
+
+// storeForDir returns the store that contains the current working
+// directory. git runs merge drivers from the root of the repository, other
+// commands like textconv filters from where git was invoked.
+func (s *Action) storeForDir(ctx context.Context) (*leaf.Store, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	wd = realPath(wd)
+
+	mounts := map[string]string{"": s.Store.Path()}
+	for alias, path := range s.Store.Mounts() {
+		mounts[alias] = path
+	}
+
+	// mounts may be nested, the innermost one wins.
+	found, foundPath := "", ""
+	for alias, path := range mounts {
+		path = realPath(path)
+		if wd != path && !strings.HasPrefix(wd, path+string(filepath.Separator)) {
+			continue
+		}
+
+		if foundPath == "" || len(path) > len(foundPath) {
+			found, foundPath = alias, path
+		}
+	}
+
+	if foundPath == "" {
+		return nil, fmt.Errorf("no store in %s", wd)
+	}
+
+	debug.Log("using store %q in %s", found, foundPath)
+
+	return s.Store.GetSubStore(found)
+}
+
+func realPath(p string) string {
+	if rp, err := filepath.EvalSymlinks(p); err == nil {
+		p = rp
+	}
+
+	return filepath.Clean(p)
+}
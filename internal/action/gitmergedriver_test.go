@@ -0,0 +1,85 @@
+package action
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitMergeDriver(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	sub, err := act.Store.GetSubStore("")
+	require.NoError(t, err)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(u.StoreDir("")))
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	// writeVersions writes the encrypted versions of foo like git does.
+	writeVersions := func(t *testing.T, versions ...string) []string {
+		t.Helper()
+
+		files := make([]string, 0, len(versions))
+		for i, v := range versions {
+			buf, err := sub.EncryptFile(ctx, "foo.txt", []byte(v))
+			require.NoError(t, err)
+
+			fn := filepath.Join(u.Dir, string(rune('a'+i)))
+			require.NoError(t, os.WriteFile(fn, buf, 0o600))
+			files = append(files, fn)
+		}
+
+		return files
+	}
+
+	t.Run("missing args", func(t *testing.T) { //nolint:paralleltest
+		assert.Error(t, act.GitMergeDriver(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("merge keys", func(t *testing.T) { //nolint:paralleltest
+		files := writeVersions(t, "foo\nuser: alice\nurl: example.org\n", "foo\nuser: bob\nurl: example.org\n", "bar\nuser: alice\nurl: example.org\n")
+		require.NoError(t, act.GitMergeDriver(gptest.CliCtx(ctx, t, files[0], files[1], files[2], "foo.txt")))
+
+		buf, err := os.ReadFile(files[1])
+		require.NoError(t, err)
+		content, err := sub.DecryptFile(ctx, "foo.txt", buf)
+		require.NoError(t, err)
+		assert.Equal(t, "bar\nurl: example.org\nuser: bob", string(content))
+	})
+
+	t.Run("conflict", func(t *testing.T) { //nolint:paralleltest
+		files := writeVersions(t, "foo\n", "bar\n", "baz\n")
+		assert.Error(t, act.GitMergeDriver(gptest.CliCtx(ctx, t, files[0], files[1], files[2], "foo.txt")))
+
+		// the current version is left untouched.
+		buf, err := os.ReadFile(files[1])
+		require.NoError(t, err)
+		content, err := sub.DecryptFile(ctx, "foo.txt", buf)
+		require.NoError(t, err)
+		assert.Equal(t, "bar\n", string(content))
+	})
+
+	t.Run("not a secret", func(t *testing.T) { //nolint:paralleltest
+		files := writeVersions(t, "foo\n", "bar\n", "baz\n")
+		assert.Error(t, act.GitMergeDriver(gptest.CliCtx(ctx, t, files[0], files[1], files[2], ".gitattributes")))
+	})
+}
@@ -0,0 +1,49 @@
+package action
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// GitTextconv prints the decrypted content of a secret file. It is invoked
+// by git as textconv filter, e.g. for `git diff` or `git log -p`. git keeps
+// the extension of its temporary files, so the crypto backend can be told
+// from the file name.
+func (s *Action) GitTextconv(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	file := c.Args().First()
+	if file == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s git-textconv <file>", s.Name)
+	}
+
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return exit.Error(exit.IO, err, "failed to read %s: %s", file, err)
+	}
+
+	sub, err := s.storeForDir(ctx)
+	if err != nil {
+		return exit.Error(exit.NotFound, err, "%s", err)
+	}
+
+	// a diff must not fail because a single revision can not be decrypted,
+	// e.g. one that was encrypted for other recipients.
+	plaintext, err := sub.DecryptFile(ctx, file, buf)
+	if err != nil {
+		debug.Log("failed to decrypt %s: %s", file, err)
+
+		fmt.Fprintln(stdout, "(gopass: can not decrypt this revision)")
+
+		return nil
+	}
+
+	_, _ = stdout.Write(plaintext)
+
+	return nil
+}
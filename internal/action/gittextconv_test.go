@@ -0,0 +1,71 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitTextconv(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+	}()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(u.StoreDir("")))
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	t.Run("missing args", func(t *testing.T) { //nolint:paralleltest
+		assert.Error(t, act.GitTextconv(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("secret", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		sub, err := act.Store.GetSubStore("")
+		require.NoError(t, err)
+
+		ciphertext, err := sub.EncryptFile(ctx, "foo.txt", []byte("secret\nuser: alice\n"))
+		require.NoError(t, err)
+
+		// git keeps the extension of the file.
+		fn := filepath.Join(u.Dir, "Ab12Cd_foo.txt")
+		require.NoError(t, os.WriteFile(fn, ciphertext, 0o600))
+
+		require.NoError(t, act.GitTextconv(gptest.CliCtx(ctx, t, fn)))
+		assert.Equal(t, "secret\nuser: alice\n", buf.String())
+	})
+
+	t.Run("not a secret", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		fn := filepath.Join(u.Dir, "foo.bin")
+		require.NoError(t, os.WriteFile(fn, []byte("foo"), 0o600))
+
+		require.NoError(t, act.GitTextconv(gptest.CliCtx(ctx, t, fn)))
+		assert.Contains(t, buf.String(), "can not decrypt")
+	})
+}
@@ -1,14 +1,16 @@
 package action
 
 import (
-	"regexp"
+	"context"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/grep"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/tree"
 	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/termio"
 	"github.com/urfave/cli/v2"
 )
 
@@ -16,48 +18,126 @@ import (
 func (s *Action) Grep(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	if !c.Args().Present() {
-		return exit.Error(exit.Usage, nil, "Usage: %s grep arg", s.Name)
+		return exit.Error(exit.Usage, nil, "Usage: %s grep arg [prefix]", s.Name)
 	}
 
 	// get the search term.
 	needle := c.Args().First()
 
-	haystack, err := s.Store.List(ctx, tree.INF)
+	match, err := grep.NewMatcher(needle, c.Bool("regexp"), c.Bool("ignore-case"))
 	if err != nil {
-		return exit.Error(exit.List, err, "failed to list store: %s", err)
+		return exit.Error(exit.Usage, err, "%s", err)
+	}
+
+	mount := c.String("store")
+	if mount != "" && !s.hasMount(mount) {
+		return exit.Error(exit.Mount, nil, "Store %q does not exist", mount)
+	}
+
+	if c.Int("context") < 0 {
+		return exit.Error(exit.Usage, nil, "--context must not be negative")
+	}
+
+	opts := grep.Options{
+		Fields:  splitFields(c.String("field")),
+		Context: c.Int("context"),
 	}
 
-	matchFn := func(haystack string) bool {
-		return strings.Contains(haystack, needle)
+	names, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return exit.Error(exit.List, err, "failed to list store: %s", err)
 	}
 
-	if c.Bool("regexp") {
-		re, err := regexp.Compile(needle)
-		if err != nil {
-			return exit.Error(exit.Usage, err, "failed to compile regexp %q: %s", needle, err)
+	prefix := strings.TrimSuffix(c.Args().Get(1), "/")
+	haystack := make([]string, 0, len(names))
+	for _, name := range names {
+		if inSelection(name, prefix, mount, s.Store.MountPoint(name)) {
+			haystack = append(haystack, name)
 		}
-		matchFn = re.MatchString
 	}
 
-	var matches int
-	var errors int
-	for _, v := range haystack {
-		sec, err := s.Store.Get(ctx, v)
-		if err != nil {
-			out.Errorf(ctx, "failed to decrypt %s: %v", v, err)
+	bar := termio.NewProgressBar("Searching secrets", int64(len(haystack)))
+	bar.Hidden = ctxutil.IsHidden(ctx) || !ctxutil.IsTerminal(ctx)
+
+	results := grep.Search(ctx, haystack, s.Store, match, opts, bar.Inc)
+	bar.Done()
+
+	var matches, matchedSecrets, errors int
+	for _, r := range results {
+		if r.Err != nil {
+			errors++
+			out.Errorf(ctx, "failed to decrypt %s: %v", r.Name, r.Err)
 
 			continue
 		}
 
-		if matchFn(string(sec.Bytes())) {
-			out.Printf(ctx, "%s matches", color.BlueString(v))
+		matchedSecrets++
+		matches += r.Matches()
+
+		if c.Bool("files-with-matches") {
+			out.Printf(ctx, "%s", color.BlueString(r.Name))
+
+			continue
 		}
+
+		printGrepResult(ctx, r, opts.Context > 0)
 	}
 
+	out.Printf(ctx, "\nScanned %d secrets. %d matches in %d secrets, %d errors", len(haystack), matches, matchedSecrets, errors)
+
 	if errors > 0 {
-		out.Warningf(ctx, "%d secrets failed to decrypt", errors)
+		return exit.Error(exit.Decrypt, nil, "%d secrets failed to decrypt", errors)
+	}
+
+	if matchedSecrets < 1 {
+		return exit.Error(exit.NotFound, nil, "No matches found")
 	}
-	out.Printf(ctx, "\nScanned %d secrets. %d matches, %d errors", len(haystack), matches, errors)
 
 	return nil
 }
+
+// printGrepResult prints the lines of a result like grep does: matching
+// lines are separated by ':', context lines by '-' and non-adjacent groups
+// of lines by "--".
+func printGrepResult(ctx context.Context, r grep.Result, separate bool) {
+	first := true
+	for _, f := range r.Fields {
+		for _, g := range f.Groups {
+			if separate && !first {
+				out.Printf(ctx, "%s", color.CyanString("--"))
+			}
+			first = false
+
+			for _, l := range g {
+				sep := "-"
+				text := l.Text
+				if l.Match {
+					sep = ":"
+					text = color.RedString("%s", text)
+				}
+
+				label := color.BlueString(r.Name) + sep
+				if f.Field != "" {
+					label += color.MagentaString(f.Field) + sep
+				}
+				out.Printf(ctx, "%s%s%s%s", label, color.GreenString("%d", l.No), sep, text)
+			}
+		}
+	}
+}
+
+// splitFields splits the comma separated list of fields.
+func splitFields(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	fields := make([]string, 0, 4)
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, strings.ToLower(f))
+		}
+	}
+
+	return fields
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/fatih/color"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/pkg/ctxutil"
 	"github.com/kpitt/gopass/pkg/gopass/secrets"
@@ -31,28 +33,56 @@ func TestGrep(t *testing.T) { //nolint:paralleltest
 		out.Stdout = os.Stdout
 	}()
 
+	color.NoColor = true
+
 	c := gptest.CliCtx(ctx, t, "foo")
-	t.Run("empty store", func(t *testing.T) { //nolint:paralleltest
+	t.Run("no match", func(t *testing.T) { //nolint:paralleltest
 		defer buf.Reset()
-		assert.NoError(t, act.Grep(c))
+		assert.Error(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "Scanned 1 secrets. 0 matches in 0 secrets, 0 errors")
 	})
 
 	t.Run("add some secret", func(t *testing.T) { //nolint:paralleltest
 		defer buf.Reset()
-		sec := &secrets.Plain{}
+		sec := secrets.NewKV()
 		sec.SetPassword("foobar")
-		sec.WriteString("foobar")
-		assert.NoError(t, act.Store.Set(ctx, "foo", sec))
+		require.NoError(t, sec.Set("user", "foo"))
+		_, err := sec.Write([]byte("first\nfoobar\nlast\n"))
+		require.NoError(t, err)
+		assert.NoError(t, act.Store.Set(ctx, "web/foo", sec))
 	})
 
 	t.Run("should find existing", func(t *testing.T) { //nolint:paralleltest
 		defer buf.Reset()
 		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "web/foo:1:foobar\nweb/foo:2:user: foo\nweb/foo:4:foobar\n")
+		assert.Contains(t, buf.String(), "Scanned 2 secrets. 3 matches in 1 secrets, 0 errors")
 	})
 
 	t.Run("RE2", func(t *testing.T) { //nolint:paralleltest
 		defer buf.Reset()
-		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"regexp": "true"}, "f..bar")
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"regexp": "true", "ignore-case": "true"}, "F..BAR")
 		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "2 matches in 1 secrets")
+	})
+
+	t.Run("fields with context", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"field": "user,body", "context": "1"}, "foo")
+		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "web/foo:user:1:foo\n--\nweb/foo-body-1-first\nweb/foo:body:2:foobar\nweb/foo-body-3-last\n")
+	})
+
+	t.Run("names only", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"files-with-matches": "true"}, "foo")
+		assert.NoError(t, act.Grep(c))
+		assert.True(t, strings.HasPrefix(buf.String(), "web/foo\n"), buf.String())
+	})
+
+	t.Run("prefix", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		assert.Error(t, act.Grep(gptest.CliCtx(ctx, t, "foo", "db")))
+		assert.Error(t, act.Grep(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "nope"}, "foo")))
 	})
 }
@@ -1,12 +1,20 @@
 package action
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/diff"
 	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/set"
 	"github.com/kpitt/gopass/pkg/ctxutil"
 	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
 	"github.com/urfave/cli/v2"
 )
 
@@ -15,6 +23,8 @@ func (s *Action) History(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	name := c.Args().Get(0)
 	showPassword := c.Bool("password")
+	patch := c.Bool("patch")
+	reveal := c.Bool("reveal")
 
 	if name == "" {
 		return exit.Error(exit.Usage, nil, "Usage: %s history <NAME>", s.Name)
@@ -29,19 +39,156 @@ func (s *Action) History(c *cli.Context) error {
 		return exit.Error(exit.Unknown, err, "Failed to get revisions: %s", err)
 	}
 
-	for _, rev := range revs {
+	var patches [][]string
+	if patch {
+		patches = s.historyPatches(ctx, name, revs, reveal)
+	}
+
+	if ctxutil.IsJSON(ctx) {
+		doc := historyJSON{
+			Name:      name,
+			Revisions: make([]revisionJSON, 0, len(revs)),
+		}
+		for i, rev := range revs {
+			rj := newRevisionJSON(rev)
+			if showPassword {
+				rj.Password = s.historyPassword(ctx, name, rev.Hash)
+			}
+			if patch {
+				rj.Patch = patches[i]
+			}
+			doc.Revisions = append(doc.Revisions, rj)
+		}
+
+		return printJSON(doc)
+	}
+
+	for i, rev := range revs {
 		pw := ""
 		if showPassword {
-			_, sec, err := s.Store.GetRevision(ctx, name, rev.Hash)
-			if err != nil {
-				debug.Log("Failed to get revision %q of %q: %s", rev.Hash, name, err)
-			}
-			if err == nil {
-				pw = " - " + sec.Password()
+			if p := s.historyPassword(ctx, name, rev.Hash); p != "" {
+				pw = " - " + p
 			}
 		}
 		out.Printf(ctx, "%s - %s <%s> - %s - %s%s\n", rev.Hash, rev.AuthorName, rev.AuthorEmail, rev.Date.Format(time.RFC3339), rev.Subject, pw)
+
+		if !patch {
+			continue
+		}
+
+		for _, line := range patches[i] {
+			out.Printf(ctx, "  %s\n", line)
+		}
+		out.Printf(ctx, "\n")
 	}
 
 	return nil
 }
+
+// historyPatches returns the changes made by each revision. Revisions are
+// ordered from newest to oldest, so every revision is compared to the next
+// one. The oldest revision is compared to an empty secret.
+func (s *Action) historyPatches(ctx context.Context, name string, revs []backend.Revision, reveal bool) [][]string {
+	secs := make([]gopass.Secret, len(revs))
+	for i, rev := range revs {
+		_, sec, err := s.Store.GetRevision(ctx, name, rev.Hash)
+		if err != nil {
+			debug.Log("Failed to get revision %q of %q: %s", rev.Hash, name, err)
+
+			continue
+		}
+		secs[i] = sec
+	}
+
+	patches := make([][]string, len(revs))
+	for i := range revs {
+		if secs[i] == nil {
+			patches[i] = []string{"(can not decrypt this revision)"}
+
+			continue
+		}
+
+		var prev gopass.Secret = secrets.NewKV()
+		if i+1 < len(revs) {
+			prev = secs[i+1]
+		}
+
+		if prev == nil {
+			patches[i] = []string{"(can not decrypt the previous revision)"}
+
+			continue
+		}
+
+		patches[i] = secretPatch(prev, secs[i], reveal)
+	}
+
+	return patches
+}
+
+// secretPatch returns a key level diff of two secrets. All values, including
+// keys like private and the lines of the body, are masked unless reveal is
+// true. Only the names of the changed keys are shown then.
+func secretPatch(old, cur gopass.Secret, reveal bool) []string {
+	mask := func(v string) string {
+		if reveal {
+			return v
+		}
+
+		return "*****"
+	}
+
+	var lines []string
+
+	if op, cp := old.Password(), cur.Password(); op != cp {
+		if op != "" {
+			lines = append(lines, fmt.Sprintf("%c password: %s", diff.Removed, mask(op)))
+		}
+		if cp != "" {
+			lines = append(lines, fmt.Sprintf("%c password: %s", diff.Added, mask(cp)))
+		}
+	}
+
+	for _, key := range set.Sorted(append(old.Keys(), cur.Keys()...)) {
+		ov, _ := old.Values(key)
+		cv, _ := cur.Values(key)
+		if strings.Join(ov, "\n") == strings.Join(cv, "\n") {
+			continue
+		}
+
+		for _, v := range ov {
+			lines = append(lines, fmt.Sprintf("%c %s: %s", diff.Removed, key, mask(v)))
+		}
+		for _, v := range cv {
+			lines = append(lines, fmt.Sprintf("%c %s: %s", diff.Added, key, mask(v)))
+		}
+	}
+
+	for _, l := range diff.Lines(bodyLines(old.Body()), bodyLines(cur.Body())) {
+		if l.Op != diff.Same {
+			lines = append(lines, fmt.Sprintf("%c %s", l.Op, mask(l.Text)))
+		}
+	}
+
+	return lines
+}
+
+func bodyLines(body string) []string {
+	if body == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+}
+
+// historyPassword returns the password of the given revision or an empty
+// string if the revision can not be decrypted.
+func (s *Action) historyPassword(ctx context.Context, name, revision string) string {
+	_, sec, err := s.Store.GetRevision(ctx, name, revision)
+	if err != nil {
+		debug.Log("Failed to get revision %q of %q: %s", revision, name, err)
+
+		return ""
+	}
+
+	return sec.Password()
+}
@@ -3,6 +3,7 @@ package action
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/kpitt/gopass/internal/config"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
 	"github.com/kpitt/gopass/pkg/termio"
 	"github.com/kpitt/gopass/tests/gptest"
 	"github.com/stretchr/testify/assert"
@@ -68,4 +70,75 @@ func TestHistory(t *testing.T) { //nolint:paralleltest
 		defer buf.Reset()
 		assert.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "bar")))
 	})
+
+	t.Run("history --patch bar", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		sec := secrets.NewKV()
+		sec.SetPassword("hunter2")
+		require.NoError(t, sec.Set("user", "alice"))
+		require.NoError(t, act.Store.Set(ctx, "bar", sec))
+
+		assert.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"patch": "true"}, "bar")))
+		assert.Contains(t, buf.String(), "+ password: *****")
+		assert.Contains(t, buf.String(), "+ user: *****")
+		assert.NotContains(t, buf.String(), "hunter2")
+		assert.NotContains(t, buf.String(), "alice")
+		buf.Reset()
+
+		assert.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"patch": "true", "reveal": "true"}, "bar")))
+		assert.Contains(t, buf.String(), "+ password: hunter2")
+		assert.Contains(t, buf.String(), "+ user: alice")
+	})
+
+	t.Run("history --json bar", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+		stdout = buf
+		defer func() {
+			stdout = os.Stdout
+		}()
+
+		assert.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"json": "true"}, "bar")))
+
+		var doc historyJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "bar", doc.Name)
+		require.NotEmpty(t, doc.Revisions)
+		assert.NotEmpty(t, doc.Revisions[0].Hash)
+	})
+}
+
+func TestSecretPatch(t *testing.T) {
+	t.Parallel()
+
+	old, err := secrets.ParseKV([]byte("foo\nuser: alice\nurl: example.org\nprivate: key1\nline1\nline2\n"))
+	require.NoError(t, err)
+	cur, err := secrets.ParseKV([]byte("bar\nuser: bob\nurl: example.org\nprivate: key2\npassphrase: s3cr3t\nline1\nline3\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"- password: *****",
+		"+ password: *****",
+		"+ passphrase: *****",
+		"- private: *****",
+		"+ private: *****",
+		"- user: *****",
+		"+ user: *****",
+		"- *****",
+		"+ *****",
+	}, secretPatch(old, cur, false))
+
+	assert.Equal(t, []string{
+		"- password: foo",
+		"+ password: bar",
+		"+ passphrase: s3cr3t",
+		"- private: key1",
+		"+ private: key2",
+		"- user: alice",
+		"+ user: bob",
+		"- line2",
+		"+ line3",
+	}, secretPatch(old, cur, true))
+
+	assert.Empty(t, secretPatch(cur, cur, false))
 }
@@ -0,0 +1,182 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/importer"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/termio"
+	"github.com/urfave/cli/v2"
+)
+
+// Import reads the export of another password manager and writes its
+// entries to the store.
+func (s *Action) Import(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if c.Args().Len() != 1 {
+		return exit.Error(exit.Usage, nil, "Usage: %s import [--format=F] [--folder=F] [--conflict=P] [--dry-run] <file>", s.Name)
+	}
+	filename := c.Args().First()
+
+	format := c.String("format")
+	if format == "" {
+		f, err := importer.Detect(filename)
+		if err != nil {
+			return exit.Error(exit.Usage, err, "%s", err)
+		}
+		format = f
+	}
+
+	read, err := importer.Get(format)
+	if err != nil {
+		return exit.Error(exit.Usage, err, "%s", err)
+	}
+
+	policy, err := importer.ParsePolicy(c.String("conflict"))
+	if err != nil {
+		return exit.Error(exit.Usage, err, "%s", err)
+	}
+
+	opts := importer.Options{}
+	if kf := c.String("keyfile"); kf != "" {
+		buf, err := os.ReadFile(kf)
+		if err != nil {
+			return exit.Error(exit.IO, err, "failed to read key file %q: %s", kf, err)
+		}
+		opts.KeyFile = buf
+	}
+	if importer.NeedsPassword(format) {
+		pw, err := termio.AskForPassword(ctx, fmt.Sprintf("password for %s", filepath.Base(filename)), false)
+		if err != nil {
+			return exit.Error(exit.Aborted, err, "failed to read password: %s", err)
+		}
+		opts.Password = pw
+	}
+
+	fh, err := os.Open(filename)
+	if err != nil {
+		return exit.Error(exit.IO, err, "failed to open %q: %s", filename, err)
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+
+	entries, err := read(fh, opts)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to read %q: %s", filename, err)
+	}
+	debug.Log("Read %d entries from %s (%s)", len(entries), filename, format)
+
+	items := importer.Plan(entries, c.String("folder"), policy, func(name string) bool {
+		return s.Store.Exists(ctx, name)
+	})
+
+	if c.Bool("dry-run") {
+		printImportPlan(ctx, items)
+
+		return nil
+	}
+
+	return s.importItems(ctx, filepath.Base(filename), items)
+}
+
+func printImportPlan(ctx context.Context, items []importer.Item) {
+	counts := make(map[importer.Op]int, 4)
+	for _, it := range items {
+		counts[it.Op]++
+		if it.Op == importer.OpRename {
+			out.Printf(ctx, "%-9s %s -> %s", it.Op, it.Original, it.Name)
+
+			continue
+		}
+		out.Printf(ctx, "%-9s %s", it.Op, it.Name)
+	}
+
+	out.Noticef(ctx, "Dry run: %d to create, %d to overwrite, %d to rename, %d to skip",
+		counts[importer.OpCreate], counts[importer.OpOverwrite], counts[importer.OpRename], counts[importer.OpSkip])
+}
+
+// importItems writes all planned secrets and creates a single commit in
+// each affected mount.
+func (s *Action) importItems(ctx context.Context, source string, items []importer.Item) error {
+	ctx = ctxutil.WithGitCommit(ctx, false)
+
+	written := make(map[string]int, 1)
+	skipped := 0
+	for _, it := range items {
+		if it.Op == importer.OpSkip {
+			out.Warningf(ctx, "Skipping existing secret %s", it.Name)
+			skipped++
+
+			continue
+		}
+
+		if err := s.Store.Set(ctx, it.Name, it.Secret); err != nil {
+			return exit.Error(exit.Encrypt, err, "failed to write %s: %s", it.Name, err)
+		}
+		debug.Log("imported %s (%s)", it.Name, it.Op)
+		written[s.Store.MountPoint(it.Name)]++
+	}
+
+	mps := make([]string, 0, len(written))
+	for mp := range written {
+		mps = append(mps, mp)
+	}
+	sort.Strings(mps)
+
+	total := 0
+	for _, mp := range mps {
+		total += written[mp]
+		if err := s.commitImport(ctx, mp, fmt.Sprintf("Import %d secrets from %s", written[mp], source)); err != nil {
+			return exit.Error(exit.Git, err, "%s", err)
+		}
+	}
+
+	out.OKf(ctx, "Imported %d secrets (%d skipped)", total, skipped)
+
+	return nil
+}
+
+func (s *Action) commitImport(ctx context.Context, mp, msg string) error {
+	sub, err := s.Store.GetSubStore(mp)
+	if err != nil {
+		return fmt.Errorf("failed to get sub store %q: %w", mp, err)
+	}
+
+	if err := sub.Storage().Commit(ctx, msg); err != nil {
+		switch {
+		case errors.Is(err, store.ErrGitNotInit):
+			debug.Log("skipping git commit - git not initialized in %s", sub.Alias())
+
+			return nil
+		case errors.Is(err, store.ErrGitNothingToCommit):
+			debug.Log("skipping git commit - nothing to commit in %s", sub.Alias())
+
+			return nil
+		default:
+			return fmt.Errorf("failed to commit changes to git (%s): %w", sub.Alias(), err)
+		}
+	}
+
+	if err := sub.Storage().Push(ctx, "", ""); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) || errors.Is(err, store.ErrGitNoRemote) {
+			debug.Log("skipping git push in %s: %s", sub.Alias(), err)
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to push change to git remote: %w", err)
+	}
+
+	return nil
+}
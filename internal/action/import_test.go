@@ -0,0 +1,82 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	fn := filepath.Join(u.Dir, "export.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("Title,Username,Password\nfoo,alice,s3cr3t\nweb,bob,hunter2\n"), 0o600))
+
+	t.Run("no args", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Import(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Import(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "lastpass"}, fn)))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Import(gptest.CliCtxWithFlags(ctx, t, map[string]string{"dry-run": "true", "conflict": "suffix"}, fn)))
+		assert.Contains(t, buf.String(), "rename    foo -> foo-1")
+		assert.Contains(t, buf.String(), "create    web")
+		assert.False(t, act.Store.Exists(ctx, "web"))
+	})
+
+	t.Run("import skip", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Import(gptest.CliCtx(ctx, t, fn)))
+		assert.Contains(t, buf.String(), "Skipping existing secret foo")
+
+		sec, err := act.Store.Get(ctx, "web")
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", sec.Password())
+		v, _ := sec.Get("username")
+		assert.Equal(t, "bob", v)
+
+		sec, err = act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+	})
+
+	t.Run("import overwrite into folder", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Import(gptest.CliCtxWithFlags(ctx, t, map[string]string{"conflict": "overwrite", "folder": "imported"}, fn)))
+
+		sec, err := act.Store.Get(ctx, "imported/foo")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", sec.Password())
+	})
+}
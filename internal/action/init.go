@@ -169,7 +169,7 @@ func (s *Action) init(ctx context.Context, alias, path string, remoteUrl string,
 	}
 
 	be := backend.GetStorageBackend(ctx)
-	if be == backend.GitFS {
+	if be == backend.GitFS || be == backend.GoGit {
 		debug.Log("Initializing git repository...")
 		if err := s.rcsInit(ctx, alias, ctxutil.GetUsername(ctx), ctxutil.GetEmail(ctx)); err != nil {
 			debug.Log("Stacktrace: %+v\n", err)
@@ -189,7 +189,7 @@ func (s *Action) init(ctx context.Context, alias, path string, remoteUrl string,
 	out.Printf(ctx, "✓ Password store %s initialized for:", path)
 	s.printRecipients(ctx, alias)
 
-	if be == backend.GitFS && remoteUrl != "" {
+	if (be == backend.GitFS || be == backend.GoGit) && remoteUrl != "" {
 		debug.Log("configuring git remote: %q", remoteUrl)
 		out.Printf(ctx, "Configuring git remote...")
 		if err := s.initSetupGitRemote(ctx, alias, remoteUrl); err != nil {
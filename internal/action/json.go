@@ -0,0 +1,126 @@
+package action
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/backend"
+)
+
+// The types below define the documents printed by --json. They are meant
+// to be consumed by scripts, so fields must only ever be added, never
+// renamed or removed.
+
+// secretJSON is the document printed by show.
+type secretJSON struct {
+	Name     string              `json:"name"`
+	Revision string              `json:"revision,omitempty"`
+	Password string              `json:"password,omitempty"`
+	Key      string              `json:"key,omitempty"`
+	Values   []string            `json:"values,omitempty"`
+	Keys     map[string][]string `json:"keys,omitempty"`
+	Body     string              `json:"body,omitempty"`
+}
+
+// listJSON is the document printed by list.
+type listJSON struct {
+	Entries []entryJSON `json:"entries"`
+	Mounts  []mountJSON `json:"mounts"`
+}
+
+// entryJSON is a single secret (or folder) in a listing.
+type entryJSON struct {
+	Name  string `json:"name"`
+	Mount string `json:"mount"`
+}
+
+// mountJSON is a single mounted sub store.
+type mountJSON struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// findJSON is the document printed by find.
+type findJSON struct {
+	Matches []string `json:"matches"`
+}
+
+// historyJSON is the document printed by history.
+type historyJSON struct {
+	Name      string         `json:"name"`
+	Revisions []revisionJSON `json:"revisions"`
+}
+
+// revisionJSON is a single revision of a secret.
+type revisionJSON struct {
+	Hash        string    `json:"hash"`
+	AuthorName  string    `json:"author_name"`
+	AuthorEmail string    `json:"author_email"`
+	Date        time.Time `json:"date"`
+	Subject     string    `json:"subject"`
+	Body        string    `json:"body,omitempty"`
+	Password    string    `json:"password,omitempty"`
+	Patch       []string  `json:"patch,omitempty"`
+}
+
+func newRevisionJSON(rev backend.Revision) revisionJSON {
+	return revisionJSON{
+		Hash:        rev.Hash,
+		AuthorName:  rev.AuthorName,
+		AuthorEmail: rev.AuthorEmail,
+		Date:        rev.Date,
+		Subject:     rev.Subject,
+		Body:        rev.Body,
+	}
+}
+
+// recipientsJSON is the document printed by recipients.
+type recipientsJSON struct {
+	Stores []storeRecipientsJSON `json:"stores"`
+}
+
+// storeRecipientsJSON lists the recipients of a store or of a sub folder
+// with its own recipients file.
+type storeRecipientsJSON struct {
+	Mount      string          `json:"mount"`
+	Path       string          `json:"path"`
+	Folder     string          `json:"folder,omitempty"`
+	Recipients []recipientJSON `json:"recipients"`
+}
+
+// recipientJSON is a single recipient. Fingerprint is empty if the public
+// key could not be found.
+type recipientJSON struct {
+	ID          string `json:"id"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// expiringJSON is the document printed by expiring.
+type expiringJSON struct {
+	Secrets []expiringSecretJSON `json:"secrets"`
+}
+
+// expiringSecretJSON is a single expired or expiring secret. Source is one
+// of expires, rotate-every or revision.
+type expiringSecretJSON struct {
+	Name    string     `json:"name"`
+	Mount   string     `json:"mount"`
+	Expires time.Time  `json:"expires"`
+	Expired bool       `json:"expired"`
+	Source  string     `json:"source"`
+	Changed *time.Time `json:"changed,omitempty"`
+}
+
+// printJSON writes v as an indented JSON document to stdout.
+func printJSON(v any) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		return exit.Error(exit.Unknown, err, "failed to encode JSON: %s", err)
+	}
+
+	return nil
+}
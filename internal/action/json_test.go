@@ -0,0 +1,105 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONOutput(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	act, err := newMock(ctx, u.StoreDir(""))
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("123")
+	require.NoError(t, sec.Set("user", "zab"))
+	_, err = sec.Write([]byte("some body"))
+	require.NoError(t, err)
+	require.NoError(t, act.Store.Set(ctx, "bar/baz", sec))
+
+	flags := map[string]string{"json": "true"}
+
+	t.Run("show", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, flags, "bar/baz")))
+
+		var doc secretJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "bar/baz", doc.Name)
+		assert.Equal(t, "123", doc.Password)
+		assert.Equal(t, []string{"zab"}, doc.Keys["user"])
+		assert.Contains(t, doc.Body, "some body")
+	})
+
+	t.Run("show key", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, flags, "bar/baz", "user")))
+
+		var doc secretJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "user", doc.Key)
+		assert.Equal(t, []string{"zab"}, doc.Values)
+		assert.Equal(t, "", doc.Password)
+	})
+
+	t.Run("list", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, flags)))
+
+		var doc listJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, []entryJSON{{Name: "bar/baz"}, {Name: "foo"}}, doc.Entries)
+		assert.Empty(t, doc.Mounts)
+	})
+
+	t.Run("find", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, act.Find(gptest.CliCtxWithFlags(ctx, t, flags, "ba")))
+
+		var doc findJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, []string{"bar/baz"}, doc.Matches)
+	})
+
+	t.Run("recipients", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, act.RecipientsPrint(gptest.CliCtxWithFlags(ctx, t, flags)))
+
+		var doc recipientsJSON
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		require.Len(t, doc.Stores, 1)
+		assert.Equal(t, "", doc.Stores[0].Mount)
+		assert.NotEmpty(t, doc.Stores[0].Recipients)
+	})
+}
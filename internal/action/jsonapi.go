@@ -0,0 +1,36 @@
+package action
+
+import (
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/jsonapi"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/api"
+	"github.com/urfave/cli/v2"
+)
+
+// JSONAPIListen answers the requests of a browser extension until the
+// browser closes the connection. stdin and stdout are reserved for the
+// native messaging protocol. Arguments added by the browser, e.g. the
+// origin of the extension, are ignored.
+func (s *Action) JSONAPIListen(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	gp, err := api.New(ctx)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "failed to initialize store: %s", err)
+	}
+	defer func() {
+		_ = gp.Close(ctx)
+	}()
+
+	a := jsonapi.New(gp, stdin, stdout)
+	a.PathOnly = c.Bool("path-only")
+
+	if err := a.Serve(ctx); err != nil {
+		return exit.Error(exit.IO, err, "failed to serve browser: %s", err)
+	}
+
+	return nil
+}
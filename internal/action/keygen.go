@@ -0,0 +1,83 @@
+package action
+
+import (
+	"context"
+	"path"
+	"strconv"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/keygen"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/clipboard"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+// These keys hold the parts of generated key material.
+const (
+	keyType    = "type"
+	keyPrivate = "private"
+	keyPublic  = "public"
+)
+
+// generateKey generates typed key material and stores it as a new YAML
+// secret. Single line private parts are stored as the password, others in
+// the private key. The public part is stored in the public key.
+func (s *Action) generateKey(ctx context.Context, c *cli.Context, name, typ, key, length string, kvps map[string]string) error {
+	if key != "" {
+		return exit.Error(exit.Usage, nil, "Keys of type %s can only be stored as a new secret", typ)
+	}
+
+	var size int
+	if length != "" {
+		iv, err := strconv.Atoi(length)
+		if err != nil {
+			return exit.Error(exit.Usage, err, "length must be a number")
+		}
+		size = iv
+	}
+
+	k, err := keygen.Generate(typ, size, path.Base(name))
+	if err != nil {
+		return exit.Error(exit.Usage, err, "failed to generate key: %s", err)
+	}
+
+	sec := secrets.NewYAML()
+	sec.SetPassword(k.Password)
+	_ = sec.Set(keyType, k.Kind)
+	if k.Private != "" {
+		_ = sec.Set(keyPrivate, k.Private)
+	}
+	if k.Public != "" {
+		_ = sec.Set(keyPublic, k.Public)
+	}
+	setMetadata(sec, kvps)
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated "+typ+" key"), name, sec); err != nil {
+		return exit.Error(exit.Encrypt, err, "failed to create %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Generated %s key for entry %q", typ, name)
+
+	if k.Public != "" {
+		out.Printf(ctx, "The public part is stored in the %q key:\n\n%s\n", keyPublic, k.Public)
+	}
+
+	secret := k.Password
+	if secret == "" {
+		secret = k.Private
+	}
+
+	if IsClip(ctx) {
+		if err := clipboard.CopyTo(ctx, name, []byte(secret), s.configFor(ctx, name).ClipTimeout); err != nil {
+			return exit.Error(exit.IO, err, "failed to copy to clipboard: %s", err)
+		}
+	}
+
+	if c.Bool("print") {
+		out.Printf(ctx, "- The generated secret is:\n\n%s\n", out.Secret(secret))
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
@@ -30,6 +31,9 @@ func (s *Action) List(c *cli.Context) error {
 
 	// print the path if the argument is a direct hit.
 	if s.Store.Exists(ctx, filter) && !s.Store.IsDir(ctx, filter) {
+		if ctxutil.IsJSON(ctx) {
+			return s.listPrintJSON([]string{filter})
+		}
 		fmt.Println(filter)
 
 		return nil
@@ -75,6 +79,15 @@ func (s *Action) listFiltered(ctx context.Context, l *tree.Root, limit int, flat
 		l.SetName(filter + sep)
 	}
 
+	if ctxutil.IsJSON(ctx) {
+		listOver := l.List
+		if folders {
+			listOver = l.ListFolders
+		}
+
+		return s.listPrintJSON(listOver(limit))
+	}
+
 	if flat {
 		listOver := l.List
 		if folders {
@@ -103,6 +116,34 @@ func (s *Action) listFiltered(ctx context.Context, l *tree.Root, limit int, flat
 	return nil
 }
 
+// listPrintJSON prints the given entries, including their mount point, and
+// all mounts as a JSON document.
+func (s *Action) listPrintJSON(entries []string) error {
+	doc := listJSON{
+		Entries: make([]entryJSON, 0, len(entries)),
+		Mounts:  make([]mountJSON, 0, len(s.Store.Mounts())),
+	}
+
+	for _, e := range entries {
+		doc.Entries = append(doc.Entries, entryJSON{
+			Name:  e,
+			Mount: s.Store.MountPoint(e),
+		})
+	}
+
+	mounts := s.Store.Mounts()
+	aliases := s.Store.MountPoints()
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		doc.Mounts = append(doc.Mounts, mountJSON{
+			Name: alias,
+			Path: mounts[alias],
+		})
+	}
+
+	return printJSON(doc)
+}
+
 // redirectPager returns a redirected io.Writer if the output would exceed
 // the terminal size.
 func redirectPager(ctx context.Context, subtree *tree.Root) (io.Writer, *bytes.Buffer) {
@@ -4,9 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gosuri/uilive"
@@ -19,7 +17,6 @@ import (
 	"github.com/kpitt/gopass/pkg/otp"
 	"github.com/kpitt/gopass/pkg/termio"
 	"github.com/mattn/go-tty"
-	potp "github.com/pquerna/otp"
 	"github.com/pquerna/otp/hotp"
 	"github.com/pquerna/otp/totp"
 	"github.com/urfave/cli/v2"
@@ -142,16 +139,16 @@ func (s *Action) otp(ctx context.Context, name, qrf string, clip, continuous, re
 			token, err = totp.GenerateCodeCustom(two.Secret(), time.Now(), totp.ValidateOpts{
 				Period:    uint(two.Period()),
 				Skew:      1,
-				Digits:    parseDigits(two.URL()),
-				Algorithm: parseAlgorithm(two.URL()),
+				Digits:    otp.ParseDigits(two.URL()),
+				Algorithm: otp.ParseAlgorithm(two.URL()),
 			})
 			if err != nil {
 				return exit.Error(exit.Unknown, err, "Failed to compute OTP token for %s: %s", name, err)
 			}
 		case "hotp":
 			token, err = hotp.GenerateCodeCustom(two.Secret(), counter, hotp.ValidateOpts{
-				Digits:    parseDigits(two.URL()),
-				Algorithm: parseAlgorithm(two.URL()),
+				Digits:    otp.ParseDigits(two.URL()),
+				Algorithm: otp.ParseAlgorithm(two.URL()),
 			})
 			if err != nil {
 				return exit.Error(exit.Unknown, err, "Failed to compute OTP token for %s: %s", name, err)
@@ -170,7 +167,7 @@ func (s *Action) otp(ctx context.Context, name, qrf string, clip, continuous, re
 		debug.Log("OTP period: %ds", two.Period())
 
 		if clip {
-			if err := clipboard.CopyTo(ctx, fmt.Sprintf("token for %s", name), []byte(token), s.cfg.ClipTimeout); err != nil {
+			if err := clipboard.CopyTo(ctx, fmt.Sprintf("token for %s", name), []byte(token), s.configFor(ctx, name).ClipTimeout); err != nil {
 				return exit.Error(exit.IO, err, "failed to copy to clipboard: %s", err)
 			}
 
@@ -217,58 +214,3 @@ func (s *Action) otpHandleError(ctx context.Context, name, qrf string, clip, pw,
 
 	return nil
 }
-
-// parseDigits and parseAlgorithm can be replaced if https://github.com/pquerna/otp/pull/74 is merged.
-func parseDigits(ku string) potp.Digits {
-	u, err := url.Parse(ku)
-	if err != nil {
-		debug.Log("Failed to parse key URL: %s", err)
-
-		// return the most common value
-		return potp.DigitsSix
-	}
-
-	q := u.Query()
-	iv, err := strconv.ParseUint(q.Get("digits"), 10, 64)
-	if err != nil {
-		debug.Log("Failed to parse digits param: %s", err)
-
-		// return the most common value
-		return potp.DigitsSix
-	}
-
-	switch iv {
-	case 6:
-		return potp.DigitsSix
-	case 8:
-		return potp.DigitsEight
-	default:
-		debug.Log("Unsupported digits value: %d", iv)
-
-		// return the most common value
-		return potp.DigitsSix
-	}
-}
-
-func parseAlgorithm(ku string) potp.Algorithm {
-	u, err := url.Parse(ku)
-	if err != nil {
-		debug.Log("Failed to parse key URL: %s", err)
-
-		// return the most common value
-		return potp.AlgorithmSHA1
-	}
-
-	q := u.Query()
-	a := strings.ToLower(q.Get("algorithm"))
-	switch a {
-	case "md5":
-		return potp.AlgorithmMD5
-	case "sha256":
-		return potp.AlgorithmSHA256
-	case "sha512":
-		return potp.AlgorithmSHA512
-	default:
-		return potp.AlgorithmSHA1
-	}
-}
@@ -1,12 +1,15 @@
 package action
 
 import (
+	"context"
 	"io/ioutil"
+	"time"
 
 	"github.com/kpitt/gopass/internal/action/exit"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/tpl"
 	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/fsutil"
 	"github.com/urfave/cli/v2"
 )
 
@@ -18,6 +21,10 @@ func (s *Action) Process(c *cli.Context) error {
 		return exit.Error(exit.Usage, nil, "Usage: %s process <FILE>", s.Name)
 	}
 
+	if fsutil.IsDir(file) {
+		return s.processDir(ctx, c, file)
+	}
+
 	buf, err := ioutil.ReadFile(file)
 	if err != nil {
 		return exit.Error(exit.IO, err, "Failed to read file: %s", file)
@@ -32,3 +39,126 @@ func (s *Action) Process(c *cli.Context) error {
 
 	return nil
 }
+
+// processDir renders all templates below dir into the output directory.
+func (s *Action) processDir(ctx context.Context, c *cli.Context, dir string) error {
+	dst := c.String("output")
+	if dst == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s process --output <DIR> <DIR>", s.Name)
+	}
+
+	var interval time.Duration
+	if c.Bool("watch") {
+		iv, err := time.ParseDuration(c.String("interval"))
+		if err != nil || iv <= 0 {
+			return exit.Error(exit.Usage, err, "Invalid interval %q", c.String("interval"))
+		}
+		interval = iv
+	}
+
+	files, err := tpl.RenderDir(ctx, dir, dst, s.Store)
+	if err != nil {
+		return exit.Error(exit.IO, err, "Failed to process %s: %s", dir, err)
+	}
+
+	if c.Bool("check") {
+		return checkTemplates(ctx, files)
+	}
+
+	for _, f := range files {
+		if err := f.Write(); err != nil {
+			return exit.Error(exit.IO, err, "%s", err)
+		}
+		out.OKf(ctx, "Rendered %s", f.Target)
+	}
+
+	if interval == 0 {
+		return nil
+	}
+
+	return s.watchTemplates(ctx, files, interval)
+}
+
+// checkTemplates reports all rendered files that differ from the files on
+// disk. Like diff it exits with 1 if there are any differences.
+func checkTemplates(ctx context.Context, files []*tpl.File) error {
+	drifted := 0
+	for _, f := range files {
+		drift, err := f.Drift()
+		if err != nil {
+			return exit.Error(exit.IO, err, "%s", err)
+		}
+
+		if drift == "" {
+			continue
+		}
+
+		drifted++
+		out.Printf(ctx, "%s: %s", f.Target, drift)
+	}
+
+	if drifted > 0 {
+		return exit.Error(exit.Unknown, nil, "%d of %d files have drifted", drifted, len(files))
+	}
+
+	out.OKf(ctx, "All %d files are up to date", len(files))
+
+	return nil
+}
+
+// watchTemplates syncs the stores at every interval and re-renders the
+// templates that use changed secrets. It runs until the context is
+// canceled.
+func (s *Action) watchTemplates(ctx context.Context, files []*tpl.File, interval time.Duration) error {
+	out.Printf(ctx, "Watching %d templates for changed secrets every %s", len(files), interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		_ = s.sync(ctx, "", false)
+
+		if err := s.rerenderTemplates(ctx, files); err != nil {
+			out.Errorf(ctx, "%s", err)
+		}
+	}
+}
+
+// rerenderTemplates renders and writes all stale templates again. Templates
+// that fail to render keep their old output.
+func (s *Action) rerenderTemplates(ctx context.Context, files []*tpl.File) error {
+	for i, f := range files {
+		stale, err := f.Stale(ctx, s.Store)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if !stale {
+			continue
+		}
+
+		nf, err := tpl.RenderFile(ctx, f.Source, f.Target, s.Store)
+		if err != nil {
+			out.Errorf(ctx, "Failed to render %s: %s", f.Source, err)
+
+			continue
+		}
+
+		if err := nf.Write(); err != nil {
+			out.Errorf(ctx, "%s", err)
+
+			continue
+		}
+
+		files[i] = nf
+		out.OKf(ctx, "Rendered %s", nf.Target)
+	}
+
+	return nil
+}
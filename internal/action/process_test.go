@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/tpl"
 	"github.com/kpitt/gopass/pkg/ctxutil"
 	"github.com/kpitt/gopass/pkg/gopass/secrets"
 	"github.com/kpitt/gopass/tests/gptest"
@@ -61,4 +62,70 @@ user=admin
 password=hunter2
 `, buf.String(), "processed template")
 	})
+	t.Run("process directory", func(t *testing.T) {
+		defer buf.Reset()
+
+		src := filepath.Join(u.Dir, "templates")
+		dst := filepath.Join(u.Dir, "rendered")
+		require.NoError(t, os.MkdirAll(filepath.Join(src, "mysql"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "mysql", "my.cnf.tpl"), []byte(`user={{ getval "server/local/mysql" "username" }}`), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "README"), []byte("not a template"), 0o644))
+
+		assert.Error(t, act.Process(gptest.CliCtx(ctx, t, src)), "no output dir")
+
+		flags := map[string]string{"output": dst}
+		require.NoError(t, act.Process(gptest.CliCtxWithFlags(ctx, t, flags, src)))
+
+		target := filepath.Join(dst, "mysql", "my.cnf")
+		content, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "user=admin", string(content))
+		fi, err := os.Stat(target)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+		assert.NoFileExists(t, filepath.Join(dst, "README"))
+
+		flags["check"] = "true"
+		require.NoError(t, act.Process(gptest.CliCtxWithFlags(ctx, t, flags, src)))
+
+		require.NoError(t, os.Chmod(target, 0o644))
+		buf.Reset()
+		assert.Error(t, act.Process(gptest.CliCtxWithFlags(ctx, t, flags, src)))
+		assert.Contains(t, buf.String(), target+": mode is 0644, want 0600")
+		require.NoError(t, os.Chmod(target, 0o600))
+
+		sec.SetPassword("changed")
+		require.NoError(t, sec.Set("username", "root"))
+		require.NoError(t, act.Store.Set(ctx, "server/local/mysql", sec))
+		buf.Reset()
+		assert.Error(t, act.Process(gptest.CliCtxWithFlags(ctx, t, flags, src)))
+		assert.Contains(t, buf.String(), target+": content differs")
+	})
+	t.Run("rerender stale templates", func(t *testing.T) {
+		defer buf.Reset()
+
+		src := filepath.Join(u.Dir, "watched")
+		dst := filepath.Join(u.Dir, "watched-out")
+		require.NoError(t, os.MkdirAll(src, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "pw.tpl"), []byte(`{{ getpw "server/local/mysql" }}`), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "static.tpl"), []byte("static"), 0o600))
+
+		files, err := tpl.RenderDir(ctx, src, dst, act.Store)
+		require.NoError(t, err)
+		for _, f := range files {
+			require.NoError(t, f.Write())
+		}
+
+		sec.SetPassword("rotated")
+		require.NoError(t, act.Store.Set(ctx, "server/local/mysql", sec))
+
+		buf.Reset()
+		require.NoError(t, act.rerenderTemplates(ctx, files))
+		assert.Contains(t, buf.String(), "pw")
+		assert.NotContains(t, buf.String(), "static")
+
+		content, err := os.ReadFile(filepath.Join(dst, "pw"))
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", string(content))
+	})
 }
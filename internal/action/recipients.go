@@ -3,8 +3,10 @@ package action
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/backend"
 	"github.com/kpitt/gopass/internal/cui"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/tree"
@@ -12,6 +14,7 @@ import (
 	"github.com/kpitt/gopass/pkg/debug"
 	"github.com/kpitt/gopass/pkg/termio"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/maps"
 )
 
 var removalWarning = `
@@ -34,6 +37,10 @@ credentials.
 // RecipientsPrint prints all recipients per store.
 func (s *Action) RecipientsPrint(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	if ctxutil.IsJSON(ctx) {
+		return s.recipientsPrintJSON(ctx)
+	}
+
 	out.Printf(ctx, "Hint: run 'gopass sync' to import any missing public keys")
 
 	t, err := s.Store.RecipientsTree(ctx, true)
@@ -46,6 +53,63 @@ func (s *Action) RecipientsPrint(c *cli.Context) error {
 	return nil
 }
 
+// recipientsPrintJSON prints the recipients of every store and of every
+// sub folder with its own recipients file as a JSON document.
+func (s *Action) recipientsPrintJSON(ctx context.Context) error {
+	doc := recipientsJSON{
+		Stores: make([]storeRecipientsJSON, 0, len(s.Store.MountPoints())+1),
+	}
+
+	mps := append([]string{""}, s.Store.MountPoints()...)
+	sort.Strings(mps)
+
+	for _, alias := range mps {
+		sub, err := s.Store.GetSubStore(alias)
+		if err != nil || sub == nil {
+			debug.Log("failed to get sub store %q: %s", alias, err)
+
+			continue
+		}
+
+		rt := sub.RecipientsTree(ctx)
+		folders := maps.Keys(rt)
+		sort.Strings(folders)
+
+		for _, folder := range folders {
+			sr := storeRecipientsJSON{
+				Mount:      alias,
+				Path:       sub.Path(),
+				Folder:     folder,
+				Recipients: make([]recipientJSON, 0, len(rt[folder])),
+			}
+			for _, id := range rt[folder] {
+				sr.Recipients = append(sr.Recipients, s.recipientJSON(ctx, sub.Crypto(), id))
+			}
+			doc.Stores = append(doc.Stores, sr)
+		}
+	}
+
+	return printJSON(doc)
+}
+
+func (s *Action) recipientJSON(ctx context.Context, crypto backend.Crypto, id string) recipientJSON {
+	r := recipientJSON{
+		ID: id,
+	}
+
+	kl, err := crypto.FindRecipients(ctx, id)
+	if err != nil || len(kl) < 1 {
+		debug.Log("public key for %q not found: %s", id, err)
+
+		return r
+	}
+
+	r.Fingerprint = crypto.Fingerprint(ctx, kl[0])
+	r.Name = crypto.FormatKey(ctx, kl[0], "")
+
+	return r
+}
+
 func (s *Action) recipientsList(ctx context.Context) []string {
 	t, err := s.Store.RecipientsTree(ctxutil.WithHidden(ctx, true), false)
 	if err != nil {
@@ -0,0 +1,33 @@
+package action
+
+import (
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+)
+
+// Restore rolls a secret back to an older revision. The old content is
+// written as a new revision, so the history is kept.
+func (s *Action) Restore(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().Get(0)
+	revision := c.Args().Get(1)
+
+	if name == "" || revision == "" {
+		return exit.Error(exit.Usage, nil, "Usage: %s restore <NAME> <REVISION>", s.Name)
+	}
+
+	revision, err := s.parseRevision(ctx, name, revision)
+	if err != nil {
+		return exit.Error(exit.Usage, err, "Invalid revision %q: %s", c.Args().Get(1), err)
+	}
+
+	if err := s.Store.Restore(ctx, name, revision); err != nil {
+		return exit.Error(exit.Unknown, err, "Failed to restore %s to revision %s: %s", name, revision, err)
+	}
+
+	out.OKf(ctx, "Restored %s to revision %s", name, revision)
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/config"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/pkg/termio"
+	"github.com/kpitt/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestore(t *testing.T) { //nolint:paralleltest
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	cfg := config.New()
+	cfg.Path = u.StoreDir("")
+	act, err := newAction(cfg, "1.0.0", false)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	require.NoError(t, act.IsInitialized(gptest.CliCtx(ctx, t)))
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+
+	for _, pw := range []string{"one", "two"} {
+		sec := secrets.NewKV()
+		sec.SetPassword(pw)
+		require.NoError(t, act.Store.Set(ctx, "bar", sec))
+	}
+
+	revs, err := act.Store.ListRevisions(ctx, "bar")
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	buf.Reset()
+
+	t.Run("restore without revision", func(t *testing.T) { //nolint:paralleltest
+		assert.Error(t, act.Restore(gptest.CliCtx(ctx, t, "bar")))
+	})
+
+	t.Run("restore bar", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, act.Restore(gptest.CliCtx(ctx, t, "bar", revs[1].Hash)))
+
+		sec, err := act.Store.Get(ctx, "bar")
+		require.NoError(t, err)
+		assert.Equal(t, "one", sec.Password())
+
+		// the history is kept.
+		nrevs, err := act.Store.ListRevisions(ctx, "bar")
+		require.NoError(t, err)
+		assert.Len(t, nrevs, 3)
+	})
+
+	t.Run("show --at", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		assert.Error(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"at": "2000-01-01"}, "bar")))
+		assert.Error(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"at": "yesterday"}, "bar")))
+		assert.Error(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"at": "2999-01-01", "revision": "-1"}, "bar")))
+
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"at": "2999-01-01", "password": "true"}, "bar")))
+		assert.Equal(t, "one", buf.String())
+		buf.Reset()
+
+		// a date without a time includes the revisions of the whole day.
+		today := time.Now().Format("2006-01-02")
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"at": today, "password": "true"}, "bar")))
+		assert.Equal(t, "one", buf.String())
+	})
+
+	t.Run("undelete", func(t *testing.T) { //nolint:paralleltest
+		defer buf.Reset()
+
+		require.NoError(t, act.Store.Delete(ctx, "bar"))
+
+		require.NoError(t, act.Undelete(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "bar - deleted by foo bar")
+		assert.Error(t, act.Undelete(gptest.CliCtx(ctx, t, "foo")))
+
+		require.NoError(t, act.Undelete(gptest.CliCtx(ctx, t, "bar")))
+		sec, err := act.Store.Get(ctx, "bar")
+		require.NoError(t, err)
+		assert.Equal(t, "one", sec.Password())
+
+		assert.Error(t, act.Undelete(gptest.CliCtx(ctx, t, "bar")), "exists")
+	})
+}
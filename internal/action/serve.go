@@ -0,0 +1,46 @@
+package action
+
+import (
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/server"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/api"
+	"github.com/urfave/cli/v2"
+)
+
+// Serve exposes the store API on a Unix domain socket until interrupted.
+func (s *Action) Serve(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	path := c.String("socket")
+	if path == "" {
+		path = server.DefaultSocket()
+	}
+
+	gp, err := api.New(ctx)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "failed to initialize store: %s", err)
+	}
+	defer func() {
+		_ = gp.Close(ctx)
+	}()
+
+	l, err := server.Listen(path)
+	if err != nil {
+		return exit.Error(exit.IO, err, "failed to create socket: %s", err)
+	}
+
+	srv := server.New(gp, server.Config{
+		ReadOnly: c.Bool("read-only"),
+		Allow:    c.StringSlice("allow"),
+		UIDs:     c.IntSlice("uid"),
+	})
+
+	out.Printf(ctx, "Listening on %s", path)
+	if err := srv.Serve(ctx, l); err != nil {
+		return exit.Error(exit.IO, err, "failed to serve: %s", err)
+	}
+
+	return nil
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/audit"
+	"github.com/kpitt/gopass/internal/backend"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/store"
 	"github.com/kpitt/gopass/pkg/clipboard"
@@ -67,12 +71,27 @@ func (s *Action) Show(c *cli.Context) error {
 	}
 
 	ctx := showParseArgs(c)
+	if !c.IsSet("noparsing") {
+		ctx = s.withMountContext(ctx, name)
+	}
 
 	if key := c.Args().Get(1); key != "" {
 		debug.Log("Adding key to ctx: %s", key)
 		ctx = WithKey(ctx, key)
 	}
 
+	if c.IsSet("at") {
+		if c.IsSet("revision") {
+			return exit.Error(exit.Usage, nil, "Use either --revision or --at")
+		}
+
+		revision, err := s.revisionAt(ctx, name, c.String("at"))
+		if err != nil {
+			return err
+		}
+		ctx = WithRevision(ctx, revision)
+	}
+
 	if err := s.show(ctx, c, name, true); err != nil {
 		return exit.Error(exit.Decrypt, err, "%s", err)
 	}
@@ -114,7 +133,7 @@ func (s *Action) showHandleRevision(ctx context.Context, c *cli.Context, name, r
 		return s.showHandleError(ctx, c, name, false, err)
 	}
 
-	return s.showHandleOutput(ctx, name, sec)
+	return s.showHandleOutput(WithRevision(ctx, revision), name, sec)
 }
 
 func (s *Action) parseRevision(ctx context.Context, name, revision string) (string, error) {
@@ -147,8 +166,41 @@ func (s *Action) parseRevision(ctx context.Context, name, revision string) (stri
 	return revision, nil
 }
 
+// revisionAt returns the revision of a secret that was current at the
+// given date. A date without a time means the end of that day.
+func (s *Action) revisionAt(ctx context.Context, name, date string) (string, error) {
+	at, err := audit.ParseDate(date)
+	if err != nil {
+		return "", exit.Error(exit.Usage, err, "Invalid date: %s", err)
+	}
+
+	if _, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(date), time.Local); err == nil {
+		at = at.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	revs, err := s.Store.ListRevisions(ctx, name)
+	if err != nil {
+		return "", exit.Error(exit.Unknown, err, "Failed to get revisions: %s", err)
+	}
+
+	sort.Sort(backend.Revisions(revs))
+	for _, rev := range revs {
+		if !rev.Date.After(at) {
+			debug.Log("Found %s from %s for %s", rev.Hash, rev.Date, at)
+
+			return rev.Hash, nil
+		}
+	}
+
+	return "", exit.Error(exit.NotFound, nil, "%s did not exist at %s", name, at.Format(time.RFC3339))
+}
+
 // showHandleOutput displays a secret.
 func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.Secret) error {
+	if ctxutil.IsJSON(ctx) {
+		return s.showPrintJSON(ctx, name, sec)
+	}
+
 	pw, body, err := s.showGetContent(ctx, sec)
 	if err != nil {
 		return err
@@ -178,7 +230,7 @@ func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.S
 	}
 
 	if IsClip(ctx) && pw != "" {
-		if err := clipboard.CopyTo(ctx, name, []byte(pw), s.cfg.ClipTimeout); err != nil {
+		if err := clipboard.CopyTo(ctx, name, []byte(pw), s.configFor(ctx, name).ClipTimeout); err != nil {
 			return err
 		}
 	}
@@ -241,7 +293,7 @@ func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string,
 
 // showHandleError handles errors retrieving secrets.
 func (s *Action) showHandleError(ctx context.Context, c *cli.Context, name string, recurse bool, err error) error {
-	if !errors.Is(err, store.ErrNotFound) || !recurse || !ctxutil.IsTerminal(ctx) {
+	if !errors.Is(err, store.ErrNotFound) || !recurse || !ctxutil.IsTerminal(ctx) || ctxutil.IsJSON(ctx) {
 		return exit.Error(exit.Unknown, err, "failed to retrieve secret %q: %s", name, err)
 	}
 
@@ -254,6 +306,41 @@ func (s *Action) showHandleError(ctx context.Context, c *cli.Context, name strin
 	return nil
 }
 
+// showPrintJSON prints the secret as a JSON document. Clipboard and QR code
+// options are ignored.
+func (s *Action) showPrintJSON(ctx context.Context, name string, sec gopass.Secret) error {
+	doc := secretJSON{
+		Name: name,
+	}
+	if HasRevision(ctx) {
+		doc.Revision = GetRevision(ctx)
+	}
+
+	switch {
+	case HasKey(ctx):
+		key := GetKey(ctx)
+		values, found := sec.Values(key)
+		if !found {
+			return exit.Error(exit.NotFound, store.ErrNoKey, store.ErrNoKey.Error())
+		}
+		doc.Key = key
+		doc.Values = values
+	case IsPasswordOnly(ctx):
+		doc.Password = sec.Password()
+	default:
+		doc.Password = sec.Password()
+		doc.Body = sec.Body()
+		if keys := sec.Keys(); len(keys) > 0 {
+			doc.Keys = make(map[string][]string, len(keys))
+			for _, k := range keys {
+				doc.Keys[k], _ = sec.Values(k)
+			}
+		}
+	}
+
+	return printJSON(doc)
+}
+
 func (s *Action) showPrintQR(name, pw string) error {
 	qr, err := qrcon.QRCode(pw)
 	if err != nil {
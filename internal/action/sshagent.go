@@ -0,0 +1,76 @@
+package action
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/server"
+	"github.com/kpitt/gopass/internal/sshagent"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/api"
+	"github.com/kpitt/gopass/pkg/termio"
+	"github.com/urfave/cli/v2"
+)
+
+// SSHAgent serves the SSH keys stored in gopass with the SSH agent protocol
+// on a Unix domain socket until interrupted.
+func (s *Action) SSHAgent(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	path := c.String("socket")
+	if path == "" {
+		path = sshagent.DefaultSocket()
+	}
+
+	opts := sshagent.Options{
+		Prefix:  c.String("prefix"),
+		Confirm: c.Bool("confirm"),
+	}
+	if lt := c.String("lifetime"); lt != "" {
+		d, err := time.ParseDuration(lt)
+		if err != nil || d < time.Second {
+			return exit.Error(exit.Usage, err, "Invalid lifetime %q", lt)
+		}
+		opts.Lifetime = d
+	}
+
+	// confirmations must not be answered by --yes.
+	cctx := ctxutil.WithAlwaysYes(ctx, false)
+	agent := sshagent.New(func(comment string) bool {
+		return termio.AskForConfirmation(cctx, fmt.Sprintf("Allow use of SSH key %s?", comment))
+	})
+
+	gp, err := api.New(ctx)
+	if err != nil {
+		return exit.Error(exit.Unknown, err, "failed to initialize store: %s", err)
+	}
+	defer func() {
+		_ = gp.Close(ctx)
+	}()
+
+	loaded, err := agent.Load(ctx, gp, opts)
+	if err != nil {
+		return exit.Error(exit.Decrypt, err, "failed to load SSH keys: %s", err)
+	}
+	if len(loaded) < 1 {
+		return exit.Error(exit.NotFound, nil, "No SSH keys found")
+	}
+	for _, name := range loaded {
+		out.OKf(ctx, "Loaded key from %s", name)
+	}
+
+	l, err := server.Listen(path)
+	if err != nil {
+		return exit.Error(exit.IO, err, "failed to create socket: %s", err)
+	}
+
+	out.Printf(ctx, "Listening on %s. Use it with:", path)
+	out.Printf(ctx, "export SSH_AUTH_SOCK=%s", path)
+	if err := agent.Serve(ctx, l); err != nil {
+		return exit.Error(exit.IO, err, "failed to serve: %s", err)
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/hook"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/store"
 	"github.com/kpitt/gopass/internal/store/leaf"
@@ -36,7 +37,7 @@ func init() {
 
 // Sync all stores with their remotes.
 func (s *Action) Sync(c *cli.Context) error {
-	return s.sync(ctxutil.WithGlobalFlags(c), c.String("store"))
+	return s.sync(ctxutil.WithGlobalFlags(c), c.String("store"), false)
 }
 
 func (s *Action) autoSync(ctx context.Context) error {
@@ -55,13 +56,15 @@ func (s *Action) autoSync(ctx context.Context) error {
 	ls := s.rem.LastSeen("autosync")
 	debug.Log("autosync - last seen: %s", ls)
 	if time.Since(ls) > time.Duration(autosyncIntervalDays)*24*time.Hour {
-		return s.sync(ctx, "")
+		return s.sync(ctx, "", true)
 	}
 
 	return nil
 }
 
-func (s *Action) sync(ctx context.Context, store string) error {
+// sync syncs all stores or only the given one. Automatic syncs skip all
+// mounts that have autosync disabled.
+func (s *Action) sync(ctx context.Context, store string, auto bool) error {
 	mps := s.Store.MountPoints()
 	mps = append([]string{""}, mps...)
 
@@ -76,6 +79,12 @@ func (s *Action) sync(ctx context.Context, store string) error {
 			}
 		}
 
+		if auto && !s.Store.MountConfig(ctx, mp).AutoSync {
+			debug.Log("autosync - skipping %q (disabled)", mp)
+
+			continue
+		}
+
 		_ = s.syncMount(ctx, mp)
 	}
 	out.OKf(ctx, "All done")
@@ -109,9 +118,18 @@ func (s *Action) syncMount(ctx context.Context, mp string) error {
 		return fmt.Errorf("failed to get sub stores (nil)")
 	}
 
+	ctx = s.withMountContext(ctx, mp)
+
 	syncMsg := fmt.Sprintf("Synchronizing %s store", color.CyanString(name))
 	ctx = ctxutil.WithSpinner(ctx, syncMsg)
 
+	ev := &hook.Event{Op: hook.OpSync, Mount: mp}
+	if err := hook.Run(ctx, hook.Pre, ev); err != nil {
+		out.Errorf(ctx, "Skipped %q store: %s", name, err)
+
+		return err
+	}
+
 	err = sub.Storage().Push(ctx, "", "")
 	switch {
 	case err == nil:
@@ -140,6 +158,7 @@ func (s *Action) syncMount(ctx context.Context, mp string) error {
 			return err
 		}
 	}
+	hook.RunPost(ctx, ev)
 	out.OKf(ctx, "%s %s", syncMsg, color.GreenString("[OK]"))
 
 	return nil
@@ -0,0 +1,54 @@
+package action
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kpitt/gopass/internal/action/exit"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+)
+
+// Undelete lists the secrets deleted in the git history or restores one of
+// them.
+func (s *Action) Undelete(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+
+	if name == "" {
+		dels, err := s.Store.Deleted(ctx)
+		if err != nil {
+			return exit.Error(exit.Unknown, err, "Failed to list deleted secrets: %s", err)
+		}
+
+		if len(dels) < 1 {
+			out.Noticef(ctx, "No deleted secrets found")
+
+			return nil
+		}
+
+		for _, d := range dels {
+			out.Printf(ctx, "%s - deleted by %s <%s> - %s - %s\n", d.Name, d.Revision.AuthorName, d.Revision.AuthorEmail, d.Revision.Date.Format(time.RFC3339), d.Revision.Hash)
+		}
+
+		return nil
+	}
+
+	if s.Store.Exists(ctx, name) {
+		return exit.Error(exit.Usage, nil, "Secret %s exists. Use restore to roll it back", name)
+	}
+
+	if err := s.Store.Undelete(ctx, name); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return exit.Error(exit.NotFound, err, "No deleted secret %s found", name)
+		}
+
+		return exit.Error(exit.Unknown, err, "Failed to restore %s: %s", name, err)
+	}
+
+	out.OKf(ctx, "Restored %s", name)
+
+	return nil
+}
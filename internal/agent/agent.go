@@ -0,0 +1,151 @@
+// Package agent implements a session agent that keeps unlocked age
+// identities, and optionally decrypted secrets, in memory.
+//
+// The agent listens on a Unix domain socket that is only accessible by the
+// current user. Every gopass process, including the ones using
+// pkg/gopass/api, asks the agent before prompting for the passphrase of
+// the identities and stores the identities in the agent once they are
+// unlocked. Entries expire after an idle timeout and a maximum lifetime,
+// and all entries are removed when the agent is locked.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kpitt/gopass/internal/cache"
+	"github.com/kpitt/gopass/pkg/appdir"
+)
+
+// These buckets hold the different kinds of entries.
+const (
+	// BucketIdentities holds the decrypted identity files, by path and
+	// modification time.
+	BucketIdentities = "identities"
+	// BucketSecrets holds decrypted secrets, by hash of the ciphertext.
+	BucketSecrets = "secrets"
+)
+
+// maxPurgeInterval is the longest time expired entries are kept in memory.
+const maxPurgeInterval = time.Minute
+
+// ErrDisabled is returned when secrets are stored in an agent that does
+// not cache secrets.
+var ErrDisabled = fmt.Errorf("secret caching is disabled")
+
+// Config controls how long entries are kept.
+type Config struct {
+	// IdleTimeout removes entries that were not used for this duration.
+	IdleTimeout time.Duration
+	// MaxLifetime removes entries this long after they were added, even
+	// if they are in use.
+	MaxLifetime time.Duration
+	// CacheSecrets enables caching of decrypted secrets. Otherwise only
+	// identities are kept.
+	CacheSecrets bool
+}
+
+// Agent keeps the entries in memory.
+type Agent struct {
+	cfg     Config
+	buckets map[string]*cache.InMemTTL[string, string]
+}
+
+// New creates a new agent.
+func New(cfg Config) *Agent {
+	a := &Agent{
+		cfg: cfg,
+		buckets: map[string]*cache.InMemTTL[string, string]{
+			BucketIdentities: cache.NewInMemTTL[string, string](cfg.IdleTimeout, cfg.MaxLifetime),
+		},
+	}
+
+	if cfg.CacheSecrets {
+		a.buckets[BucketSecrets] = cache.NewInMemTTL[string, string](cfg.IdleTimeout, cfg.MaxLifetime)
+	}
+
+	return a
+}
+
+// Lock removes all entries.
+func (a *Agent) Lock() {
+	for _, b := range a.buckets {
+		b.Purge()
+	}
+}
+
+// purgeExpired removes the expired entries of all buckets. Otherwise they
+// would only be removed when a new entry is added to the same bucket.
+func (a *Agent) purgeExpired() {
+	for _, b := range a.buckets {
+		b.PurgeExpired()
+	}
+}
+
+// purgeInterval returns how often expired entries are removed.
+func (a *Agent) purgeInterval() time.Duration {
+	if a.cfg.IdleTimeout <= 0 || a.cfg.IdleTimeout > maxPurgeInterval {
+		return maxPurgeInterval
+	}
+
+	return a.cfg.IdleTimeout
+}
+
+// Serve handles connections on l until ctx is canceled. Expired entries
+// are removed periodically and all entries are removed when it returns.
+func (a *Agent) Serve(ctx context.Context, l net.Listener) error {
+	defer a.Lock()
+
+	go func() {
+		ticker := time.NewTicker(a.purgeInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.purgeExpired()
+			}
+		}
+	}()
+
+	hs := &http.Server{
+		Handler:           a.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = hs.Shutdown(sctx)
+	}()
+
+	if err := hs.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	return nil
+}
+
+// DefaultSocket returns the location of the agent socket. It is placed in
+// the user's runtime dir, if there is one, and in the cache dir otherwise.
+func DefaultSocket() string {
+	if rd := os.Getenv("XDG_RUNTIME_DIR"); rd != "" {
+		return filepath.Join(rd, appdir.Name, "agent.sock")
+	}
+
+	return filepath.Join(appdir.UserCache(), "agent.sock")
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kpitt/gopass/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// start runs an agent on a new socket and returns a client for it.
+func start(t *testing.T, cfg Config) *Client {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := server.Listen(sock)
+	require.NoError(t, err)
+
+	go func() {
+		_ = New(cfg).Serve(ctx, l)
+	}()
+
+	return NewClient(sock)
+}
+
+func TestAgent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := start(t, Config{IdleTimeout: time.Hour, MaxLifetime: time.Hour})
+
+	st, err := c.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, st.CacheSecrets)
+
+	_, found := c.Get(ctx, BucketIdentities, "ids")
+	assert.False(t, found)
+
+	require.NoError(t, c.Set(ctx, BucketIdentities, "ids", "AGE-SECRET-KEY-1"))
+	v, found := c.Get(ctx, BucketIdentities, "ids")
+	assert.True(t, found)
+	assert.Equal(t, "AGE-SECRET-KEY-1", v)
+
+	assert.ErrorIs(t, c.Set(ctx, BucketSecrets, "sum", "plaintext"), ErrDisabled)
+	_, found = c.Get(ctx, BucketSecrets, "sum")
+	assert.False(t, found)
+
+	assert.Error(t, c.Set(ctx, "unknown", "key", "value"))
+
+	require.NoError(t, c.Lock(ctx))
+	_, found = c.Get(ctx, BucketIdentities, "ids")
+	assert.False(t, found, "locked")
+}
+
+func TestCacheSecrets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := start(t, Config{IdleTimeout: time.Hour, MaxLifetime: time.Hour, CacheSecrets: true})
+
+	st, err := c.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, st.CacheSecrets)
+
+	require.NoError(t, c.Set(ctx, BucketSecrets, "sum", "plaintext"))
+	v, found := c.Get(ctx, BucketSecrets, "sum")
+	assert.True(t, found)
+	assert.Equal(t, "plaintext", v)
+}
+
+func TestIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := start(t, Config{IdleTimeout: 50 * time.Millisecond, MaxLifetime: time.Hour})
+
+	require.NoError(t, c.Set(ctx, BucketIdentities, "ids", "AGE-SECRET-KEY-1"))
+	time.Sleep(100 * time.Millisecond)
+
+	_, found := c.Get(ctx, BucketIdentities, "ids")
+	assert.False(t, found, "expired")
+}
+
+func TestPurgeExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := server.Listen(sock)
+	require.NoError(t, err)
+
+	a := New(Config{IdleTimeout: 20 * time.Millisecond, MaxLifetime: time.Hour, CacheSecrets: true})
+	go func() {
+		_ = a.Serve(ctx, l)
+	}()
+
+	c := NewClient(sock)
+	require.NoError(t, c.Set(ctx, BucketIdentities, "ids", "AGE-SECRET-KEY-1"))
+	require.NoError(t, c.Set(ctx, BucketSecrets, "sum", "plaintext"))
+
+	// the entries are removed without any further request.
+	assert.Eventually(t, func() bool {
+		return a.buckets[BucketIdentities].Len() == 0 && a.buckets[BucketSecrets].Len() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNotRunning(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, c := range []*Client{nil, NewClient(filepath.Join(t.TempDir(), "missing.sock"))} {
+		_, err := c.Status(ctx)
+		assert.ErrorIs(t, err, ErrNotRunning)
+
+		_, found := c.Get(ctx, BucketIdentities, "ids")
+		assert.False(t, found)
+
+		assert.ErrorIs(t, c.Set(ctx, BucketIdentities, "ids", "value"), ErrNotRunning)
+		assert.ErrorIs(t, c.Lock(ctx), ErrNotRunning)
+	}
+}
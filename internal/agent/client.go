@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kpitt/gopass/pkg/debug"
+)
+
+// ErrNotRunning is returned if there is no agent listening on the socket.
+var ErrNotRunning = fmt.Errorf("agent is not running")
+
+// Client talks to a running agent. A nil client behaves like a client
+// without a running agent.
+type Client struct {
+	socket string
+	hc     *http.Client
+}
+
+// NewClient creates a client for the agent listening on socket.
+func NewClient(socket string) *Client {
+	return &Client{
+		socket: socket,
+		hc: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					d := net.Dialer{Timeout: time.Second}
+
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the status of the agent.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var st Status
+	if err := c.do(ctx, http.MethodGet, "/v1/status", nil, &st); err != nil {
+		return nil, err
+	}
+
+	return &st, nil
+}
+
+// Get returns an entry. It returns false if the entry does not exist or
+// the agent is not running.
+func (c *Client) Get(ctx context.Context, bucket, key string) (string, bool) {
+	var e Entry
+	if err := c.do(ctx, http.MethodPost, "/v1/get", Entry{Bucket: bucket, Key: key}, &e); err != nil {
+		debug.Log("failed to get %s entry from agent: %s", bucket, err)
+
+		return "", false
+	}
+
+	return e.Value, true
+}
+
+// Set adds or replaces an entry.
+func (c *Client) Set(ctx context.Context, bucket, key, value string) error {
+	return c.do(ctx, http.MethodPost, "/v1/set", Entry{Bucket: bucket, Key: key, Value: value}, nil)
+}
+
+// Lock removes all entries from the agent.
+func (c *Client) Lock(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/v1/lock", nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, req, resp any) error {
+	if c == nil {
+		return ErrNotRunning
+	}
+
+	// avoid the dial timeout if there is no agent at all.
+	if fi, err := os.Stat(c.socket); err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return ErrNotRunning
+	}
+
+	var body io.Reader
+	if req != nil {
+		buf, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	hr, err := http.NewRequestWithContext(ctx, method, "http://agent"+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := c.hc.Do(hr)
+	if err != nil {
+		debug.Log("failed to connect to agent at %s: %s", c.socket, err)
+
+		return ErrNotRunning
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode >= 300 {
+		var e Error
+		if err := json.NewDecoder(res.Body).Decode(&e); err != nil || e.Error == "" {
+			return fmt.Errorf("agent returned %s", res.Status)
+		}
+
+		if e.Error == ErrDisabled.Error() {
+			return ErrDisabled
+		}
+
+		return fmt.Errorf("agent returned %s: %s", res.Status, e.Error)
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
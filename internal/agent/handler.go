@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kpitt/gopass/pkg/debug"
+)
+
+// maxBodySize limits the size of request bodies.
+const maxBodySize = 1 << 20
+
+// The types below define the request and response documents.
+
+// Entry is a single cache entry.
+type Entry struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+}
+
+// Status describes the agent.
+type Status struct {
+	CacheSecrets bool `json:"cache_secrets"`
+}
+
+// Error is returned for all failed requests.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Handler returns the HTTP handler.
+//
+//	GET  /v1/status  get the agent status
+//	POST /v1/get     get an entry
+//	POST /v1/set     add or replace an entry
+//	POST /v1/lock    remove all entries
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/status", a.handleStatus)
+	mux.HandleFunc("/v1/get", a.handleGet)
+	mux.HandleFunc("/v1/set", a.handleSet)
+	mux.HandleFunc("/v1/lock", a.handleLock)
+
+	return mux
+}
+
+func (a *Agent) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Status{CacheSecrets: a.cfg.CacheSecrets})
+}
+
+func (a *Agent) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var e Entry
+	if !readJSON(w, r, &e) {
+		return
+	}
+
+	b, found := a.buckets[e.Bucket]
+	if !found {
+		writeError(w, http.StatusNotFound, "entry not found")
+
+		return
+	}
+
+	v, found := b.Get(e.Key)
+	if !found {
+		writeError(w, http.StatusNotFound, "entry not found")
+
+		return
+	}
+
+	e.Value = v
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (a *Agent) handleSet(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var e Entry
+	if !readJSON(w, r, &e) {
+		return
+	}
+
+	b, found := a.buckets[e.Bucket]
+	if !found {
+		if e.Bucket == BucketSecrets {
+			writeError(w, http.StatusForbidden, ErrDisabled.Error())
+
+			return
+		}
+
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown bucket %q", e.Bucket))
+
+		return
+	}
+
+	debug.Log("caching %s entry", e.Bucket)
+	b.Set(e.Key, e.Value)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *Agent) handleLock(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	debug.Log("locking agent")
+	a.Lock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func checkMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+
+		return false
+	}
+
+	return true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %s", err))
+
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		debug.Log("failed to write response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, Error{Error: msg})
+}
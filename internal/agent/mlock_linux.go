@@ -0,0 +1,19 @@
+//go:build linux
+// +build linux
+
+package agent
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// LockMemory prevents the memory of the process from being swapped to disk.
+func LockMemory() error {
+	if err := unix.Mlockall(unix.MCL_CURRENT | unix.MCL_FUTURE); err != nil {
+		return fmt.Errorf("failed to lock memory: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package agent
+
+import "fmt"
+
+// LockMemory is not supported on this platform.
+func LockMemory() error {
+	return fmt.Errorf("memory locking is not supported on this platform")
+}
@@ -7,9 +7,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/kpitt/gopass/internal/backend"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/pkg/ctxutil"
@@ -20,15 +20,24 @@ import (
 	"github.com/nbutton23/zxcvbn-go"
 )
 
-// auditedSecret with its name, content a warning message and a pipeline error.
+// auditedSecret with its name, content, findings and a pipeline error.
 type auditedSecret struct {
 	name string
 
 	// the secret's content as a string. Needed for checking for duplicates.
 	content string
 
-	// message to the user about some flaw in the secret.
-	messages []string
+	// flaws found in the secret.
+	findings []Finding
+
+	// zxcvbn score of the password, -1 if it was not rated.
+	score int
+
+	// date of the latest revision, if known.
+	changed time.Time
+
+	// number of times the password was seen in data breaches.
+	breaches uint64
 
 	// real error that something in the pipeline went wrong.
 	err error
@@ -42,11 +51,30 @@ type secretGetter interface {
 
 type validator func(string, gopass.Secret) error
 
+// BreachChecker returns how often a password was seen in data breaches.
+type BreachChecker interface {
+	Lookup(ctx context.Context, password string) (uint64, error)
+}
+
+// Options configure a batch audit.
+type Options struct {
+	// Expiration is the age in days before a password is considered
+	// expired. If set only the expiration is checked.
+	Expiration int
+	// Breaches is used to check all passwords against known data breaches.
+	// Breaches are not checked if it is nil.
+	Breaches BreachChecker
+}
+
 // DefaultExpiration is the default expiration time for secrets.
 var DefaultExpiration = time.Hour * 24 * 365
 
-// Batch runs a password strength audit on multiple secrets. Expiration is in days.
-func Batch(ctx context.Context, secrets []string, secStore secretGetter, expiration int) error {
+// Batch runs a password strength audit on multiple secrets and returns the
+// report.
+func Batch(ctx context.Context, secrets []string, secStore secretGetter, opts Options) *Report {
+	expiration := opts.Expiration
+	breaches := opts.Breaches
+
 	// Secrets that still need auditing.
 	pending := make(chan string, 100)
 
@@ -59,23 +87,6 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter, expirat
 		func(_ string, sec gopass.Secret) error {
 			return cv.Check(sec.Password())
 		},
-		func(name string, sec gopass.Secret) error {
-			ui := make([]string, 0, len(sec.Keys())+1)
-			for _, k := range sec.Keys() {
-				pw, found := sec.Get(k)
-				if !found {
-					continue
-				}
-				ui = append(ui, pw)
-			}
-			ui = append(ui, name)
-			match := zxcvbn.PasswordStrength(sec.Password(), ui)
-			if match.Score < 3 {
-				return fmt.Errorf("weak password (%d / 4)", match.Score)
-			}
-
-			return nil
-		},
 		func(name string, sec gopass.Secret) error {
 			if name == sec.Password() {
 				return fmt.Errorf("password equals name")
@@ -87,6 +98,7 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter, expirat
 	// if expiration is not zero only check for expired secrets
 	if expiration > 0 {
 		validators = nil
+		breaches = nil
 	}
 
 	// It would be nice to parallelize this operation and limit the maxJobs to
@@ -101,7 +113,7 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter, expirat
 	maxJobs := secStore.Concurrency()
 	done := make(chan struct{}, maxJobs)
 	for jobs := 0; jobs < maxJobs; jobs++ {
-		go audit(ctx, secStore, validators, time.Duration(expiration)*24*time.Hour, pending, checked, done)
+		go audit(ctx, secStore, validators, breaches, time.Duration(expiration)*24*time.Hour, pending, checked, done)
 	}
 
 	go func() {
@@ -118,23 +130,17 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter, expirat
 	}()
 
 	duplicates := make(map[string][]string)
-	messages := make(map[string][]string)
-	errors := make(map[string][]string)
+	audited := make([]auditedSecret, 0, len(secrets))
 
 	bar := termio.NewProgressBar("Checking secrets", int64(len(secrets)))
 	bar.Hidden = ctxutil.IsHidden(ctx)
 
 	i := 0
 	for secret := range checked {
-		if secret.err != nil {
-			en := secret.err.Error()
-			errors[en] = append(errors[en], secret.name)
-		} else if secret.content != "" {
+		if secret.err == nil && secret.content != "" {
 			duplicates[secret.content] = append(duplicates[secret.content], secret.name)
 		}
-		for _, m := range secret.messages {
-			messages[m] = append(messages[m], secret.name)
-		}
+		audited = append(audited, secret)
 
 		bar.Inc()
 		i++
@@ -144,16 +150,17 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter, expirat
 	}
 	bar.Done()
 
-	return auditPrintResults(ctx, duplicates, messages, errors)
+	return newReport(audited, duplicates, breaches != nil)
 }
 
-func audit(ctx context.Context, secStore secretGetter, validators []validator, expiry time.Duration, secrets <-chan string, checked chan<- auditedSecret, done chan struct{}) {
+func audit(ctx context.Context, secStore secretGetter, validators []validator, breaches BreachChecker, expiry time.Duration, secrets <-chan string, checked chan<- auditedSecret, done chan struct{}) {
 	if expiry < time.Hour {
 		expiry = DefaultExpiration
 	}
 	for secret := range secrets {
 		as := auditedSecret{
-			name: secret,
+			name:  secret,
+			score: -1,
 		}
 		// check for context cancelation.
 		select {
@@ -167,18 +174,11 @@ func audit(ctx context.Context, secStore secretGetter, validators []validator, e
 
 		debug.Log("Checking %s", secret)
 
-		// handle old passwords
 		revs, err := secStore.ListRevisions(ctx, secret)
 		if err != nil {
-			as.messages = append(as.messages, err.Error())
-		} else if len(revs) > 0 && time.Since(revs[0].Date) > expiry {
-			as.messages = append(as.messages, fmt.Sprintf("Password too old (%dd)", int(expiry.Hours()/24)))
-		}
-
-		if len(validators) < 1 {
-			checked <- as
-
-			continue
+			as.findings = append(as.findings, Finding{Category: CategoryError, Message: err.Error()})
+		} else if len(revs) > 0 {
+			as.changed = revs[0].Date
 		}
 
 		sec, err := secStore.Get(ctx, secret)
@@ -193,6 +193,18 @@ func audit(ctx context.Context, secStore secretGetter, validators []validator, e
 
 			continue
 		}
+
+		// handle expired and old passwords
+		if f, found := expiryFinding(secret, sec, revs, expiry); found {
+			as.findings = append(as.findings, f)
+		}
+
+		if len(validators) < 1 {
+			checked <- as
+
+			continue
+		}
+
 		as.content = sec.Password()
 
 		// do not check empty secrets.
@@ -202,14 +214,22 @@ func audit(ctx context.Context, secStore secretGetter, validators []validator, e
 			continue
 		}
 
-		// handle password validation errors.
-		if errs := allValid(validators, secret, sec); len(errs) > 0 {
-			for _, e := range errs {
-				as.messages = append(as.messages, e.Error())
+		if breaches != nil {
+			n, err := breaches.Lookup(ctx, as.content)
+			if err != nil {
+				as.findings = append(as.findings, Finding{Category: CategoryError, Message: fmt.Sprintf("Breach check failed: %s", err)})
 			}
-			checked <- as
+			as.breaches = n
+		}
 
-			continue
+		as.score = score(secret, sec)
+		if as.score < 3 {
+			as.findings = append(as.findings, Finding{Category: CategoryWeak, Message: fmt.Sprintf("weak password (%d / 4)", as.score)})
+		}
+
+		// handle password validation errors.
+		for _, e := range allValid(validators, secret, sec) {
+			as.findings = append(as.findings, Finding{Category: CategoryWeak, Message: e.Error()})
 		}
 
 		// record every password for possible duplicates
@@ -218,6 +238,22 @@ func audit(ctx context.Context, secStore secretGetter, validators []validator, e
 	done <- struct{}{}
 }
 
+// score rates the password with zxcvbn. All other values of the secret and
+// its name are considered to be known to an attacker.
+func score(name string, sec gopass.Secret) int {
+	ui := make([]string, 0, len(sec.Keys())+1)
+	for _, k := range sec.Keys() {
+		pw, found := sec.Get(k)
+		if !found {
+			continue
+		}
+		ui = append(ui, pw)
+	}
+	ui = append(ui, name)
+
+	return zxcvbn.PasswordStrength(sec.Password(), ui).Score
+}
+
 func allValid(vs []validator, name string, sec gopass.Secret) []error {
 	errs := make([]error, 0, len(vs))
 	for _, v := range vs {
@@ -229,53 +265,66 @@ func allValid(vs []validator, name string, sec gopass.Secret) []error {
 	return errs
 }
 
-func printAuditResults(m map[string][]string, format string, color func(format string, a ...any) string) bool {
-	b := false
+// newReport builds the report from the audited secrets.
+func newReport(audited []auditedSecret, duplicates map[string][]string, breachesChecked bool) *Report {
+	r := &Report{
+		Date:            time.Now(),
+		BreachesChecked: breachesChecked,
+		Secrets:         make([]SecretReport, 0, len(audited)),
+	}
 
-	for msg, secrets := range m {
-		b = true
-		fmt.Fprint(out.Stdout, color(format, msg))
-		for _, secret := range secrets {
-			fmt.Fprint(out.Stdout, color("\t- %s\n", secret))
+	for _, as := range audited {
+		sr := SecretReport{
+			Name:     as.name,
+			Breaches: as.breaches,
+			Findings: as.findings,
 		}
-	}
 
-	return b
-}
+		if as.score >= 0 {
+			sc := as.score
+			sr.Score = &sc
+		}
 
-// Single runs a password strength audit on a single password.
-func Single(ctx context.Context, password string) {
-	validator := crunchy.NewValidator()
-	if err := validator.Check(password); err != nil {
-		out.Printf(ctx, fmt.Sprintf("Warning: %s", err))
-	}
-}
+		if !as.changed.IsZero() {
+			changed := as.changed
+			sr.Changed = &changed
+		}
 
-func auditPrintResults(ctx context.Context, duplicates, messages, errors map[string][]string) error {
-	foundDuplicates := false
-	for _, secrets := range duplicates {
-		if len(secrets) > 1 {
-			foundDuplicates = true
+		if as.err != nil {
+			sr.Error = as.err.Error()
+		}
 
-			out.Printf(ctx, "Detected a shared secret for:")
-			for _, secret := range secrets {
-				out.Printf(ctx, "\t- %s", secret)
+		if as.breaches > 0 {
+			sr.Findings = append(sr.Findings, Finding{Category: CategoryBreached, Message: fmt.Sprintf("found in data breaches (seen %d times)", as.breaches)})
+		}
+
+		if as.err == nil && as.content != "" {
+			for _, other := range duplicates[as.content] {
+				if other != as.name {
+					sr.Duplicates = append(sr.Duplicates, other)
+				}
+			}
+			sort.Strings(sr.Duplicates)
+
+			if len(sr.Duplicates) > 0 {
+				sr.Findings = append(sr.Findings, Finding{Category: CategoryDuplicate, Message: "shared password"})
 			}
 		}
-	}
-	if !foundDuplicates {
-		out.Printf(ctx, "No shared secrets found.")
-	}
 
-	foundWeakPasswords := printAuditResults(messages, "%s:\n", color.CyanString)
-	if !foundWeakPasswords {
-		out.Printf(ctx, "No weak secrets detected.")
+		r.Secrets = append(r.Secrets, sr)
 	}
-	foundErrors := printAuditResults(errors, "%s:\n", color.RedString)
 
-	if foundWeakPasswords || foundDuplicates || foundErrors {
-		return fmt.Errorf("found weak passwords or duplicates")
-	}
+	sort.Slice(r.Secrets, func(i, j int) bool {
+		return r.Secrets[i].Name < r.Secrets[j].Name
+	})
+
+	return r
+}
 
-	return nil
+// Single runs a password strength audit on a single password.
+func Single(ctx context.Context, password string) {
+	validator := crunchy.NewValidator()
+	if err := validator.Check(password); err != nil {
+		out.Printf(ctx, fmt.Sprintf("Warning: %s", err))
+	}
 }
@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/pkg/gopass"
+)
+
+// Keys that hold the expiration metadata of a secret.
+const (
+	// KeyExpires is a fixed expiration date.
+	KeyExpires = "expires"
+	// KeyRotateEvery is the interval after which a secret must be changed.
+	KeyRotateEvery = "rotate-every"
+)
+
+// Sources of an expiration date.
+const (
+	SourceExpires  = "expires"
+	SourceRotate   = "rotate-every"
+	SourceRevision = "revision"
+)
+
+// dateLayouts are the formats accepted by the expires key.
+var dateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+}
+
+// Expiry is the expiration date of a secret.
+type Expiry struct {
+	Name string
+	// Date is the point in time the secret expires. It is zero if neither the
+	// secret nor its history have a date.
+	Date time.Time
+	// Source tells where Date was taken from.
+	Source string
+	// Changed is the date of the latest revision, if known.
+	Changed time.Time
+}
+
+// Known returns true if the expiration date is known.
+func (e Expiry) Known() bool {
+	return !e.Date.IsZero()
+}
+
+// Expired returns true if the secret is expired at now.
+func (e Expiry) Expired(now time.Time) bool {
+	return e.Known() && !e.Date.After(now)
+}
+
+// ExpiresWithin returns true if the secret is expired at now or expires
+// within d.
+func (e Expiry) ExpiresWithin(now time.Time, d time.Duration) bool {
+	return e.Known() && !e.Date.After(now.Add(d))
+}
+
+// GetExpiry determines the expiration date of a secret. An expires key takes
+// precedence over a rotate-every key. Without either the secret expires
+// maxAge after the latest revision. revs must be ordered newest first, as
+// returned by ListRevisions.
+func GetExpiry(name string, sec gopass.Secret, revs []backend.Revision, maxAge time.Duration) (Expiry, error) {
+	e := Expiry{Name: name}
+	if len(revs) > 0 {
+		e.Changed = revs[0].Date
+	}
+
+	if sec != nil {
+		if v, found := sec.Get(KeyExpires); found && v != "" {
+			d, err := ParseDate(v)
+			if err != nil {
+				return e, fmt.Errorf("invalid %s: %w", KeyExpires, err)
+			}
+			e.Date = d
+			e.Source = SourceExpires
+
+			return e, nil
+		}
+
+		if v, found := sec.Get(KeyRotateEvery); found && v != "" {
+			iv, err := ParseInterval(v)
+			if err != nil {
+				return e, fmt.Errorf("invalid %s: %w", KeyRotateEvery, err)
+			}
+			e.Source = SourceRotate
+			if !e.Changed.IsZero() {
+				e.Date = e.Changed.Add(iv)
+			}
+
+			return e, nil
+		}
+	}
+
+	e.Source = SourceRevision
+	if !e.Changed.IsZero() && maxAge > 0 {
+		e.Date = e.Changed.Add(maxAge)
+	}
+
+	return e, nil
+}
+
+// ParseDate parses the value of an expires key. Dates without a time zone
+// are in local time.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a date, use YYYY-MM-DD", s)
+}
+
+// ParseInterval parses the value of a rotate-every key. It is a number
+// followed by a unit: d (days), w (weeks), m (months) or y (years). A number
+// without unit is in days.
+func ParseInterval(in string) (time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(in))
+	if s == "" {
+		return 0, fmt.Errorf("empty interval")
+	}
+
+	unit := 24 * time.Hour
+	switch s[len(s)-1] {
+	case 'd':
+		s = s[:len(s)-1]
+	case 'w':
+		unit *= 7
+		s = s[:len(s)-1]
+	case 'm':
+		unit *= 30
+		s = s[:len(s)-1]
+	case 'y':
+		unit *= 365
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not an interval, use e.g. 90d, 12w, 6m or 1y", in)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// expiryFinding returns the audit finding for an expired secret.
+func expiryFinding(name string, sec gopass.Secret, revs []backend.Revision, maxAge time.Duration) (Finding, bool) {
+	e, err := GetExpiry(name, sec, revs, maxAge)
+	if err != nil {
+		return Finding{Category: CategoryError, Message: err.Error()}, true
+	}
+
+	if !e.Expired(time.Now()) {
+		return Finding{}, false
+	}
+
+	if e.Source == SourceRevision {
+		return Finding{Category: CategoryExpired, Message: fmt.Sprintf("Password too old (%dd)", int(maxAge.Hours()/24))}, true
+	}
+
+	return Finding{Category: CategoryExpired, Message: fmt.Sprintf("Password expired (%s)", e.Source)}, true
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInterval(t *testing.T) {
+	t.Parallel()
+
+	day := 24 * time.Hour
+	for in, want := range map[string]time.Duration{
+		"90":   90 * day,
+		"90d":  90 * day,
+		"12w":  84 * day,
+		"6m":   180 * day,
+		"1Y":   365 * day,
+		" 2w ": 14 * day,
+	} {
+		got, err := ParseInterval(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "d", "-1d", "0", "1h", "foo"} {
+		_, err := ParseInterval(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	d, err := ParseDate("2030-02-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2030, 2, 1, 0, 0, 0, 0, time.Local), d)
+
+	d, err = ParseDate("2030-02-01T10:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2030, 2, 1, 10, 0, 0, 0, time.UTC), d.UTC())
+
+	_, err = ParseDate("01/02/2030")
+	assert.Error(t, err)
+}
+
+func TestGetExpiry(t *testing.T) {
+	t.Parallel()
+
+	changed := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	revs := []backend.Revision{{Date: changed}, {Date: changed.Add(-time.Hour)}}
+	year := 365 * 24 * time.Hour
+
+	mk := func(kvs ...string) *secrets.KV {
+		sec := secrets.NewKV()
+		for i := 0; i < len(kvs); i += 2 {
+			require.NoError(t, sec.Set(kvs[i], kvs[i+1]))
+		}
+
+		return sec
+	}
+
+	t.Run("expires", func(t *testing.T) {
+		t.Parallel()
+
+		e, err := GetExpiry("foo", mk(KeyExpires, "2030-01-01", KeyRotateEvery, "30d"), revs, year)
+		require.NoError(t, err)
+		assert.Equal(t, SourceExpires, e.Source)
+		assert.Equal(t, 2030, e.Date.Year())
+		assert.Equal(t, changed, e.Changed)
+		assert.False(t, e.Expired(time.Date(2029, 12, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, e.ExpiresWithin(time.Date(2029, 12, 1, 0, 0, 0, 0, time.UTC), 60*24*time.Hour))
+	})
+
+	t.Run("rotate-every", func(t *testing.T) {
+		t.Parallel()
+
+		e, err := GetExpiry("foo", mk(KeyRotateEvery, "30d"), revs, year)
+		require.NoError(t, err)
+		assert.Equal(t, SourceRotate, e.Source)
+		assert.Equal(t, changed.Add(30*24*time.Hour), e.Date)
+
+		// without history the date is unknown.
+		e, err = GetExpiry("foo", mk(KeyRotateEvery, "30d"), nil, year)
+		require.NoError(t, err)
+		assert.False(t, e.Known())
+		assert.False(t, e.Expired(time.Now()))
+	})
+
+	t.Run("revision", func(t *testing.T) {
+		t.Parallel()
+
+		e, err := GetExpiry("foo", mk("user", "bar"), revs, year)
+		require.NoError(t, err)
+		assert.Equal(t, SourceRevision, e.Source)
+		assert.Equal(t, changed.Add(year), e.Date)
+		assert.True(t, e.Expired(time.Now()))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := GetExpiry("foo", mk(KeyExpires, "soon"), revs, year)
+		assert.Error(t, err)
+
+		_, err = GetExpiry("foo", mk(KeyRotateEvery, "often"), revs, year)
+		assert.Error(t, err)
+	})
+}
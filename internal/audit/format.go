@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats are the supported report formats.
+var Formats = []string{"json", "csv", "html"}
+
+// Write writes the report in the given format.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return r.writeJSON(w)
+	case "csv":
+		return r.writeCSV(w)
+	case "html":
+		return r.writeHTML(w)
+	default:
+		return fmt.Errorf("unknown report format %q, use one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
+
+// writeCSV writes one row per secret. Lists are joined with "; ".
+func (r *Report) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "score", "changed", "breaches", "duplicates", "findings", "error"}); err != nil {
+		return err
+	}
+
+	for _, s := range r.Secrets {
+		if err := cw.Write([]string{
+			s.Name,
+			s.score(),
+			s.changed(),
+			strconv.FormatUint(s.Breaches, 10),
+			strings.Join(s.Duplicates, "; "),
+			strings.Join(s.messages(), "; "),
+			s.Error,
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func (s SecretReport) score() string {
+	if s.Score == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*s.Score)
+}
+
+func (s SecretReport) changed() string {
+	if s.Changed == nil {
+		return ""
+	}
+
+	return s.Changed.Format(time.RFC3339)
+}
+
+// messages returns all findings as "category: message".
+func (s SecretReport) messages() []string {
+	m := make([]string, 0, len(s.Findings))
+	for _, f := range s.Findings {
+		m = append(m, f.Category+": "+f.Message)
+	}
+
+	return m
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gopass audit report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+tr.flawed td:first-child { color: #b00; font-weight: bold; }
+ul { margin: 0; padding-left: 1.2em; }
+</style>
+</head>
+<body>
+<h1>gopass audit report</h1>
+<p>Generated {{ .Report.Date.Format "2006-01-02 15:04:05 MST" }}. {{ len .Report.Secrets }} secrets audited.</p>
+<h2>Summary</h2>
+<table>
+<tr><th>Category</th><th>Secrets</th></tr>
+{{- range .Summary }}
+<tr><td>{{ .Category }}</td><td>{{ .Count }}</td></tr>
+{{- end }}
+</table>
+<h2>Secrets</h2>
+<table>
+<tr><th>Name</th><th>Score</th><th>Last changed</th><th>Breaches</th><th>Shared with</th><th>Findings</th></tr>
+{{- range .Report.Secrets }}
+<tr{{ if or .Findings .Error }} class="flawed"{{ end }}>
+<td>{{ .Name }}</td>
+<td>{{ if .Score }}{{ .Score }} / 4{{ end }}</td>
+<td>{{ if .Changed }}{{ .Changed.Format "2006-01-02" }}{{ end }}</td>
+<td>{{ if .Breaches }}{{ .Breaches }}{{ end }}</td>
+<td>{{ range .Duplicates }}{{ . }}<br>{{ end }}</td>
+<td><ul>{{ range .Findings }}<li>{{ .Category }}: {{ .Message }}</li>{{ end }}{{ if .Error }}<li>error: {{ .Error }}</li>{{ end }}</ul></td>
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+type categoryCount struct {
+	Category string
+	Count    int
+}
+
+func (r *Report) writeHTML(w io.Writer) error {
+	summary := make([]categoryCount, 0, len(Categories))
+	for _, c := range Categories {
+		summary = append(summary, categoryCount{Category: c, Count: r.Count(c)})
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		Report  *Report
+		Summary []categoryCount
+	}{
+		Report:  r,
+		Summary: summary,
+	})
+}
@@ -0,0 +1,255 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kpitt/gopass/internal/out"
+)
+
+// Categories of findings.
+const (
+	CategoryWeak      = "weak"
+	CategoryDuplicate = "duplicate"
+	CategoryBreached  = "breached"
+	CategoryExpired   = "expired"
+	CategoryError     = "error"
+)
+
+// Categories are all categories of findings.
+var Categories = []string{CategoryWeak, CategoryDuplicate, CategoryBreached, CategoryExpired, CategoryError}
+
+// Report is the result of an audit. It is meant to be archived, so fields
+// must only ever be added, never renamed or removed.
+type Report struct {
+	Date time.Time `json:"date"`
+	// BreachesChecked is true if the passwords were checked against known
+	// data breaches.
+	BreachesChecked bool           `json:"breaches_checked"`
+	Secrets         []SecretReport `json:"secrets"`
+}
+
+// SecretReport is the result of the audit of a single secret.
+type SecretReport struct {
+	Name string `json:"name"`
+	// Score is the zxcvbn score of the password from 0 (weak) to 4 (strong).
+	// It is nil if the password was not rated.
+	Score *int `json:"score,omitempty"`
+	// Changed is the date of the latest revision, if known.
+	Changed    *time.Time `json:"changed,omitempty"`
+	Breaches   uint64     `json:"breaches,omitempty"`
+	Duplicates []string   `json:"duplicates,omitempty"`
+	Findings   []Finding  `json:"findings,omitempty"`
+	// Error is set if the secret could not be audited.
+	Error string `json:"error,omitempty"`
+}
+
+// Finding is a single flaw of a secret.
+type Finding struct {
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+// Policy decides which findings fail an audit.
+type Policy struct {
+	// FailOn are the categories that fail the audit. All categories fail
+	// the audit if it is nil.
+	FailOn []string
+	// MinScore fails the audit if any password has a lower zxcvbn score.
+	// It is disabled if zero.
+	MinScore int
+}
+
+// ParseCategories parses a comma separated list of categories as given on
+// the command line. The special values "all" and "none" select all or no
+// category. An empty list selects all categories.
+func ParseCategories(in string) ([]string, error) {
+	if strings.TrimSpace(in) == "" {
+		return nil, nil
+	}
+
+	cats := make([]string, 0, len(Categories))
+	for _, c := range strings.Split(in, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		switch {
+		case c == "" || c == "none":
+			continue
+		case c == "all":
+			return nil, nil
+		case !isCategory(c):
+			return nil, fmt.Errorf("unknown category %q, use one of %s, all or none", c, strings.Join(Categories, ", "))
+		}
+
+		cats = append(cats, c)
+	}
+
+	return cats, nil
+}
+
+func isCategory(c string) bool {
+	for _, k := range Categories {
+		if k == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Count returns the number of secrets with at least one finding of the
+// given category.
+func (r *Report) Count(category string) int {
+	n := 0
+	for _, s := range r.Secrets {
+		if s.has(category) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s SecretReport) has(category string) bool {
+	if category == CategoryError && s.Error != "" {
+		return true
+	}
+
+	for _, f := range s.Findings {
+		if f.Category == category {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check returns an error if the report violates the policy.
+func (r *Report) Check(p Policy) error {
+	failOn := p.FailOn
+	if failOn == nil {
+		failOn = Categories
+	}
+
+	reasons := make([]string, 0, len(failOn)+1)
+	for _, c := range failOn {
+		if n := r.Count(c); n > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d %s", n, c))
+		}
+	}
+
+	if p.MinScore > 0 {
+		n := 0
+		for _, s := range r.Secrets {
+			if s.Score != nil && *s.Score < p.MinScore {
+				n++
+			}
+		}
+
+		if n > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d with a score below %d", n, p.MinScore))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return fmt.Errorf("audit failed: %s", strings.Join(reasons, ", "))
+	}
+
+	return nil
+}
+
+// PrintResults prints a human readable summary of the report.
+func (r *Report) PrintResults(ctx context.Context) {
+	foundDuplicates := false
+	seen := make(map[string]bool, len(r.Secrets))
+	for _, s := range r.Secrets {
+		if len(s.Duplicates) < 1 || seen[s.Name] {
+			continue
+		}
+
+		foundDuplicates = true
+
+		out.Printf(ctx, "Detected a shared secret for:")
+		group := append([]string{s.Name}, s.Duplicates...)
+		sort.Strings(group)
+		for _, name := range group {
+			seen[name] = true
+			out.Printf(ctx, "\t- %s", name)
+		}
+	}
+	if !foundDuplicates {
+		out.Printf(ctx, "No shared secrets found.")
+	}
+
+	foundWeakPasswords := printGrouped(r.groupFindings(CategoryWeak, CategoryExpired), "%s:\n", color.CyanString)
+	if !foundWeakPasswords {
+		out.Printf(ctx, "No weak secrets detected.")
+	}
+
+	foundBreached := r.printBreaches()
+	if !foundBreached && r.BreachesChecked {
+		out.Printf(ctx, "No breached secrets found.")
+	}
+
+	errs := r.groupFindings(CategoryError)
+	for _, s := range r.Secrets {
+		if s.Error != "" {
+			errs[s.Error] = append(errs[s.Error], s.Name)
+		}
+	}
+	printGrouped(errs, "%s:\n", color.RedString)
+}
+
+// groupFindings returns the names of all secrets by the message of their
+// findings of the given categories.
+func (r *Report) groupFindings(categories ...string) map[string][]string {
+	m := make(map[string][]string)
+	for _, s := range r.Secrets {
+		for _, f := range s.Findings {
+			for _, c := range categories {
+				if f.Category == c {
+					m[f.Message] = append(m[f.Message], s.Name)
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+func printGrouped(m map[string][]string, format string, color func(format string, a ...any) string) bool {
+	msgs := make([]string, 0, len(m))
+	for msg := range m {
+		msgs = append(msgs, msg)
+	}
+	sort.Strings(msgs)
+
+	for _, msg := range msgs {
+		fmt.Fprint(out.Stdout, color(format, msg))
+		for _, secret := range m[msg] {
+			fmt.Fprint(out.Stdout, color("\t- %s\n", secret))
+		}
+	}
+
+	return len(msgs) > 0
+}
+
+func (r *Report) printBreaches() bool {
+	found := false
+	for _, s := range r.Secrets {
+		if s.Breaches < 1 {
+			continue
+		}
+
+		if !found {
+			fmt.Fprint(out.Stdout, color.RedString("Found in data breaches:\n"))
+			found = true
+		}
+		fmt.Fprint(out.Stdout, color.RedString("\t- %s (seen %d times)\n", s.Name, s.Breaches))
+	}
+
+	return found
+}
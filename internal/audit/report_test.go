@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func testReport() *Report {
+	changed := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	return &Report{
+		Date:            time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC),
+		BreachesChecked: true,
+		Secrets: []SecretReport{
+			{
+				Name:       "db/root",
+				Score:      intPtr(1),
+				Changed:    &changed,
+				Duplicates: []string{"web/example.org"},
+				Findings: []Finding{
+					{Category: CategoryWeak, Message: "weak password (1 / 4)"},
+					{Category: CategoryDuplicate, Message: "shared password"},
+				},
+			},
+			{
+				Name:     "web/<script>",
+				Score:    intPtr(4),
+				Breaches: 42,
+				Findings: []Finding{
+					{Category: CategoryBreached, Message: "found in data breaches (seen 42 times)"},
+				},
+			},
+			{
+				Name:       "web/example.org",
+				Score:      intPtr(2),
+				Duplicates: []string{"db/root"},
+				Findings: []Finding{
+					{Category: CategoryDuplicate, Message: "shared password"},
+				},
+			},
+			{
+				Name:  "web/broken",
+				Error: "decryption failed",
+			},
+		},
+	}
+}
+
+func TestParseCategories(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "all", want: nil},
+		{in: "none", want: []string{}},
+		{in: "weak, Duplicate,error", want: []string{"weak", "duplicate", "error"}},
+	} {
+		got, err := ParseCategories(tc.in)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+	}
+
+	_, err := ParseCategories("weak,foo")
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	r := testReport()
+	assert.Equal(t, 1, r.Count(CategoryWeak))
+	assert.Equal(t, 2, r.Count(CategoryDuplicate))
+	assert.Equal(t, 1, r.Count(CategoryError))
+	assert.Equal(t, 0, r.Count(CategoryExpired))
+
+	for _, tc := range []struct {
+		policy Policy
+		err    string
+	}{
+		{
+			policy: Policy{},
+			err:    "audit failed: 1 weak, 2 duplicate, 1 breached, 1 error",
+		},
+		{
+			policy: Policy{FailOn: []string{}},
+		},
+		{
+			policy: Policy{FailOn: []string{CategoryExpired}, MinScore: 1},
+		},
+		{
+			policy: Policy{FailOn: []string{CategoryDuplicate}, MinScore: 2},
+			err:    "audit failed: 2 duplicate, 1 with a score below 2",
+		},
+	} {
+		err := r.Check(tc.policy)
+		if tc.err == "" {
+			assert.NoError(t, err, tc.policy)
+
+			continue
+		}
+		assert.EqualError(t, err, tc.err, tc.policy)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, testReport().Write(buf, "json"))
+
+	var got Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, testReport().Secrets, got.Secrets)
+	assert.True(t, got.BreachesChecked)
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, testReport().Write(buf, "csv"))
+
+	rows, err := csv.NewReader(buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"name", "score", "changed", "breaches", "duplicates", "findings", "error"},
+		{"db/root", "1", "2022-03-04T05:06:07Z", "0", "web/example.org", "weak: weak password (1 / 4); duplicate: shared password", ""},
+		{"web/<script>", "4", "", "42", "", "breached: found in data breaches (seen 42 times)", ""},
+		{"web/example.org", "2", "", "0", "db/root", "duplicate: shared password", ""},
+		{"web/broken", "", "", "0", "", "", "decryption failed"},
+	}, rows)
+}
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, testReport().Write(buf, "html"))
+
+	assert.Contains(t, buf.String(), "<td>duplicate</td><td>2</td>")
+	assert.Contains(t, buf.String(), "<td>1 / 4</td>")
+	assert.Contains(t, buf.String(), "<td>2022-03-04</td>")
+	assert.Contains(t, buf.String(), "web/&lt;script&gt;")
+	assert.NotContains(t, buf.String(), "web/<script>")
+
+	assert.Error(t, testReport().Write(buf, "pdf"))
+}
+
+func TestPrintResults(t *testing.T) { //nolint:paralleltest
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	color.NoColor = true
+
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	testReport().PrintResults(context.Background())
+	assert.Equal(t, `Detected a shared secret for:
+	- db/root
+	- web/example.org
+weak password (1 / 4):
+	- db/root
+Found in data breaches:
+	- web/<script> (seen 42 times)
+decryption failed:
+	- web/broken
+`, buf.String())
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/blang/semver/v4"
+	"github.com/kpitt/gopass/internal/agent"
 	"github.com/kpitt/gopass/internal/cache"
 	"github.com/kpitt/gopass/internal/cache/ghssh"
 	"github.com/kpitt/gopass/pkg/appdir"
@@ -27,6 +29,12 @@ type Age struct {
 	ghCache   *ghssh.Cache
 	askPass   *askPass
 	recpCache *cache.OnDisk
+	agent     *agent.Client
+
+	// agentStatus makes sure the agent is only asked once whether it caches
+	// secrets.
+	agentStatus  sync.Once
+	agentSecrets bool
 }
 
 // New creates a new Age backend.
@@ -45,6 +53,7 @@ func New() (*Age, error) {
 		recpCache: rc,
 		identity:  filepath.Join(appdir.UserConfig(), "age", "identities"),
 		askPass:   DefaultAskPass,
+		agent:     agent.NewClient(agent.DefaultSocket()),
 	}, nil
 }
 
@@ -0,0 +1,87 @@
+package age
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/kpitt/gopass/internal/agent"
+	"github.com/kpitt/gopass/pkg/debug"
+)
+
+// agentKey identifies the identities file in the agent. It changes when the
+// file is modified, so the agent never returns outdated identities.
+func (a *Age) agentKey() string {
+	return fmt.Sprintf("%s@%d", a.identity, modTime(a.identity).UnixNano())
+}
+
+// cachedIdentities returns the decrypted identities file from the agent,
+// if it is running and has them.
+func (a *Age) cachedIdentities(ctx context.Context) ([]byte, bool) {
+	buf, found := a.agent.Get(ctx, agent.BucketIdentities, a.agentKey())
+	if !found {
+		return nil, false
+	}
+
+	debug.Log("read identities from the agent")
+
+	return []byte(buf), true
+}
+
+// cacheIdentities stores the decrypted identities file in the agent.
+func (a *Age) cacheIdentities(ctx context.Context, buf []byte) {
+	if err := a.agent.Set(ctx, agent.BucketIdentities, a.agentKey(), string(buf)); err != nil {
+		debug.Log("failed to store identities in the agent: %s", err)
+	}
+}
+
+// secretKey identifies a decrypted secret in the agent by the hash of its
+// ciphertext.
+func secretKey(ciphertext []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(ciphertext))
+}
+
+// agentCachesSecrets returns true if the agent is running and caches
+// secrets. The agent is only asked once, so decrypting many secrets doesn't
+// cost a failing round trip each.
+func (a *Age) agentCachesSecrets(ctx context.Context) bool {
+	a.agentStatus.Do(func() {
+		st, err := a.agent.Status(ctx)
+		if err != nil {
+			debug.Log("agent status: %s", err)
+
+			return
+		}
+		a.agentSecrets = st.CacheSecrets
+	})
+
+	return a.agentSecrets
+}
+
+// cachedSecret returns the plaintext of the ciphertext from the agent, if
+// it is running and caches secrets.
+func (a *Age) cachedSecret(ctx context.Context, ciphertext []byte) ([]byte, bool) {
+	if !a.agentCachesSecrets(ctx) {
+		return nil, false
+	}
+
+	buf, found := a.agent.Get(ctx, agent.BucketSecrets, secretKey(ciphertext))
+	if !found {
+		return nil, false
+	}
+
+	debug.Log("read secret from the agent")
+
+	return []byte(buf), true
+}
+
+// cacheSecret stores the plaintext of the ciphertext in the agent.
+func (a *Age) cacheSecret(ctx context.Context, ciphertext, plaintext []byte) {
+	if !a.agentCachesSecrets(ctx) {
+		return
+	}
+
+	if err := a.agent.Set(ctx, agent.BucketSecrets, secretKey(ciphertext), string(plaintext)); err != nil {
+		debug.Log("failed to store secret in the agent: %s", err)
+	}
+}
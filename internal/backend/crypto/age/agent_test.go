@@ -0,0 +1,98 @@
+package age
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/kpitt/gopass/internal/agent"
+	"github.com/kpitt/gopass/internal/server"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startAgent(t *testing.T, cacheSecrets bool) *agent.Client {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := server.Listen(sock)
+	require.NoError(t, err)
+
+	go func() {
+		_ = agent.New(agent.Config{
+			IdleTimeout:  time.Hour,
+			MaxLifetime:  time.Hour,
+			CacheSecrets: cacheSecrets,
+		}).Serve(ctx, l)
+	}()
+
+	return agent.NewClient(sock)
+}
+
+func TestAgentIdentities(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithPasswordCallback(ctx, func(string, bool) ([]byte, error) {
+		t.Fatal("asked for passphrase")
+
+		return nil, nil
+	})
+
+	idf := filepath.Join(t.TempDir(), "identities")
+	require.NoError(t, os.WriteFile(idf, []byte("encrypted"), 0o600))
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	a := &Age{identity: idf, agent: startAgent(t, false)}
+	require.NoError(t, a.agent.Set(ctx, agent.BucketIdentities, a.agentKey(), id.String()+"\n"))
+
+	ids, err := a.Identities(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Equal(t, id.String(), ids[0].(*age.X25519Identity).String())
+
+	// a modified identities file is read again.
+	mt := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(idf, mt, mt))
+	_, found := a.cachedIdentities(ctx)
+	assert.False(t, found)
+}
+
+func TestAgentDecrypt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a := &Age{identity: filepath.Join(t.TempDir(), "identities"), agent: startAgent(t, true)}
+
+	ciphertext := []byte("ciphertext")
+	a.cacheSecret(ctx, ciphertext, []byte("plaintext"))
+
+	buf, err := a.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(buf))
+}
+
+func TestAgentSecretsDisabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a := &Age{identity: filepath.Join(t.TempDir(), "identities"), agent: startAgent(t, false)}
+	assert.False(t, a.agentCachesSecrets(ctx))
+
+	// the status is not requested again, so a caching agent started later
+	// is not used either.
+	a.agent = startAgent(t, true)
+	ciphertext := []byte("ciphertext")
+	a.cacheSecret(ctx, ciphertext, []byte("plaintext"))
+	_, found := a.agent.Get(ctx, agent.BucketSecrets, secretKey(ciphertext))
+	assert.False(t, found)
+}
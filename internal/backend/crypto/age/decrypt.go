@@ -14,6 +14,10 @@ import (
 
 // Decrypt will attempt to decrypt the given payload.
 func (a *Age) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	if plaintext, found := a.cachedSecret(ctx, ciphertext); found {
+		return plaintext, nil
+	}
+
 	if !ctxutil.HasPasswordCallback(ctx) {
 		debug.Log("no password callback found, redirecting to askPass")
 		ctx = ctxutil.WithPasswordCallback(ctx, func(prompt string, _ bool) ([]byte, error) {
@@ -28,7 +32,13 @@ func (a *Age) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return a.decrypt(ciphertext, ids...)
+	plaintext, err := a.decrypt(ciphertext, ids...)
+	if err != nil {
+		return nil, err
+	}
+	a.cacheSecret(ctx, ciphertext, plaintext)
+
+	return plaintext, nil
 }
 
 func (a *Age) decrypt(ciphertext []byte, ids ...age.Identity) ([]byte, error) {
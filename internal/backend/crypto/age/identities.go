@@ -30,14 +30,19 @@ func (a *Age) Identities(ctx context.Context) ([]age.Identity, error) {
 	}
 
 	debug.Log("reading native identities from %s", a.identity)
-	buf, err := a.decryptFile(ctx, a.identity)
-	if err != nil {
-		debug.Log("failed to decrypt existing identities from %s: %s", a.identity, err)
-		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("failed to decrypt %s: %w", a.identity, err)
-		}
+	buf, found := a.cachedIdentities(ctx)
+	if !found {
+		var err error
+		buf, err = a.decryptFile(ctx, a.identity)
+		if err != nil {
+			debug.Log("failed to decrypt existing identities from %s: %s", a.identity, err)
+			if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", a.identity, err)
+			}
 
-		return nil, nil
+			return nil, nil
+		}
+		a.cacheIdentities(ctx, buf)
 	}
 
 	ids, err := age.ParseIdentities(bytes.NewReader(buf))
@@ -0,0 +1,26 @@
+package backend
+
+// GitAttributes is the content of the .gitattributes file of every git
+// based store. It routes all secrets through the gopass diff and merge
+// drivers of GitConfig.
+const GitAttributes = "*.gpg diff=gopass merge=gopass\n*.age diff=gopass merge=gopass\n"
+
+// GitConfigOption is a single local git config setting.
+type GitConfigOption struct {
+	Key   string
+	Value string
+}
+
+// GitConfig are the local git config settings of every git based store.
+// All git storage backends set them, so they can be used on the same
+// repository.
+var GitConfig = []GitConfigOption{
+	// diff=gpg is still used by older stores.
+	{Key: "diff.gpg.binary", Value: "true"},
+	{Key: "diff.gpg.textconv", Value: "gpg --no-tty --decrypt"},
+	// decrypt secrets for git diff and git log -p.
+	{Key: "diff.gopass.textconv", Value: "gopass git-textconv"},
+	// merge secrets key by key instead of failing on binary files.
+	{Key: "merge.gopass.name", Value: "gopass secret merge driver"},
+	{Key: "merge.gopass.driver", Value: "gopass git-merge-driver %O %A %B %P"},
+}
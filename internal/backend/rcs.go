@@ -20,6 +20,7 @@ type rcs interface {
 
 	Revisions(ctx context.Context, name string) ([]Revision, error)
 	GetRevision(ctx context.Context, name, revision string) ([]byte, error)
+	Deleted(ctx context.Context) ([]Deletion, error)
 
 	Compact(ctx context.Context) error
 }
@@ -34,6 +35,13 @@ type Revision struct {
 	Body        string
 }
 
+// Deletion is a file that was removed by a revision. Its last content is
+// available in the parent of that revision.
+type Deletion struct {
+	Name     string
+	Revision Revision
+}
+
 // Revisions implements the sort interface.
 type Revisions []Revision
 
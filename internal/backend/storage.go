@@ -19,6 +19,8 @@ const (
 	FS StorageBackend = iota
 	// GitFS is a filesystem-backed storage with Git.
 	GitFS
+	// GoGit is a filesystem-backed storage with a pure Go Git implementation.
+	GoGit
 )
 
 func (s StorageBackend) String() string {
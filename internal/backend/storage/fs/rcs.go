@@ -72,6 +72,11 @@ func (s *Store) GetRevision(ctx context.Context, name string, revision string) (
 	return []byte(""), backend.ErrNotSupported
 }
 
+// Deleted is not supported.
+func (s *Store) Deleted(context.Context) ([]backend.Deletion, error) {
+	return nil, backend.ErrNotSupported
+}
+
 // Compact is not implemented.
 func (s *Store) Compact(context.Context) error {
 	return nil
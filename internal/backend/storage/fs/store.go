@@ -58,9 +58,45 @@ func (s *Store) Set(ctx context.Context, name string, value []byte) error {
 			return err
 		}
 	}
-	debug.Log("Writing %s to %s", name, filepath.Join(s.path, name))
+	debug.Log("Writing %s to %s", name, filename)
 
-	return os.WriteFile(filepath.Join(s.path, name), value, 0o644)
+	return writeFileAtomic(filename, value)
+}
+
+// writeFileAtomic writes the file to a temporary file next to it first and
+// renames it afterwards, so an interrupted write never leaves a truncated
+// file behind.
+func writeFileAtomic(filename string, value []byte) error {
+	fh, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := fh.Name()
+
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+
+	if _, err := fh.Write(value); err != nil {
+		_ = fh.Close()
+
+		return err
+	}
+	if err := fh.Chmod(0o644); err != nil {
+		_ = fh.Close()
+
+		return err
+	}
+	if err := fh.Sync(); err != nil {
+		_ = fh.Close()
+
+		return err
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filename)
 }
 
 // Move moves the named entity to the new location.
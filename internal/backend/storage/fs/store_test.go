@@ -39,6 +39,11 @@ func TestSetAndGet(t *testing.T) {
 	_ = s.Set(ctx, filename, otherContent)
 	fileHasContent(filename, otherContent)
 
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(path, "a", "b"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
 	// when folder already exists, with unclean path
 	_ = s.Set(ctx, filepath.Join("a", ".", "b", "..", "other"), initialContent)
 	fileHasContent(filepath.Join("a", "other"), initialContent)
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/kpitt/gopass/internal/backend"
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/store"
 	"github.com/kpitt/gopass/pkg/debug"
@@ -21,16 +22,12 @@ const (
 // that git has. We'd prefer if that wasn't necessary but git has way too many modes of operation
 // and we need it to behave a predicatable as possible.
 func (g *Git) fixConfig(ctx context.Context) error {
-	// setup for proper diffs.
-	if err := g.ConfigSet(ctx, "diff.gpg.binary", "true"); err != nil {
-		out.Errorf(ctx, "Error while initializing git: %s", err)
-	}
-	if err := g.ConfigSet(ctx, "diff.gpg.textconv", "gpg --no-tty --decrypt"); err != nil {
-		out.Errorf(ctx, "Error while initializing git: %s", err)
+	for _, o := range backend.GitConfig {
+		if err := g.ConfigSet(ctx, o.Key, o.Value); err != nil {
+			out.Errorf(ctx, "Error while initializing git: %s", err)
+		}
 	}
 
-	// TODO: should set up for age encryption also, or check the crypto backend
-
 	return nil
 }
 
@@ -57,14 +54,13 @@ func (g *Git) InitConfig(ctx context.Context, userName, userEmail string) error
 		return fmt.Errorf("failed to fix git config: %w", err)
 	}
 
-	// TODO: handle `*.age` files if using `age` crypto backend
-	if err := os.WriteFile(filepath.Join(g.fs.Path(), ".gitattributes"), []byte("*.gpg diff=gpg\n"), fileMode); err != nil {
+	if err := os.WriteFile(filepath.Join(g.fs.Path(), ".gitattributes"), []byte(backend.GitAttributes), fileMode); err != nil {
 		return fmt.Errorf("failed to initialize git: %w", err)
 	}
 	if err := g.Add(ctx, g.fs.Path()+"/.gitattributes"); err != nil {
 		out.Warningf(ctx, "Failed to add .gitattributes to git")
 	}
-	if err := g.Commit(ctx, "Configure git repository for gpg file diff."); err != nil {
+	if err := g.Commit(ctx, "Configure git repository for secret diff and merge."); err != nil {
 		out.Warningf(ctx, "Failed to commit .gitattributes to git")
 	}
 
@@ -43,6 +43,14 @@ func TestGitConfig(t *testing.T) { //nolint:paralleltest
 	assert.NoError(t, err)
 	assert.Equal(t, "Foo Bar", un)
 
+	md, err := git.ConfigGet(ctx, "merge.gopass.driver")
+	assert.NoError(t, err)
+	assert.Equal(t, "gopass git-merge-driver %O %A %B %P", md)
+
+	attrs, err := os.ReadFile(filepath.Join(gitdir, ".gitattributes"))
+	require.NoError(t, err)
+	assert.Contains(t, string(attrs), "*.age diff=gopass merge=gopass")
+
 	assert.NoError(t, git.ConfigSet(ctx, "user.name", "foo"))
 	un, err = git.ConfigGet(ctx, "user.name")
 	assert.NoError(t, err)
@@ -355,39 +355,76 @@ func (g *Git) Revisions(ctx context.Context, name string) ([]backend.Revision, e
 			continue
 		}
 
-		p := strings.Split(rev, "\x1f")
-		if len(p) < 1 {
-			continue
-		}
+		revs = append(revs, parseRevision(strings.Split(rev, "\x1f")))
+	}
 
-		r := backend.Revision{}
-		r.Hash = p[0]
-		if len(p) > 1 {
-			r.AuthorName = p[1]
-		}
+	return revs, nil
+}
 
-		if len(p) > 2 {
-			r.AuthorEmail = p[2]
-		}
+// parseRevision parses the fields printed by git log.
+func parseRevision(p []string) backend.Revision {
+	r := backend.Revision{}
+	r.Hash = p[0]
+	if len(p) > 1 {
+		r.AuthorName = p[1]
+	}
 
-		if len(p) > 3 {
-			if iv, err := strconv.ParseInt(p[3], 10, 64); err == nil {
-				r.Date = time.Unix(iv, 0)
-			}
-		}
+	if len(p) > 2 {
+		r.AuthorEmail = p[2]
+	}
 
-		if len(p) > 4 {
-			r.Subject = p[4]
+	if len(p) > 3 {
+		if iv, err := strconv.ParseInt(p[3], 10, 64); err == nil {
+			r.Date = time.Unix(iv, 0)
 		}
+	}
+
+	if len(p) > 4 {
+		r.Subject = p[4]
+	}
+
+	if len(p) > 5 {
+		r.Body = p[5]
+	}
 
-		if len(p) > 5 {
-			r.Body = p[5]
+	return r
+}
+
+// Deleted lists all files that were removed from the repository, newest
+// first. Renamed files are not included.
+func (g *Git) Deleted(ctx context.Context) ([]backend.Deletion, error) {
+	// -z keeps non-ASCII paths as they are instead of quoting them.
+	args := []string{
+		"log",
+		"--diff-filter=D",
+		"--name-only",
+		"-z",
+		`--format=%x1e%H%x1f%an%x1f%ae%x1f%at%x1f%s`,
+	}
+	stdout, stderr, err := g.captureCmd(ctx, "Deleted", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+
+		return nil, err
+	}
+
+	// every revision is the header followed by the NUL terminated names.
+	var dels []backend.Deletion
+	for _, rev := range strings.Split(string(stdout), "\x1e") {
+		fields := strings.Split(rev, "\x00")
+		if fields[0] == "" {
+			continue
 		}
 
-		revs = append(revs, r)
+		r := parseRevision(strings.Split(fields[0], "\x1f"))
+		for _, name := range fields[1:] {
+			if name = strings.TrimPrefix(name, "\n"); name != "" {
+				dels = append(dels, backend.Deletion{Name: name, Revision: r})
+			}
+		}
 	}
 
-	return revs, nil
+	return dels, nil
 }
 
 // GetRevision will return the content of any revision of the named entity
@@ -82,3 +82,48 @@ func TestGit(t *testing.T) { //nolint:paralleltest
 		assert.Equal(t, "foobar", string(content))
 	})
 }
+
+func TestDeleted(t *testing.T) { //nolint:paralleltest
+	gitdir := t.TempDir()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	git, err := Init(ctx, gitdir, "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+
+	for _, f := range []string{"foo", "sub/bar", "sub/bär", "baz"} {
+		require.NoError(t, git.Set(ctx, f, []byte("content of "+f)))
+	}
+	require.NoError(t, git.Add(ctx, "foo", "sub/bar", "sub/bär", "baz"))
+	require.NoError(t, git.Commit(ctx, "added files"))
+
+	require.NoError(t, git.Delete(ctx, "sub/bar"))
+	require.NoError(t, git.Delete(ctx, "sub/bär"))
+	require.NoError(t, git.Add(ctx, "sub/bar", "sub/bär"))
+	require.NoError(t, git.Commit(ctx, "removed sub/bar"))
+
+	// renamed files are not deleted.
+	require.NoError(t, git.Move(ctx, "baz", "zab", true))
+	require.NoError(t, git.Add(ctx, "baz", "zab"))
+	require.NoError(t, git.Commit(ctx, "moved baz"))
+
+	dels, err := git.Deleted(ctx)
+	require.NoError(t, err)
+	require.Len(t, dels, 2)
+	assert.Equal(t, "sub/bar", dels[0].Name)
+	// non-ASCII names are not quoted.
+	assert.Equal(t, "sub/bär", dels[1].Name)
+	assert.Equal(t, "removed sub/bar", dels[0].Revision.Subject)
+	assert.Equal(t, "Dead Beef", dels[0].Revision.AuthorName)
+
+	content, err := git.GetRevision(ctx, dels[0].Name, dels[0].Revision.Hash+"^")
+	require.NoError(t, err)
+	assert.Equal(t, "content of sub/bar", string(content))
+}
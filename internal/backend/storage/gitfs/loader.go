@@ -3,6 +3,7 @@ package gitfs
 import (
 	"context"
 	"fmt"
+	"os/exec"
 	"path/filepath"
 
 	"github.com/kpitt/gopass/internal/backend"
@@ -44,6 +45,12 @@ func (l loader) Handles(ctx context.Context, path string) error {
 		return fmt.Errorf("no .git")
 	}
 
+	// without a git binary we can't do anything useful with this repo. Let
+	// the detection fall through to the next (pure Go) backend.
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git not found: %w", err)
+	}
+
 	return nil
 }
 
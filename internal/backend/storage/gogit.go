@@ -0,0 +1,3 @@
+package storage
+
+import _ "github.com/kpitt/gopass/internal/backend/storage/gogit" // register gogit backend
@@ -0,0 +1,173 @@
+package gogit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store"
+)
+
+const (
+	fileMode = 0o600
+)
+
+// fixConfig sets up the git config for the password store. It uses the
+// same settings as gitfs, so both backends can be used on the same
+// repository.
+func (g *Git) fixConfig(ctx context.Context) error {
+	for _, o := range backend.GitConfig {
+		if err := g.ConfigSet(ctx, o.Key, o.Value); err != nil {
+			out.Errorf(ctx, "Error while initializing git: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// InitConfig initialized and preparse the git config.
+func (g *Git) InitConfig(ctx context.Context, userName, userEmail string) error {
+	// set commit identity.
+	if userName != "" {
+		if err := g.ConfigSet(ctx, "user.name", userName); err != nil {
+			return fmt.Errorf("failed to set git config user.name: %w", err)
+		}
+	} else {
+		out.Printf(ctx, "Git Username not set")
+	}
+	if userEmail != "" && strings.Contains(userEmail, "@") {
+		if err := g.ConfigSet(ctx, "user.email", userEmail); err != nil {
+			return fmt.Errorf("failed to set git config user.email: %w", err)
+		}
+	} else {
+		out.Printf(ctx, "Git Email not set")
+	}
+
+	// ensure sane git config.
+	if err := g.fixConfig(ctx); err != nil {
+		return fmt.Errorf("failed to fix git config: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(g.fs.Path(), ".gitattributes"), []byte(backend.GitAttributes), fileMode); err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+	if err := g.Add(ctx, g.fs.Path()+"/.gitattributes"); err != nil {
+		out.Warningf(ctx, "Failed to add .gitattributes to git")
+	}
+	if err := g.Commit(ctx, "Configure git repository for secret diff and merge."); err != nil {
+		out.Warningf(ctx, "Failed to commit .gitattributes to git")
+	}
+
+	return nil
+}
+
+// splitKey splits a git config key (e.g. diff.gpg.binary) into section,
+// subsection and option.
+func splitKey(key string) (string, string, string, error) {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first < 1 || last == len(key)-1 {
+		return "", "", "", fmt.Errorf("invalid config key %q", key)
+	}
+
+	if first == last {
+		return key[:first], "", key[last+1:], nil
+	}
+
+	return key[:first], key[first+1 : last], key[last+1:], nil
+}
+
+// ConfigSet sets a local config value.
+func (g *Git) ConfigSet(ctx context.Context, key, value string) error {
+	section, subsection, option, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return err
+	}
+
+	if subsection == "" {
+		cfg.Raw.Section(section).SetOption(option, value)
+	} else {
+		cfg.Raw.Section(section).Subsection(subsection).SetOption(option, value)
+	}
+
+	// the typed fields take precedence over Raw when marshalling.
+	switch key {
+	case "user.name":
+		cfg.User.Name = value
+	case "user.email":
+		cfg.User.Email = value
+	}
+
+	return g.repo.SetConfig(cfg)
+}
+
+// ConfigGet returns a given config value.
+func (g *Git) ConfigGet(ctx context.Context, key string) (string, error) {
+	if !g.IsInitialized() {
+		return "", store.ErrGitNotInit
+	}
+
+	section, subsection, option, err := splitKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return "", err
+	}
+
+	if !cfg.Raw.HasSection(section) {
+		return "", fmt.Errorf("config key %q not found", key)
+	}
+	s := cfg.Raw.Section(section)
+
+	if subsection == "" {
+		if !s.HasOption(option) {
+			return "", fmt.Errorf("config key %q not found", key)
+		}
+
+		return s.Option(option), nil
+	}
+
+	if !s.HasSubsection(subsection) || !s.Subsection(subsection).HasOption(option) {
+		return "", fmt.Errorf("config key %q not found", key)
+	}
+
+	return s.Subsection(subsection).Option(option), nil
+}
+
+// ConfigList returns all local git config settings.
+func (g *Git) ConfigList(ctx context.Context) (map[string]string, error) {
+	if !g.IsInitialized() {
+		return nil, store.ErrGitNotInit
+	}
+
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	kv := make(map[string]string, 16)
+	for _, s := range cfg.Raw.Sections {
+		for _, o := range s.Options {
+			kv[strings.ToLower(s.Name+"."+o.Key)] = o.Value
+		}
+		for _, ss := range s.Subsections {
+			for _, o := range ss.Options {
+				kv[strings.ToLower(s.Name)+"."+ss.Name+"."+strings.ToLower(o.Key)] = o.Value
+			}
+		}
+	}
+
+	return kv, nil
+}
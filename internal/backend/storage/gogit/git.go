@@ -0,0 +1,589 @@
+// Package gogit implements a pure Go git based RCS backend. It doesn't
+// require a git binary and works on any repository created by gitfs.
+package gogit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/backend/storage/fs"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/fsutil"
+	"github.com/kpitt/gopass/pkg/termio"
+)
+
+// Git is a pure Go git backend.
+type Git struct {
+	fs   *fs.Store
+	repo *git.Repository
+}
+
+// New opens an existing git repository.
+func New(path string) (*Git, error) {
+	if !fsutil.IsDir(filepath.Join(path, ".git")) {
+		return nil, fmt.Errorf("git repo does not exist")
+	}
+
+	g := &Git{
+		fs: fs.New(path),
+	}
+
+	repo, err := git.PlainOpen(g.fs.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repo at %q: %w", path, err)
+	}
+	g.repo = repo
+
+	return g, nil
+}
+
+// Clone clones an existing git repo and returns a new go-git based backend
+// configured for this clone repo.
+func Clone(ctx context.Context, repo, path, userName, userEmail string) (*Git, error) {
+	debug.Log("Cloning %q into %q", repo, path)
+	r, err := git.PlainCloneContext(ctx, path, false, &git.CloneOptions{
+		URL: repo,
+	})
+	if err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return nil, fmt.Errorf("failed to clone %q: %w", repo, err)
+	}
+
+	g, err := New(path)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		g.repo = r
+	}
+
+	// initialize the local git config.
+	if err := g.InitConfig(ctx, userName, userEmail); err != nil {
+		return g, fmt.Errorf("failed to configure git: %w", err)
+	}
+	out.Printf(ctx, "git configured at %s", g.fs.Path())
+
+	return g, nil
+}
+
+// Init initializes this store's git repo.
+func Init(ctx context.Context, path, userName, userEmail string) (*Git, error) {
+	g := &Git{
+		fs: fs.New(path),
+	}
+
+	// the git repo may be empty (i.e. no branches, cloned from a fresh remote)
+	// or already initialized. Only run git init if the folder is completely empty.
+	if !g.IsInitialized() {
+		if _, err := git.PlainInit(g.fs.Path(), false); err != nil {
+			return nil, fmt.Errorf("failed to initialize git: %w", err)
+		}
+		out.Printf(ctx, "git initialized at %s", g.fs.Path())
+	}
+
+	repo, err := git.PlainOpen(g.fs.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repo at %q: %w", path, err)
+	}
+	g.repo = repo
+
+	if !ctxutil.IsGitInit(ctx) {
+		return g, nil
+	}
+
+	// initialize the local git config.
+	if err := g.InitConfig(ctx, userName, userEmail); err != nil {
+		return g, fmt.Errorf("failed to configure git: %w", err)
+	}
+	out.Printf(ctx, "git configured at %s", g.fs.Path())
+
+	// add current content of the store.
+	if err := g.Add(ctx, g.fs.Path()); err != nil {
+		return g, fmt.Errorf("failed to add %q to git: %w", g.fs.Path(), err)
+	}
+
+	// commit if there is something to commit.
+	if !g.HasStagedChanges(ctx) {
+		debug.Log("No staged changes")
+
+		return g, nil
+	}
+
+	if err := g.Commit(ctx, "Add current content of password store"); err != nil {
+		return g, fmt.Errorf("failed to commit changes to git: %w", err)
+	}
+
+	return g, nil
+}
+
+// Name returns gogit.
+func (g *Git) Name() string {
+	return name
+}
+
+// Version returns the version of the go-git library.
+func (g *Git) Version(context.Context) semver.Version {
+	return debug.ModuleVersion("github.com/go-git/go-git/v5")
+}
+
+// IsInitialized returns true if this stores has an (probably) initialized .git folder.
+func (g *Git) IsInitialized() bool {
+	return fsutil.IsFile(filepath.Join(g.fs.Path(), ".git", "config"))
+}
+
+// Add adds the listed files to the git index. Files or directories that have
+// been removed from the worktree are removed from the index.
+func (g *Git) Add(ctx context.Context, files ...string) error {
+	if !g.IsInitialized() {
+		return store.ErrGitNotInit
+	}
+
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+
+	// emulate git add --all --force with the given pathspecs. go-git can't
+	// add a removed directory, so we match every changed path against the
+	// pathspecs instead.
+	specs := make([]string, 0, len(files))
+	for _, f := range files {
+		if f == g.fs.Path() || f == "." {
+			f = ""
+		}
+		f = strings.TrimPrefix(f, g.fs.Path()+string(filepath.Separator))
+		f = strings.Trim(filepath.ToSlash(f), "/")
+		specs = append(specs, f)
+	}
+	if len(specs) < 1 {
+		specs = append(specs, "")
+	}
+
+	for _, fn := range sortedKeys(status) {
+		if status[fn].Worktree == git.Unmodified {
+			continue
+		}
+		if !matchesAny(fn, specs) {
+			continue
+		}
+		debug.Log("adding %q to index", fn)
+		if _, err := wt.Add(fn); err != nil {
+			return fmt.Errorf("failed to add %q: %w", fn, err)
+		}
+	}
+
+	return nil
+}
+
+func matchesAny(fn string, specs []string) bool {
+	for _, spec := range specs {
+		if spec == "" || fn == spec || strings.HasPrefix(fn, spec+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(status git.Status) []string {
+	keys := make([]string, 0, len(status))
+	for k := range status {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// HasStagedChanges returns true if there are any staged changes which can be committed.
+func (g *Git) HasStagedChanges(ctx context.Context) bool {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return false
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		debug.Log("failed to get status: %s", err)
+
+		return false
+	}
+
+	for _, st := range status {
+		if st.Staging != git.Unmodified && st.Staging != git.Untracked {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListUntrackedFiles lists untracked files.
+func (g *Git) ListUntrackedFiles(ctx context.Context) []string {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return []string{fmt.Sprintf("ERROR: %s", err)}
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return []string{fmt.Sprintf("ERROR: %s", err)}
+	}
+
+	uf := []string{}
+	for _, fn := range sortedKeys(status) {
+		if status.IsUntracked(fn) {
+			uf = append(uf, fn)
+		}
+	}
+
+	return uf
+}
+
+// Commit creates a new git commit with the given commit message.
+func (g *Git) Commit(ctx context.Context, msg string) error {
+	if !g.IsInitialized() {
+		return store.ErrGitNotInit
+	}
+
+	if !g.HasStagedChanges(ctx) {
+		return store.ErrGitNothingToCommit
+	}
+
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	sig, err := g.signature(ctx)
+	if err != nil {
+		return err
+	}
+
+	h, err := wt.Commit(msg, &git.CommitOptions{
+		Author: sig,
+	})
+	if err != nil {
+		return err
+	}
+	debug.Log("committed %s: %s", h, msg)
+
+	return nil
+}
+
+// signature returns the commit identity. The git config (local, global and
+// system) takes precedence over the environment.
+func (g *Git) signature(ctx context.Context) (*object.Signature, error) {
+	sig := &object.Signature{
+		When: time.Now(),
+	}
+
+	if cfg, err := g.repo.ConfigScoped(config.SystemScope); err == nil {
+		sig.Name = cfg.User.Name
+		sig.Email = cfg.User.Email
+	}
+
+	if sig.Name == "" {
+		sig.Name = termio.DetectName(ctx, nil)
+	}
+	if sig.Email == "" {
+		sig.Email = termio.DetectEmail(ctx, nil)
+	}
+
+	if sig.Name == "" || sig.Email == "" {
+		return nil, fmt.Errorf("git author identity unknown. Please set user.name and user.email")
+	}
+
+	return sig, nil
+}
+
+func (g *Git) defaultRemote(ctx context.Context, branch string) string {
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return "origin"
+	}
+
+	b, found := cfg.Branches[branch]
+	if !found || b.Remote == "" {
+		return "origin"
+	}
+
+	if _, found := cfg.Remotes[b.Remote]; found {
+		return b.Remote
+	}
+
+	return "origin"
+}
+
+func (g *Git) defaultBranch(ctx context.Context) string {
+	head, err := g.repo.Head()
+	if err == nil && head.Name().IsBranch() {
+		return head.Name().Short()
+	}
+
+	// an empty repository has no HEAD commit, but HEAD still points to the
+	// initial branch.
+	if ref, err := g.repo.Storer.Reference(plumbing.HEAD); err == nil && ref.Type() == plumbing.SymbolicReference {
+		return ref.Target().Short()
+	}
+
+	// see https://github.com/github/renaming.
+	return "main"
+}
+
+// PushPull pushes the repo to it's origin.
+// optional arguments: remote and branch.
+func (g *Git) PushPull(ctx context.Context, op, remote, branch string) error {
+	if ctxutil.IsNoNetwork(ctx) {
+		debug.Log("Skipping network ops. NoNetwork=true")
+
+		return nil
+	}
+	if !g.IsInitialized() {
+		return store.ErrGitNotInit
+	}
+
+	if branch == "" {
+		branch = g.defaultBranch(ctx)
+	}
+
+	if remote == "" {
+		remote = g.defaultRemote(ctx, branch)
+	}
+
+	if _, err := g.repo.Remote(remote); err != nil {
+		return store.ErrGitNoRemote
+	}
+
+	if err := g.pull(ctx, remote, branch); err != nil {
+		if op == "pull" {
+			return err
+		}
+		out.Warningf(ctx, "Failed to pull before git push: %s", err)
+	}
+
+	if op == "pull" {
+		return nil
+	}
+
+	if uf := g.ListUntrackedFiles(ctx); len(uf) > 0 {
+		out.Warningf(ctx, "Found untracked files: %+v", uf)
+	}
+
+	return g.push(ctx, remote, branch)
+}
+
+func (g *Git) pull(ctx context.Context, remote, branch string) error {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	debug.Log("pulling %s from %s", branch, remote)
+	err = wt.PullContext(ctx, &git.PullOptions{
+		RemoteName:    remote,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+	})
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, git.NoErrAlreadyUpToDate):
+		return nil
+	case errors.Is(err, transport.ErrEmptyRemoteRepository):
+		return nil
+	case errors.Is(err, git.ErrNonFastForwardUpdate):
+		return fmt.Errorf("%w: merging diverged branches is not supported by %s, use gitfs to resolve", err, name)
+	default:
+		return err
+	}
+}
+
+func (g *Git) push(ctx context.Context, remote, branch string) error {
+	ref := plumbing.NewBranchReferenceName(branch)
+	debug.Log("pushing %s to %s", branch, remote)
+
+	err := g.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref + ":" + ref)},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	return nil
+}
+
+// Push pushes to the git remote.
+func (g *Git) Push(ctx context.Context, remote, branch string) error {
+	return g.PushPull(ctx, "push", remote, branch)
+}
+
+// Pull pulls from the git remote.
+func (g *Git) Pull(ctx context.Context, remote, branch string) error {
+	return g.PushPull(ctx, "pull", remote, branch)
+}
+
+// AddRemote adds a new remote.
+func (g *Git) AddRemote(ctx context.Context, remote, url string) error {
+	_, err := g.repo.CreateRemote(&config.RemoteConfig{
+		Name: remote,
+		URLs: []string{url},
+	})
+
+	return err
+}
+
+// RemoveRemote removes a remote.
+func (g *Git) RemoveRemote(ctx context.Context, remote string) error {
+	return g.repo.DeleteRemote(remote)
+}
+
+// Revisions will list all available revisions of the named entity.
+func (g *Git) Revisions(ctx context.Context, name string) ([]backend.Revision, error) {
+	name = filepath.ToSlash(strings.TrimSpace(name))
+	iter, err := g.repo.Log(&git.LogOptions{
+		FileName: &name,
+		Order:    git.LogOrderCommitterTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	revs := make([]backend.Revision, 0, 10)
+	if err := iter.ForEach(func(c *object.Commit) error {
+		subject, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		revs = append(revs, backend.Revision{
+			Hash:        c.Hash.String(),
+			AuthorName:  c.Author.Name,
+			AuthorEmail: c.Author.Email,
+			Date:        c.Author.When,
+			Subject:     strings.TrimSpace(subject),
+			Body:        strings.TrimSpace(body),
+		})
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return revs, nil
+}
+
+// GetRevision will return the content of any revision of the named entity.
+func (g *Git) GetRevision(ctx context.Context, name, revision string) ([]byte, error) {
+	name = filepath.ToSlash(strings.TrimSpace(name))
+	revision = strings.TrimSpace(revision)
+
+	h, err := g.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %q: %w", revision, err)
+	}
+
+	commit, err := g.repo.CommitObject(*h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", h, err)
+	}
+
+	f, err := commit.File(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q at %s: %w", name, h, err)
+	}
+
+	content, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(content), nil
+}
+
+// Deleted lists all files that were removed from the repository, newest
+// first. Renamed files are not included.
+func (g *Git) Deleted(ctx context.Context) ([]backend.Deletion, error) {
+	iter, err := g.repo.Log(&git.LogOptions{
+		Order: git.LogOrderCommitterTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var dels []backend.Deletion
+	if err := iter.ForEach(func(c *object.Commit) error {
+		if c.NumParents() < 1 {
+			return nil
+		}
+
+		parent, err := c.Parent(0)
+		if err != nil {
+			return err
+		}
+
+		from, err := parent.Tree()
+		if err != nil {
+			return err
+		}
+
+		to, err := c.Tree()
+		if err != nil {
+			return err
+		}
+
+		changes, err := object.DiffTreeWithOptions(ctx, from, to, object.DefaultDiffTreeOptions)
+		if err != nil {
+			return err
+		}
+
+		subject, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		for _, ch := range changes {
+			if action, err := ch.Action(); err != nil || action != merkletrie.Delete {
+				continue
+			}
+
+			dels = append(dels, backend.Deletion{
+				Name: ch.From.Name,
+				Revision: backend.Revision{
+					Hash:        c.Hash.String(),
+					AuthorName:  c.Author.Name,
+					AuthorEmail: c.Author.Email,
+					Date:        c.Author.When,
+					Subject:     strings.TrimSpace(subject),
+					Body:        strings.TrimSpace(body),
+				},
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return dels, nil
+}
+
+// Compact will repack the object database.
+func (g *Git) Compact(ctx context.Context) error {
+	return g.repo.RepackObjects(&git.RepackConfig{})
+}
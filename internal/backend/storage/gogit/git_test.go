@@ -0,0 +1,138 @@
+package gogit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGit(t *testing.T) { //nolint:paralleltest
+	td := t.TempDir()
+
+	gitdir := filepath.Join(td, "git")
+	require.NoError(t, os.Mkdir(gitdir, 0o755))
+	gitdir2 := filepath.Join(td, "git2")
+	remote := filepath.Join(td, "remote.git")
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	t.Run("init new repo", func(t *testing.T) { //nolint:paralleltest
+		git, err := Init(ctx, gitdir, "Dead Beef", "dead.beef@example.org")
+		require.NoError(t, err)
+		require.NotNil(t, git)
+		assert.Equal(t, "gogit", git.Name())
+
+		assert.True(t, git.IsInitialized())
+		tf := filepath.Join(gitdir, "some-file")
+		require.NoError(t, os.WriteFile(tf, []byte("foobar"), 0o644))
+		assert.NoError(t, git.Add(ctx, "some-file"))
+		assert.True(t, git.HasStagedChanges(ctx))
+		assert.NoError(t, git.Commit(ctx, "added some-file"))
+		assert.False(t, git.HasStagedChanges(ctx))
+		assert.ErrorIs(t, git.Commit(ctx, "nothing"), store.ErrGitNothingToCommit)
+
+		assert.ErrorIs(t, git.Push(ctx, "origin", "master"), store.ErrGitNoRemote)
+		assert.ErrorIs(t, git.Pull(ctx, "origin", "master"), store.ErrGitNoRemote)
+
+		v, err := git.ConfigGet(ctx, "user.name")
+		require.NoError(t, err)
+		assert.Equal(t, "Dead Beef", v)
+		v, err = git.ConfigGet(ctx, "diff.gpg.binary")
+		require.NoError(t, err)
+		assert.Equal(t, "true", v)
+
+		// the same settings as gitfs.
+		for _, o := range backend.GitConfig {
+			v, err = git.ConfigGet(ctx, o.Key)
+			require.NoError(t, err)
+			assert.Equal(t, o.Value, v, o.Key)
+		}
+		attrs, err := os.ReadFile(filepath.Join(gitdir, ".gitattributes"))
+		require.NoError(t, err)
+		assert.Equal(t, backend.GitAttributes, string(attrs))
+	})
+
+	t.Run("push to file remote", func(t *testing.T) { //nolint:paralleltest
+		_, err := git.PlainInit(remote, true)
+		require.NoError(t, err)
+
+		g, err := New(gitdir)
+		require.NoError(t, err)
+		require.NoError(t, g.AddRemote(ctx, "origin", "file://"+remote))
+		assert.NoError(t, g.Push(ctx, "", ""))
+	})
+
+	t.Run("clone existing repo", func(t *testing.T) { //nolint:paralleltest
+		g, err := Clone(ctx, "file://"+remote, gitdir2, "Other User", "other@example.org")
+		require.NoError(t, err)
+		require.NotNil(t, g)
+
+		content, err := g.Get(ctx, "some-file")
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", string(content))
+
+		require.NoError(t, g.Set(ctx, "some-other-file", []byte("foobaz")))
+		assert.NoError(t, g.Add(ctx, "some-other-file"))
+		assert.NoError(t, g.Commit(ctx, "added some-other-file"))
+		assert.NoError(t, g.Push(ctx, "", ""))
+
+		revs, err := g.Revisions(ctx, "some-other-file")
+		require.NoError(t, err)
+		require.Len(t, revs, 1)
+		assert.Equal(t, "Other User", revs[0].AuthorName)
+		assert.Equal(t, "added some-other-file", revs[0].Subject)
+
+		content, err = g.GetRevision(ctx, "some-other-file", revs[0].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "foobaz", string(content))
+	})
+
+	t.Run("pull and delete", func(t *testing.T) { //nolint:paralleltest
+		g, err := New(gitdir)
+		require.NoError(t, err)
+		require.NoError(t, g.Pull(ctx, "", ""))
+		assert.True(t, g.Exists(ctx, "some-other-file"))
+
+		require.NoError(t, g.Set(ctx, "sub/dir/secret", []byte("one")))
+		require.NoError(t, g.Add(ctx, "sub"))
+		require.NoError(t, g.Commit(ctx, "added sub/dir/secret"))
+
+		require.NoError(t, g.Prune(ctx, "sub"))
+		require.NoError(t, g.Add(ctx, "sub"))
+		require.NoError(t, g.Commit(ctx, "removed sub"))
+
+		_, err = g.GetRevision(ctx, "sub/dir/secret", "HEAD")
+		assert.Error(t, err)
+		content, err := g.GetRevision(ctx, "sub/dir/secret", "HEAD~1")
+		require.NoError(t, err)
+		assert.Equal(t, "one", string(content))
+		assert.Empty(t, g.ListUntrackedFiles(ctx))
+
+		dels, err := g.Deleted(ctx)
+		require.NoError(t, err)
+		require.Len(t, dels, 1)
+		assert.Equal(t, "sub/dir/secret", dels[0].Name)
+		assert.Equal(t, "removed sub", dels[0].Revision.Subject)
+
+		content, err = g.GetRevision(ctx, dels[0].Name, dels[0].Revision.Hash+"^")
+		require.NoError(t, err)
+		assert.Equal(t, "one", string(content))
+	})
+}
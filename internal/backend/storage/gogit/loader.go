@@ -0,0 +1,55 @@
+package gogit
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/pkg/fsutil"
+	"github.com/kpitt/gopass/pkg/termio"
+)
+
+const (
+	name = "gogit"
+)
+
+func init() {
+	backend.StorageRegistry.Register(backend.GoGit, name, &loader{})
+}
+
+type loader struct{}
+
+// New implements backend.StorageLoader.
+func (l loader) New(ctx context.Context, path string) (backend.Storage, error) {
+	return New(path)
+}
+
+// Clone implements backend.StorageLoader.
+func (l loader) Clone(ctx context.Context, repo, path string) (backend.Storage, error) {
+	return Clone(ctx, repo, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
+}
+
+// Init implements backend.StorageLoader.
+func (l loader) Init(ctx context.Context, path string) (backend.Storage, error) {
+	return Init(ctx, path, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
+}
+
+// Handles returns nil if the given path contains a git repository.
+func (l loader) Handles(ctx context.Context, path string) error {
+	if !fsutil.IsDir(filepath.Join(path, ".git")) {
+		return fmt.Errorf("no .git")
+	}
+
+	return nil
+}
+
+// Priority returns the priority of this backend. It must be lower (i.e. a
+// larger number) than gitfs so the git binary is preferred if it is available.
+func (l loader) Priority() int {
+	return 2
+}
+
+func (l loader) String() string {
+	return name
+}
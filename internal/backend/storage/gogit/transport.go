@@ -0,0 +1,31 @@
+package gogit
+
+import (
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/kpitt/gopass/pkg/fsutil"
+)
+
+func init() {
+	// the default file transport shells out to git-upload-pack and
+	// git-receive-pack. Serve local remotes in-process instead so file://
+	// remotes work without a git installation.
+	client.InstallProtocol("file", server.NewServer(fileLoader{}))
+}
+
+// fileLoader loads bare and non-bare repositories from the local filesystem.
+type fileLoader struct{}
+
+// Load implements server.Loader.
+func (fileLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	e := *ep
+	if dotGit := filepath.Join(e.Path, ".git"); fsutil.IsDir(dotGit) {
+		e.Path = dotGit
+	}
+
+	return server.DefaultLoader.Load(&e)
+}
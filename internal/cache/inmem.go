@@ -66,6 +66,14 @@ func (c *InMemTTL[K, V]) Get(key K) (V, bool) {
 	return ce.value, true
 }
 
+// PurgeExpired removes all expired entries.
+func (c *InMemTTL[K, V]) PurgeExpired() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.purgeExpired()
+}
+
 // purgeExpire will remove expired entries. It is called by Set.
 func (c *InMemTTL[K, V]) purgeExpired() {
 	for k, ce := range c.entries {
@@ -110,3 +118,12 @@ func (c *InMemTTL[K, V]) Purge() {
 
 	c.entries = make(map[K]cacheEntry[V], 10)
 }
+
+// Len returns the number of entries, including expired entries that were not
+// purged, yet.
+func (c *InMemTTL[K, V]) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return len(c.entries)
+}
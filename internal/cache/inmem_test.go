@@ -68,6 +68,21 @@ func TestTTL(t *testing.T) {
 	assert.False(t, found)
 }
 
+func TestPurgeExpired(t *testing.T) {
+	t.Parallel()
+
+	c := NewInMemTTL[string, string](10*time.Millisecond, time.Minute)
+	c.Set("foo", "bar")
+	c.PurgeExpired()
+	assert.Equal(t, 1, c.Len())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, c.Len(), "expired entries are kept until purged")
+
+	c.PurgeExpired()
+	assert.Equal(t, 0, c.Len())
+}
+
 func TestPar(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/kpitt/gopass/internal/hook"
 )
 
 var (
@@ -22,9 +24,23 @@ type Config struct {
 	ExportKeys  bool              `yaml:"exportkeys"`  // automatically export public keys of all recipients.
 	NoPager     bool              `yaml:"nopager"`     // do not invoke a pager to display long lists.
 	Parsing     bool              `yaml:"parsing"`     // allows to switch off all output parsing.
+	AutoSync    bool              `yaml:"autosync"`    // periodically sync with the remotes.
+	Generator   string            `yaml:"generator"`   // default password generator.
+	PwLength    int               `yaml:"pwlength"`    // default password length, ask if zero.
 	Path        string            `yaml:"path"`
 	Mounts      map[string]string `yaml:"mounts"`
 
+	// DockerCredentialPrefix is the folder used by the docker credential helper.
+	DockerCredentialPrefix string `yaml:"dockercredentialprefix"`
+	// GitCredentialPrefix is the folder used by the git credential helper.
+	GitCredentialPrefix string `yaml:"gitcredentialprefix"`
+
+	// MountConfig holds the per-mount overrides, keyed by mount point.
+	MountConfig map[string]*StoreConfig `yaml:"mountconfig,omitempty"`
+
+	// Hooks are run before and after secrets are changed.
+	Hooks *hook.Config `yaml:"hooks,omitempty"`
+
 	ConfigPath string `yaml:"-"`
 
 	// Catches all undefined files and must be empty after parsing.
@@ -35,8 +51,10 @@ type Config struct {
 func New() *Config {
 	return &Config{
 		AutoImport:  false,
+		AutoSync:    true,
 		ClipTimeout: 45,
 		ExportKeys:  true,
+		Generator:   "cryptic",
 		Mounts:      make(map[string]string),
 		Parsing:     true,
 		Path:        PwStoreDir(""),
@@ -47,7 +65,21 @@ func New() *Config {
 // CheckOverflow implements configer. It will check for any extra config values not.
 // handled by the current struct.
 func (c *Config) CheckOverflow() error {
-	return checkOverflow(c.XXX)
+	if err := checkOverflow(c.XXX); err != nil {
+		return err
+	}
+
+	if err := checkMountOverflow(c.MountConfig); err != nil {
+		return err
+	}
+
+	if c.Hooks != nil {
+		if err := checkOverflow(c.Hooks.XXX); err != nil {
+			return fmt.Errorf("hooks: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // Config will return a current config.
@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 
+	"github.com/kpitt/gopass/internal/hook"
 	"github.com/kpitt/gopass/pkg/ctxutil"
 )
 
@@ -25,5 +26,9 @@ func (c *Config) WithContext(ctx context.Context) context.Context {
 		ctx = ctxutil.WithShowParsing(ctx, c.Parsing)
 	}
 
+	if c.Hooks != nil && !hook.HasConfig(ctx) {
+		ctx = hook.WithConfig(ctx, c.Hooks)
+	}
+
 	return ctx
 }
@@ -116,8 +116,10 @@ type configer interface {
 func decode(buf []byte, relaxed bool) (*Config, error) {
 	mostRecent := &Config{
 		AutoImport:  true,
+		AutoSync:    true,
 		ClipTimeout: 45,
 		ExportKeys:  true,
+		Generator:   "cryptic",
 		Parsing:     true,
 		Path:        PwStoreDir(""),
 	}
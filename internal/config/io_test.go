@@ -11,6 +11,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/google/go-cmp/cmp"
+	"github.com/kpitt/gopass/internal/hook"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,8 +40,10 @@ mounts:
   work: /home/johndoe/.password-store-work`,
 			want: &Config{
 				AutoImport:  false,
+				AutoSync:    true,
 				ClipTimeout: 45,
 				ExportKeys:  true,
+				Generator:   "cryptic",
 				NoPager:     false,
 				Parsing:     true,
 				Path:        "/home/johndoe/.password-store",
@@ -62,8 +65,10 @@ mounts:
   work: /home/johndoe/.password-store-work`,
 			want: &Config{
 				AutoImport:  false,
+				AutoSync:    true,
 				ClipTimeout: 45,
 				ExportKeys:  true,
+				Generator:   "cryptic",
 				NoPager:     false,
 				Parsing:     true,
 				Path:        "/home/johndoe/.password-store",
@@ -73,6 +78,72 @@ mounts:
 				},
 				XXX: map[string]any{"foo": string("bar")},
 			},
+		}, {
+			name: "mountconfig",
+			cfg: `autoimport: false
+autosync: false
+cliptimeout: 45
+exportkeys: true
+generator: xkcd
+nopager: false
+parsing: true
+pwlength: 32
+path: /home/johndoe/.password-store
+mounts:
+  work: /home/johndoe/.password-store-work
+mountconfig:
+  work:
+    cliptimeout: 10
+    parsing: false`,
+			want: &Config{
+				AutoImport:  false,
+				AutoSync:    false,
+				ClipTimeout: 45,
+				ExportKeys:  true,
+				Generator:   "xkcd",
+				NoPager:     false,
+				Parsing:     true,
+				PwLength:    32,
+				Path:        "/home/johndoe/.password-store",
+				Mounts: map[string]string{
+					"work": "/home/johndoe/.password-store-work",
+				},
+				MountConfig: map[string]*StoreConfig{
+					"work": {
+						ClipTimeout: intPtr(10),
+						Parsing:     boolPtr(false),
+					},
+				},
+			},
+		}, {
+			name: "hooks",
+			cfg: `autoimport: false
+autosync: true
+cliptimeout: 45
+exportkeys: true
+generator: cryptic
+nopager: false
+parsing: true
+path: /home/johndoe/.password-store
+mounts: {}
+hooks:
+  pre-set: check-name {{ .Name }}
+  post-sync: notify
+  changes: true`,
+			want: &Config{
+				AutoSync:    true,
+				ClipTimeout: 45,
+				ExportKeys:  true,
+				Generator:   "cryptic",
+				Parsing:     true,
+				Path:        "/home/johndoe/.password-store",
+				Mounts:      map[string]string{},
+				Hooks: &hook.Config{
+					PreSet:   "check-name {{ .Name }}",
+					PostSync: "notify",
+					Changes:  true,
+				},
+			},
 		},
 	} {
 		tc := tc
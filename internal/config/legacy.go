@@ -23,8 +23,10 @@ type Pre1150 struct {
 func (c *Pre1150) Config() *Config {
 	cfg := &Config{
 		AutoImport:  c.AutoImport,
+		AutoSync:    true,
 		ClipTimeout: c.ClipTimeout,
 		ExportKeys:  c.ExportKeys,
+		Generator:   "cryptic",
 		NoPager:     c.NoPager,
 		Parsing:     c.Parsing,
 		Path:        c.Path,
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/debug"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+)
+
+// StoreConfigFile is the name of the config file inside of a store. It is
+// committed together with the secrets and shared by everyone using the
+// store.
+const StoreConfigFile = ".gopass.yml"
+
+// StoreConfig holds the settings that can be overridden for a single
+// mount. Unset values are inherited from the global config.
+//
+// The effective config of a mount is built in this order, later values
+// win:
+//
+//  1. the global config
+//  2. the config file inside the store (StoreConfigFile)
+//  3. the section of the mount in the global config (mountconfig)
+type StoreConfig struct {
+	AutoSync    *bool   `yaml:"autosync,omitempty"`
+	ClipTimeout *int    `yaml:"cliptimeout,omitempty"`
+	ExportKeys  *bool   `yaml:"exportkeys,omitempty"`
+	Generator   *string `yaml:"generator,omitempty"`
+	Parsing     *bool   `yaml:"parsing,omitempty"`
+	PwLength    *int    `yaml:"pwlength,omitempty"`
+
+	// Catches all undefined files and must be empty after parsing.
+	XXX map[string]any `yaml:",inline"`
+}
+
+// ParseStoreConfig decodes a config file from inside of a store. Unknown
+// keys are ignored so that stores can be shared with newer versions.
+func ParseStoreConfig(buf []byte) (*StoreConfig, error) {
+	sc := &StoreConfig{}
+	if err := yaml.Unmarshal(buf, sc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StoreConfigFile, err)
+	}
+
+	if len(sc.XXX) > 0 {
+		keys := maps.Keys(sc.XXX)
+		sort.Strings(keys)
+		debug.Log("Ignoring unknown keys in %s: %+v", StoreConfigFile, keys)
+		sc.XXX = nil
+	}
+
+	return sc, nil
+}
+
+// checkMountOverflow checks all mount sections for unknown keys.
+func checkMountOverflow(mcs map[string]*StoreConfig) error {
+	for _, mp := range sortedKeys(mcs) {
+		if mcs[mp] == nil {
+			continue
+		}
+
+		if err := checkOverflow(mcs[mp].XXX); err != nil {
+			return fmt.Errorf("mount %q: %w", mp, err)
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(mcs map[string]*StoreConfig) []string {
+	keys := maps.Keys(mcs)
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Map returns the values that are set as strings.
+func (sc *StoreConfig) Map() map[string]string {
+	m := make(map[string]string, 6)
+	if sc == nil {
+		return m
+	}
+
+	o := reflect.ValueOf(sc).Elem()
+	for i := 0; i < o.NumField(); i++ {
+		key := yamlKey(o.Type().Field(i))
+		f := o.Field(i)
+		if key == "" || f.Kind() != reflect.Ptr || f.IsNil() {
+			continue
+		}
+
+		m[key] = fmt.Sprintf("%v", f.Elem().Interface())
+	}
+
+	return m
+}
+
+// Set sets a single value. An empty value unsets the key.
+func (sc *StoreConfig) Set(key, value string) error {
+	o := reflect.ValueOf(sc).Elem()
+	for i := 0; i < o.NumField(); i++ {
+		if yamlKey(o.Type().Field(i)) != key {
+			continue
+		}
+
+		f := o.Field(i)
+		if value == "" {
+			f.Set(reflect.Zero(f.Type()))
+
+			return nil
+		}
+
+		v := reflect.New(f.Type().Elem())
+		switch v.Elem().Kind() { //nolint:exhaustive
+		case reflect.String:
+			v.Elem().SetString(value)
+		case reflect.Bool:
+			bv, err := parseBool(value)
+			if err != nil {
+				return err
+			}
+			v.Elem().SetBool(bv)
+		case reflect.Int:
+			iv, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("failed to convert %q to integer: %w", value, err)
+			}
+			v.Elem().SetInt(int64(iv))
+		}
+		f.Set(v)
+
+		return nil
+	}
+
+	return fmt.Errorf("option %q can not be set per mount", key)
+}
+
+// IsEmpty returns true if no value is set.
+func (sc *StoreConfig) IsEmpty() bool {
+	return len(sc.Map()) < 1
+}
+
+// ForMount returns the effective config of a mount. inStore is the config
+// file from inside of the store, it may be nil.
+func (c *Config) ForMount(mp string, inStore *StoreConfig) *Config {
+	eff := *c
+	eff.apply(inStore)
+	eff.apply(c.MountConfig[mp])
+
+	return &eff
+}
+
+// apply copies all values that are set in sc to c.
+func (c *Config) apply(sc *StoreConfig) {
+	if sc == nil {
+		return
+	}
+
+	if sc.AutoSync != nil {
+		c.AutoSync = *sc.AutoSync
+	}
+	if sc.ClipTimeout != nil {
+		c.ClipTimeout = *sc.ClipTimeout
+	}
+	if sc.ExportKeys != nil {
+		c.ExportKeys = *sc.ExportKeys
+	}
+	if sc.Generator != nil {
+		c.Generator = *sc.Generator
+	}
+	if sc.Parsing != nil {
+		c.Parsing = *sc.Parsing
+	}
+	if sc.PwLength != nil {
+		c.PwLength = *sc.PwLength
+	}
+}
+
+// SetMountConfigValue sets a value in the section of a mount and saves the
+// config.
+func (c *Config) SetMountConfigValue(mp, key, value string) error {
+	if c.MountConfig == nil {
+		c.MountConfig = make(map[string]*StoreConfig, 1)
+	}
+
+	sc := c.MountConfig[mp]
+	if sc == nil {
+		sc = &StoreConfig{}
+	}
+
+	if err := sc.Set(key, strings.ToLower(value)); err != nil {
+		return err
+	}
+
+	c.MountConfig[mp] = sc
+	if sc.IsEmpty() {
+		delete(c.MountConfig, mp)
+	}
+
+	return c.Save()
+}
+
+func yamlKey(f reflect.StructField) string {
+	key := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+
+	return key
+}
+
+func parseBool(value string) (bool, error) {
+	switch value {
+	case "true", "on":
+		return true, nil
+	case "false", "off":
+		return false, nil
+	default:
+		return false, fmt.Errorf("not a bool: %s", value)
+	}
+}
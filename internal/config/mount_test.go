@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestParseStoreConfig(t *testing.T) {
+	t.Parallel()
+
+	sc, err := ParseStoreConfig([]byte("cliptimeout: 10\nautosync: false\nfuture: option\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cliptimeout": "10", "autosync": "false"}, sc.Map())
+
+	_, err = ParseStoreConfig([]byte("cliptimeout: [\n"))
+	assert.Error(t, err)
+}
+
+func TestForMount(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		AutoSync:    true,
+		ClipTimeout: 45,
+		Generator:   "cryptic",
+		Parsing:     true,
+		MountConfig: map[string]*StoreConfig{
+			"work": {ClipTimeout: intPtr(5)},
+		},
+	}
+
+	inStore := &StoreConfig{
+		ClipTimeout: intPtr(10),
+		Parsing:     boolPtr(false),
+		PwLength:    intPtr(32),
+	}
+
+	// the section in the user config wins over the file inside the store.
+	eff := cfg.ForMount("work", inStore)
+	assert.Equal(t, 5, eff.ClipTimeout)
+	assert.False(t, eff.Parsing)
+	assert.Equal(t, 32, eff.PwLength)
+	assert.True(t, eff.AutoSync)
+	assert.Equal(t, "cryptic", eff.Generator)
+
+	eff = cfg.ForMount("other", inStore)
+	assert.Equal(t, 10, eff.ClipTimeout)
+
+	eff = cfg.ForMount("other", nil)
+	assert.Equal(t, 45, eff.ClipTimeout)
+	assert.True(t, eff.Parsing)
+
+	// the global config is not modified.
+	assert.Equal(t, 45, cfg.ClipTimeout)
+}
+
+func TestStoreConfigSet(t *testing.T) {
+	t.Parallel()
+
+	sc := &StoreConfig{}
+	require.NoError(t, sc.Set("cliptimeout", "30"))
+	require.NoError(t, sc.Set("autosync", "off"))
+	require.NoError(t, sc.Set("generator", "xkcd"))
+	assert.Equal(t, map[string]string{"cliptimeout": "30", "autosync": "false", "generator": "xkcd"}, sc.Map())
+
+	require.NoError(t, sc.Set("cliptimeout", ""))
+	assert.Nil(t, sc.ClipTimeout)
+
+	assert.Error(t, sc.Set("cliptimeout", "soon"))
+	assert.Error(t, sc.Set("autosync", "maybe"))
+	assert.Error(t, sc.Set("path", "/tmp"))
+	assert.False(t, sc.IsEmpty())
+}
+
+func TestMountOverflow(t *testing.T) {
+	t.Parallel()
+
+	_, err := decode([]byte("mountconfig:\n  work:\n    foo: bar\n"), false)
+	assert.Error(t, err)
+}
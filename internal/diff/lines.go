@@ -0,0 +1,65 @@
+package diff
+
+// Line operations of a line based diff.
+const (
+	Same    = ' '
+	Removed = '-'
+	Added   = '+'
+)
+
+// Line is a single line of a line based diff.
+type Line struct {
+	Op   rune
+	Text string
+}
+
+// Lines returns a line based diff from l to r. It uses the longest common
+// subsequence, which is fine for the small inputs this is used for.
+func Lines(l, r []string) []Line {
+	// lcs[i][j] is the length of the longest common subsequence of l[i:]
+	// and r[j:].
+	lcs := make([][]int, len(l)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(r)+1)
+	}
+
+	for i := len(l) - 1; i >= 0; i-- {
+		for j := len(r) - 1; j >= 0; j-- {
+			switch {
+			case l[i] == r[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	out := make([]Line, 0, len(l)+len(r))
+	i, j := 0, 0
+	for i < len(l) && j < len(r) {
+		switch {
+		case l[i] == r[j]:
+			out = append(out, Line{Op: Same, Text: l[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, Line{Op: Removed, Text: l[i]})
+			i++
+		default:
+			out = append(out, Line{Op: Added, Text: r[j]})
+			j++
+		}
+	}
+
+	for ; i < len(l); i++ {
+		out = append(out, Line{Op: Removed, Text: l[i]})
+	}
+
+	for ; j < len(r); j++ {
+		out = append(out, Line{Op: Added, Text: r[j]})
+	}
+
+	return out
+}
@@ -0,0 +1,40 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		old  []string
+		new  []string
+		want []Line
+	}{
+		{
+			old:  nil,
+			new:  []string{"foo"},
+			want: []Line{{Added, "foo"}},
+		},
+		{
+			old:  []string{"foo", "bar", "baz"},
+			new:  []string{"foo", "baz"},
+			want: []Line{{Same, "foo"}, {Removed, "bar"}, {Same, "baz"}},
+		},
+		{
+			old:  []string{"foo", "bar"},
+			new:  []string{"foo", "zab", "bar", "baz"},
+			want: []Line{{Same, "foo"}, {Added, "zab"}, {Same, "bar"}, {Added, "baz"}},
+		},
+		{
+			old:  []string{"foo"},
+			new:  []string{"bar"},
+			want: []Line{{Removed, "foo"}, {Added, "bar"}},
+		},
+	} {
+		assert.Equal(t, tc.want, Lines(tc.old, tc.new))
+	}
+}
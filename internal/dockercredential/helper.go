@@ -0,0 +1,311 @@
+// Package dockercredential implements the docker credential helper protocol
+// (https://github.com/docker/docker-credential-helpers) on top of a
+// gopass.Store.
+package dockercredential
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/fsutil"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/pkg/gopass/secrets/secparse"
+)
+
+// DefaultPrefix is the folder credentials are stored in if no prefix is
+// configured.
+const DefaultPrefix = "docker"
+
+// Keys used in the secrets. The password line holds the secret.
+const (
+	KeyUsername  = "username"
+	KeyServerURL = "url"
+)
+
+// ErrNotFound is returned if there are no credentials for a server. The
+// message is part of the protocol, docker compares it verbatim.
+var ErrNotFound = errors.New("credentials not found in native keychain")
+
+// Actions are the supported protocol actions.
+var Actions = []string{"get", "store", "erase", "list"}
+
+// Credentials are exchanged with docker as JSON.
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// Helper stores credentials below a prefix.
+type Helper struct {
+	store  gopass.Store
+	prefix string
+}
+
+// New creates a new helper.
+func New(store gopass.Store, prefix string) *Helper {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	return &Helper{
+		store:  store,
+		prefix: prefix,
+	}
+}
+
+// Run executes a protocol action. It reads the request from in and writes
+// the response to out.
+func (h *Helper) Run(ctx context.Context, action string, in io.Reader, out io.Writer) error {
+	switch action {
+	case "get":
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+
+		c, err := h.Get(ctx, serverURL)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(out).Encode(c)
+	case "store":
+		c := &Credentials{}
+		if err := json.NewDecoder(in).Decode(c); err != nil {
+			return fmt.Errorf("failed to decode credentials: %w", err)
+		}
+
+		return h.Store(ctx, c)
+	case "erase":
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+
+		return h.Erase(ctx, serverURL)
+	case "list":
+		m, err := h.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(out).Encode(m)
+	default:
+		return fmt.Errorf("unknown action %q, use one of %s", action, strings.Join(Actions, ", "))
+	}
+}
+
+func readServerURL(in io.Reader) (string, error) {
+	buf, err := io.ReadAll(in)
+	if err != nil {
+		return "", fmt.Errorf("failed to read server URL: %w", err)
+	}
+
+	serverURL := string(bytes.TrimSpace(buf))
+	if serverURL == "" {
+		return "", fmt.Errorf("no server URL given")
+	}
+
+	return serverURL, nil
+}
+
+// Get returns the credentials for a server.
+func (h *Helper) Get(ctx context.Context, serverURL string) (*Credentials, error) {
+	name, err := h.Name(serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	sec, err := h.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	username, _ := sec.Get(KeyUsername)
+
+	return &Credentials{
+		ServerURL: serverURL,
+		Username:  username,
+		Secret:    sec.Password(),
+	}, nil
+}
+
+// Store saves the credentials for a server. The secret is not touched if it
+// already holds the same credentials.
+func (h *Helper) Store(ctx context.Context, c *Credentials) error {
+	name, err := h.Name(c.ServerURL)
+	if err != nil {
+		return err
+	}
+
+	var sec gopass.Secret = secrets.NewYAML()
+	switch old, err := h.get(ctx, name); {
+	case err == nil:
+		username, _ := old.Get(KeyUsername)
+		serverURL, _ := old.Get(KeyServerURL)
+		if username == c.Username && serverURL == c.ServerURL && old.Password() == c.Secret {
+			debug.Log("credentials at %s are up to date", name)
+
+			return nil
+		}
+		sec = old
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+
+	sec.SetPassword(c.Secret)
+	if err := sec.Set(KeyUsername, c.Username); err != nil {
+		return fmt.Errorf("failed to set username: %w", err)
+	}
+	if err := sec.Set(KeyServerURL, c.ServerURL); err != nil {
+		return fmt.Errorf("failed to set server URL: %w", err)
+	}
+
+	if err := h.store.Set(ctxutil.WithCommitMessage(ctx, "Stored docker credentials"), name, sec); err != nil {
+		return fmt.Errorf("failed to save credentials to %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Erase removes the credentials for a server.
+func (h *Helper) Erase(ctx context.Context, serverURL string) error {
+	name, err := h.Name(serverURL)
+	if err != nil {
+		return err
+	}
+
+	found, err := h.exists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+
+	if err := h.store.Remove(ctxutil.WithCommitMessage(ctx, "Erased docker credentials"), name); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// List returns the usernames of all stored credentials by server URL.
+func (h *Helper) List(ctx context.Context) (map[string]string, error) {
+	names, err := h.names(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, len(names))
+	for _, name := range names {
+		sec, err := h.store.Get(ctx, name, "latest")
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+
+		serverURL, found := sec.Get(KeyServerURL)
+		if !found {
+			debug.Log("skipping %s: no server URL", name)
+
+			continue
+		}
+
+		username, _ := sec.Get(KeyUsername)
+		m[serverURL] = username
+	}
+
+	return m, nil
+}
+
+// Name returns the name of the secret holding the credentials for a server,
+// i.e. prefix/host[/path]. The scheme is not part of the name, so
+// "https://ghcr.io" and "ghcr.io" share the same credentials.
+func (h *Helper) Name(serverURL string) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Host == "" {
+		// registries are usually given without a scheme.
+		u, err = url.Parse("https://" + serverURL)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid server URL %q: no host", serverURL)
+	}
+
+	elems := []string{h.prefix, fsutil.CleanFilename(u.Host)}
+	for _, e := range strings.Split(u.Path, "/") {
+		e = fsutil.CleanFilename(e)
+		if e == "" || e == "." || e == ".." {
+			continue
+		}
+		elems = append(elems, e)
+	}
+
+	return path.Join(elems...), nil
+}
+
+// get returns the secret or ErrNotFound. The store API does not tell
+// missing secrets apart from other errors, so we look it up in the listing
+// first.
+func (h *Helper) get(ctx context.Context, name string) (gopass.Secret, error) {
+	found, err := h.exists(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+
+	sec, err := h.store.Get(ctx, name, "latest")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+
+	// make sure the keys are available even if the store returned the
+	// secret unparsed.
+	return secparse.Parse(sec.Bytes())
+}
+
+func (h *Helper) exists(ctx context.Context, name string) (bool, error) {
+	names, err := h.names(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	i := sort.SearchStrings(names, name)
+
+	return i < len(names) && names[i] == name, nil
+}
+
+// names returns the sorted names of all secrets below the prefix.
+func (h *Helper) names(ctx context.Context) ([]string, error) {
+	all, err := h.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	names := make([]string, 0, len(all))
+	for _, name := range all {
+		if strings.HasPrefix(name, h.prefix+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
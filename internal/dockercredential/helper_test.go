@@ -0,0 +1,89 @@
+package dockercredential
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/gopass/apimock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestName(t *testing.T) {
+	t.Parallel()
+
+	h := New(apimock.New(), "")
+	for in, want := range map[string]string{
+		"ghcr.io":                     "docker/ghcr.io",
+		"https://ghcr.io":             "docker/ghcr.io",
+		"https://index.docker.io/v1/": "docker/index.docker.io/v1",
+		"registry.local:5000":         "docker/registry.local_5000",
+		"https://example.com/../../x": "docker/example.com/x",
+	} {
+		name, err := h.Name(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, name, in)
+	}
+
+	name, err := New(apimock.New(), "/ci/registries/").Name("ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "ci/registries/ghcr.io", name)
+
+	_, err = h.Name("")
+	assert.Error(t, err)
+}
+
+func TestHelper(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := apimock.New()
+	require.NoError(t, store.Set(ctx, "docker/other", &apimock.Secret{Buf: []byte("no url")}))
+	require.NoError(t, store.Set(ctx, "websites/ghcr.io", &apimock.Secret{Buf: []byte("unrelated")}))
+	h := New(store, "")
+
+	run := func(action, in string) (string, error) {
+		buf := &bytes.Buffer{}
+		err := h.Run(ctx, action, strings.NewReader(in), buf)
+
+		return buf.String(), err
+	}
+
+	_, err := run("get", "ghcr.io\n")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = run("store", `{"ServerURL":"https://ghcr.io","Username":"alice","Secret":"t0ken"}`)
+	require.NoError(t, err)
+
+	sec, err := store.Get(ctx, "docker/ghcr.io", "")
+	require.NoError(t, err)
+	assert.Equal(t, "t0ken\n---\nurl: https://ghcr.io\nusername: alice\n", string(sec.Bytes()))
+
+	out, err := run("get", "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, `{"ServerURL":"ghcr.io","Username":"alice","Secret":"t0ken"}`+"\n", out)
+
+	_, err = run("store", `{"ServerURL":"registry.local:5000","Username":"bob","Secret":"s3cr3t"}`)
+	require.NoError(t, err)
+
+	out, err = run("list", "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"https://ghcr.io":"alice","registry.local:5000":"bob"}`+"\n", out)
+
+	_, err = run("erase", "https://ghcr.io")
+	require.NoError(t, err)
+
+	_, err = run("erase", "https://ghcr.io")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = run("get", "")
+	assert.Error(t, err)
+
+	_, err = run("store", "not json")
+	assert.Error(t, err)
+
+	_, err = run("version", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package exporter
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"github.com/kpitt/gopass/internal/store/leaf"
+)
+
+func init() {
+	Register("age", writeAge, true, true)
+}
+
+// writeAge writes a gzip compressed tar archive encrypted with age. The
+// archive mirrors the layout of a store:
+//
+//	secrets/<name>.txt
+//	history/<name>/<date>-<hash>.txt
+//	templates/<name>/.pass-template
+//
+// The archive is encrypted for opts.Recipients or, if there are none, with
+// opts.Password.
+func writeAge(w io.Writer, e *Export, opts Options) error {
+	recps, err := ageRecipients(opts)
+	if err != nil {
+		return err
+	}
+
+	aw, err := age.Encrypt(w, recps...)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt archive: %w", err)
+	}
+
+	zw := gzip.NewWriter(aw)
+	tw := tar.NewWriter(zw)
+
+	now := time.Now()
+	for _, s := range e.Secrets {
+		if err := tarFile(tw, path.Join("secrets", s.Name+".txt"), s.Secret.Bytes(), now); err != nil {
+			return err
+		}
+
+		for _, r := range s.Revisions {
+			if r.Secret == nil {
+				continue
+			}
+
+			fn := fmt.Sprintf("%s-%s.txt", r.Date.UTC().Format("20060102T150405Z"), r.Hash)
+			if err := tarFile(tw, path.Join("history", s.Name, fn), r.Secret.Bytes(), r.Date); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, t := range e.Templates {
+		if err := tarFile(tw, path.Join("templates", t.Name, leaf.TemplateFile), t.Content, now); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+	if err := aw.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt archive: %w", err)
+	}
+
+	return nil
+}
+
+func tarFile(tw *tar.Writer, name string, content []byte, mtime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(content)),
+		ModTime: mtime,
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ageRecipients parses native age and SSH recipients. Without recipients
+// a passphrase recipient is returned.
+func ageRecipients(opts Options) ([]age.Recipient, error) {
+	if len(opts.Recipients) < 1 {
+		if opts.Password == "" {
+			return nil, fmt.Errorf("a recipient or a passphrase is required to write an age archive")
+		}
+
+		r, err := age.NewScryptRecipient(opts.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to use passphrase: %w", err)
+		}
+
+		return []age.Recipient{r}, nil
+	}
+
+	recps := make([]age.Recipient, 0, len(opts.Recipients))
+	for _, s := range opts.Recipients {
+		var r age.Recipient
+		var err error
+		if strings.HasPrefix(s, "ssh-") {
+			r, err = agessh.ParseRecipient(s)
+		} else {
+			r, err = age.ParseX25519Recipient(s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", s, err)
+		}
+		recps = append(recps, r)
+	}
+
+	return recps, nil
+}
@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readArchive(t *testing.T, r io.Reader, id age.Identity) map[string]string {
+	t.Helper()
+
+	ar, err := age.Decrypt(r, id)
+	require.NoError(t, err)
+	zr, err := gzip.NewReader(ar)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		buf, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(buf)
+	}
+
+	return files
+}
+
+func TestAge(t *testing.T) {
+	t.Parallel()
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	assert.Error(t, writeAge(&bytes.Buffer{}, testExport(t), Options{}))
+	assert.Error(t, writeAge(&bytes.Buffer{}, testExport(t), Options{Recipients: []string{"foo"}}))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeAge(buf, testExport(t), Options{Recipients: []string{id.Recipient().String()}}))
+
+	files := readArchive(t, buf, id)
+	assert.Len(t, files, 4)
+	assert.Contains(t, files["secrets/websites/example.org.txt"], "s3cr3t\n")
+	assert.Equal(t, "bar\n", files["secrets/foo.txt"])
+	assert.Equal(t, "hunter2\n", files["history/websites/example.org/20220102T030405Z-abcdef.txt"])
+	assert.Equal(t, "{{ .Name }}\n", files["templates/websites/.pass-template"])
+}
+
+func TestAgePassphrase(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeAge(buf, &Export{}, Options{Password: "foo"}))
+
+	id, err := age.NewScryptIdentity("foo")
+	require.NoError(t, err)
+	assert.Empty(t, readArchive(t, buf, id))
+}
@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register("csv", writeCSV, false, false)
+}
+
+// writeCSV writes one row per secret. The columns are name, password, one
+// column for every key used by any secret and the body. Keys with multiple
+// values are joined by newlines. Templates are written as rows below
+// .templates/ with the template in the body column.
+func writeCSV(w io.Writer, e *Export, _ Options) error {
+	seen := map[string]bool{}
+	for _, s := range e.Secrets {
+		for _, k := range s.Secret.Keys() {
+			seen[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cw := csv.NewWriter(w)
+
+	header := append(append([]string{"name", "password"}, keys...), "body")
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	for _, s := range e.Secrets {
+		row := make([]string, 0, len(header))
+		row = append(row, s.Name, s.Secret.Password())
+		for _, k := range keys {
+			v, _ := s.Secret.Values(k)
+			row = append(row, strings.Join(v, "\n"))
+		}
+		row = append(row, s.Secret.Body())
+
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	for _, t := range e.Templates {
+		row := make([]string, len(header))
+		row[0] = templatePrefix + t.Name
+		row[len(row)-1] = string(t.Content)
+
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
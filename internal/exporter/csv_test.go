@@ -0,0 +1,27 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSV(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeCSV(buf, testExport(t), Options{}))
+
+	rows, err := csv.NewReader(buf).ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"name", "password", "otpauth", "url", "username", "body"},
+		{"websites/example.org", "s3cr3t", "//totp/example?secret=JBSWY3DPEHPK3PXP", "https://example.org", "alice", "some notes"},
+		{"foo", "bar", "", "", "", ""},
+		{".templates/websites", "", "", "", "", "{{ .Name }}\n"},
+	}, rows)
+}
@@ -0,0 +1,125 @@
+// Package exporter writes the decrypted content of a store to structured
+// files, e.g. for audits, offboarding or disaster recovery.
+//
+// Each supported format registers a Writer. The caller collects the
+// secrets, templates and (optionally) their history in an Export and passes
+// it to the writer of the selected format.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/pkg/gopass"
+)
+
+// Export is everything that is written by an export.
+type Export struct {
+	Secrets   []Secret
+	Templates []Template
+}
+
+// Secret is a single decrypted secret.
+type Secret struct {
+	Name   string
+	Secret gopass.Secret
+	// Revisions are the past revisions, newest first. Only set if the
+	// history was requested.
+	Revisions []Revision
+}
+
+// Revision is a single revision of a secret. Secret is nil if that
+// revision could not be decrypted.
+type Revision struct {
+	backend.Revision
+	Secret gopass.Secret
+}
+
+// Template is a single template.
+type Template struct {
+	Name    string
+	Content []byte
+}
+
+// Options are passed to all writers. Writers ignore options they don't
+// support.
+type Options struct {
+	// Password protects KeePass databases and age archives.
+	Password string
+	// Recipients of age archives. Password is used if empty.
+	Recipients []string
+}
+
+// Writer writes an export.
+type Writer func(w io.Writer, e *Export, opts Options) error
+
+type format struct {
+	writer  Writer
+	history bool
+	binary  bool
+}
+
+var formats = map[string]format{}
+
+// Register adds a new format. Formats that can include the history of
+// secrets set history, formats that write binary data set binary.
+func Register(name string, w Writer, history, binary bool) {
+	formats[name] = format{
+		writer:  w,
+		history: history,
+		binary:  binary,
+	}
+}
+
+// Formats returns the names of all registered formats.
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the writer for a format.
+func Get(name string) (Writer, error) {
+	f, found := formats[name]
+	if !found {
+		return nil, fmt.Errorf("unknown format %q. Supported formats: %s", name, strings.Join(Formats(), ", "))
+	}
+
+	return f.writer, nil
+}
+
+// SupportsHistory returns true if the format can include past revisions.
+func SupportsHistory(name string) bool {
+	return formats[name].history
+}
+
+// IsBinary returns true if the format is not meant to be written to a
+// terminal.
+func IsBinary(name string) bool {
+	return formats[name].binary
+}
+
+// templatePrefix is prepended to the names of templates in formats that
+// don't distinguish between secrets and templates.
+const templatePrefix = ".templates/"
+
+// keyValues returns all keys of a secret with their values. The password
+// is not included.
+func keyValues(sec gopass.Secret) map[string][]string {
+	keys := sec.Keys()
+	kv := make(map[string][]string, len(keys))
+	for _, k := range keys {
+		if v, found := sec.Values(k); found {
+			kv[k] = v
+		}
+	}
+
+	return kv
+}
@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExport(t *testing.T) *Export {
+	t.Helper()
+
+	web := secrets.NewKV()
+	web.SetPassword("s3cr3t")
+	require.NoError(t, web.Set("username", "alice"))
+	require.NoError(t, web.Set("url", "https://example.org"))
+	require.NoError(t, web.Set("otpauth", "//totp/example?secret=JBSWY3DPEHPK3PXP"))
+	_, err := web.Write([]byte("some notes"))
+	require.NoError(t, err)
+
+	old := secrets.NewKV()
+	old.SetPassword("hunter2")
+
+	plain := secrets.NewKV()
+	plain.SetPassword("bar")
+
+	return &Export{
+		Secrets: []Secret{
+			{
+				Name:   "websites/example.org",
+				Secret: web,
+				Revisions: []Revision{
+					{
+						Revision: backend.Revision{
+							Hash:       "abcdef",
+							AuthorName: "Alice",
+							Date:       time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+							Subject:    "Save secret",
+						},
+						Secret: old,
+					},
+					{Revision: backend.Revision{Hash: "012345"}},
+				},
+			},
+			{Name: "foo", Secret: plain},
+		},
+		Templates: []Template{{Name: "websites", Content: []byte("{{ .Name }}\n")}},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"age", "csv", "json", "kdbx"}, Formats())
+
+	_, err := Get("xml")
+	assert.Error(t, err)
+
+	assert.True(t, SupportsHistory("json"))
+	assert.False(t, SupportsHistory("csv"))
+	assert.True(t, IsBinary("kdbx"))
+	assert.False(t, IsBinary("json"))
+}
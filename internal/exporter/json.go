@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+func init() {
+	Register("json", writeJSON, true, false)
+}
+
+// The types below define the JSON document. Fields must only ever be
+// added, never renamed or removed.
+
+type jsonExport struct {
+	Secrets   []jsonSecret   `json:"secrets"`
+	Templates []jsonTemplate `json:"templates"`
+}
+
+type jsonSecret struct {
+	Name      string              `json:"name"`
+	Password  string              `json:"password"`
+	Keys      map[string][]string `json:"keys,omitempty"`
+	Body      string              `json:"body,omitempty"`
+	Revisions []jsonRevision      `json:"revisions,omitempty"`
+}
+
+type jsonRevision struct {
+	Hash        string              `json:"hash"`
+	AuthorName  string              `json:"author_name"`
+	AuthorEmail string              `json:"author_email"`
+	Date        time.Time           `json:"date"`
+	Subject     string              `json:"subject"`
+	Password    string              `json:"password,omitempty"`
+	Keys        map[string][]string `json:"keys,omitempty"`
+	Body        string              `json:"body,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+type jsonTemplate struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func writeJSON(w io.Writer, e *Export, _ Options) error {
+	doc := jsonExport{
+		Secrets:   make([]jsonSecret, 0, len(e.Secrets)),
+		Templates: make([]jsonTemplate, 0, len(e.Templates)),
+	}
+
+	for _, s := range e.Secrets {
+		js := jsonSecret{
+			Name:     s.Name,
+			Password: s.Secret.Password(),
+			Keys:     keyValues(s.Secret),
+			Body:     s.Secret.Body(),
+		}
+
+		for _, r := range s.Revisions {
+			jr := jsonRevision{
+				Hash:        r.Hash,
+				AuthorName:  r.AuthorName,
+				AuthorEmail: r.AuthorEmail,
+				Date:        r.Date,
+				Subject:     r.Subject,
+			}
+			if r.Secret == nil {
+				jr.Error = "failed to decrypt"
+			} else {
+				jr.Password = r.Secret.Password()
+				jr.Keys = keyValues(r.Secret)
+				jr.Body = r.Secret.Body()
+			}
+			js.Revisions = append(js.Revisions, jr)
+		}
+
+		doc.Secrets = append(doc.Secrets, js)
+	}
+
+	for _, t := range e.Templates {
+		doc.Templates = append(doc.Templates, jsonTemplate{
+			Name:    t.Name,
+			Content: string(t.Content),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	return nil
+}
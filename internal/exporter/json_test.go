@@ -0,0 +1,33 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeJSON(buf, testExport(t), Options{}))
+
+	var doc jsonExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Secrets, 2)
+
+	s := doc.Secrets[0]
+	assert.Equal(t, "websites/example.org", s.Name)
+	assert.Equal(t, "s3cr3t", s.Password)
+	assert.Equal(t, []string{"alice"}, s.Keys["username"])
+	assert.Equal(t, "some notes", s.Body)
+	require.Len(t, s.Revisions, 2)
+	assert.Equal(t, "hunter2", s.Revisions[0].Password)
+	assert.Equal(t, "Alice", s.Revisions[0].AuthorName)
+	assert.Equal(t, "failed to decrypt", s.Revisions[1].Error)
+
+	assert.Equal(t, []jsonTemplate{{Name: "websites", Content: "{{ .Name }}\n"}}, doc.Templates)
+}
@@ -0,0 +1,125 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/kdbx"
+)
+
+func init() {
+	Register("kdbx", writeKeePass, false, true)
+}
+
+// kpFields maps keys to the standard KeePass fields. The first key that
+// is set wins, all others are written as custom fields.
+var kpFields = map[string][]string{
+	kdbx.FieldUserName: {"username", "user", "login"},
+	kdbx.FieldURL:      {"url", "website"},
+}
+
+// writeKeePass writes a KDBX 4 database protected by opts.Password.
+// Folders become groups and templates are stored in a group .templates.
+func writeKeePass(w io.Writer, e *Export, opts Options) error {
+	if opts.Password == "" {
+		return fmt.Errorf("a password is required to write a KeePass database")
+	}
+
+	root := &kdbx.Group{Name: "gopass"}
+	for _, s := range e.Secrets {
+		g, title := kpGroup(root, s.Name)
+		g.Entries = append(g.Entries, kpEntry(title, s.Secret))
+	}
+
+	for _, t := range e.Templates {
+		g, title := kpGroup(root, templatePrefix+t.Name)
+		g.Entries = append(g.Entries, &kdbx.Entry{
+			Fields: []kdbx.Field{
+				{Key: kdbx.FieldTitle, Value: title},
+				{Key: kdbx.FieldNotes, Value: string(t.Content)},
+			},
+		})
+	}
+
+	db := &kdbx.Database{
+		Name: "gopass",
+		Root: root,
+	}
+
+	if err := kdbx.Encode(w, db, kdbx.Credentials{Password: opts.Password}); err != nil {
+		return fmt.Errorf("failed to write KeePass database: %w", err)
+	}
+
+	return nil
+}
+
+// kpGroup returns the group for a secret, creating it if necessary, and
+// the title of the entry.
+func kpGroup(root *kdbx.Group, name string) (*kdbx.Group, string) {
+	parts := strings.Split(name, "/")
+	g := root
+
+OUTER:
+	for _, p := range parts[:len(parts)-1] {
+		for _, sg := range g.Groups {
+			if sg.Name == p {
+				g = sg
+
+				continue OUTER
+			}
+		}
+
+		sg := &kdbx.Group{Name: p}
+		g.Groups = append(g.Groups, sg)
+		g = sg
+	}
+
+	return g, parts[len(parts)-1]
+}
+
+func kpEntry(title string, sec gopass.Secret) *kdbx.Entry {
+	e := &kdbx.Entry{
+		Fields: []kdbx.Field{
+			{Key: kdbx.FieldTitle, Value: title},
+			{Key: kdbx.FieldPassword, Value: sec.Password()},
+		},
+	}
+
+	used := map[string]bool{}
+	for _, field := range []string{kdbx.FieldUserName, kdbx.FieldURL} {
+		for _, k := range kpFields[field] {
+			if v, found := sec.Get(k); found {
+				e.Fields = append(e.Fields, kdbx.Field{Key: field, Value: v})
+				used[k] = true
+
+				break
+			}
+		}
+	}
+
+	for _, k := range sec.Keys() {
+		if used[k] {
+			continue
+		}
+
+		vs, _ := sec.Values(k)
+		v := strings.Join(vs, "\n")
+		key := k
+		if k == "otpauth" {
+			// KeePassXC expects the full URL in the field otp.
+			key = "otp"
+			if strings.HasPrefix(v, "//") {
+				v = "otpauth:" + v
+			}
+		}
+		e.Fields = append(e.Fields, kdbx.Field{Key: key, Value: v, Protected: key == "otp"})
+	}
+
+	if body := sec.Body(); body != "" {
+		e.Fields = append(e.Fields, kdbx.Field{Key: kdbx.FieldNotes, Value: body})
+	}
+
+	return e
+}
@@ -0,0 +1,40 @@
+package exporter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/kdbx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeePass(t *testing.T) {
+	t.Parallel()
+
+	assert.Error(t, writeKeePass(&bytes.Buffer{}, testExport(t), Options{}))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeKeePass(buf, testExport(t), Options{Password: "foo"}))
+
+	db, err := kdbx.Decode(buf, kdbx.Credentials{Password: "foo"})
+	require.NoError(t, err)
+
+	root := db.Root
+	require.Len(t, root.Entries, 1)
+	assert.Equal(t, "foo", root.Entries[0].Get(kdbx.FieldTitle))
+	assert.Equal(t, "bar", root.Entries[0].Get(kdbx.FieldPassword))
+
+	require.Len(t, root.Groups, 2)
+	assert.Equal(t, "websites", root.Groups[0].Name)
+	e := root.Groups[0].Entries[0]
+	assert.Equal(t, "example.org", e.Get(kdbx.FieldTitle))
+	assert.Equal(t, "s3cr3t", e.Get(kdbx.FieldPassword))
+	assert.Equal(t, "alice", e.Get(kdbx.FieldUserName))
+	assert.Equal(t, "https://example.org", e.Get(kdbx.FieldURL))
+	assert.Equal(t, "some notes", e.Get(kdbx.FieldNotes))
+	assert.Equal(t, "otpauth://totp/example?secret=JBSWY3DPEHPK3PXP", e.Get("otp"))
+
+	assert.Equal(t, ".templates", root.Groups[1].Name)
+	assert.Equal(t, "{{ .Name }}\n", root.Groups[1].Entries[0].Get(kdbx.FieldNotes))
+}
@@ -0,0 +1,131 @@
+// Package gitcredential implements the git credential helper protocol as
+// described in git-credential(1).
+package gitcredential
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/fsutil"
+)
+
+// DefaultPrefix is the folder credentials are stored in if no prefix is
+// configured.
+const DefaultPrefix = "git"
+
+// Credential is a set of attributes exchanged with git. Attributes not used
+// by gopass, e.g. wwwauth[], are ignored.
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// Read reads a credential description from r. The description ends with a
+// blank line or at EOF.
+func Read(r io.Reader) (*Credential, error) {
+	c := &Credential{}
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSuffix(s.Text(), "\r")
+		if line == "" {
+			break
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid line %q: missing '='", line)
+		}
+
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "url":
+			if err := c.setURL(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read credential: %w", err)
+	}
+
+	return c, nil
+}
+
+// setURL sets all attributes contained in the URL. As git does, they
+// replace any attribute seen before.
+func (c *Credential) setURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", value, err)
+	}
+
+	c.Protocol = u.Scheme
+	c.Host = u.Host
+	c.Path = strings.TrimPrefix(u.Path, "/")
+	c.Username = u.User.Username()
+	c.Password, _ = u.User.Password()
+
+	return nil
+}
+
+// WriteTo writes the username and password in the format expected by git.
+// Empty attributes are omitted.
+func (c *Credential) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	for _, attr := range [][2]string{
+		{"username", c.Username},
+		{"password", c.Password},
+	} {
+		if attr[1] == "" {
+			continue
+		}
+		sb.WriteString(attr[0] + "=" + attr[1] + "\n")
+	}
+
+	n, err := io.WriteString(w, sb.String())
+
+	return int64(n), err
+}
+
+// Name returns the name of the secret holding this credential, i.e.
+// prefix/protocol/host[/path]. Characters that are not safe in file names
+// are replaced. The path is only present if git was configured to send it
+// (credential.useHttpPath).
+func (c *Credential) Name(prefix string) (string, error) {
+	if c.Protocol == "" || c.Host == "" {
+		return "", fmt.Errorf("protocol and host are required")
+	}
+
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	elems := []string{strings.Trim(prefix, "/"), fsutil.CleanFilename(c.Protocol), fsutil.CleanFilename(c.Host)}
+	for _, e := range strings.Split(c.Path, "/") {
+		e = fsutil.CleanFilename(e)
+		if e == "" || e == "." || e == ".." {
+			continue
+		}
+		elems = append(elems, e)
+	}
+
+	return path.Join(elems...), nil
+}
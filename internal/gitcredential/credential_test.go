@@ -0,0 +1,106 @@
+package gitcredential
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		in   string
+		want Credential
+	}{
+		{
+			name: "attributes",
+			in:   "protocol=https\nhost=example.com\nusername=alice\npassword=secret\n\n",
+			want: Credential{Protocol: "https", Host: "example.com", Username: "alice", Password: "secret"},
+		},
+		{
+			name: "eof without blank line",
+			in:   "protocol=https\nhost=example.com:8443\npath=org/repo.git",
+			want: Credential{Protocol: "https", Host: "example.com:8443", Path: "org/repo.git"},
+		},
+		{
+			name: "url",
+			in:   "url=https://bob@example.com/org/repo.git\n",
+			want: Credential{Protocol: "https", Host: "example.com", Path: "org/repo.git", Username: "bob"},
+		},
+		{
+			name: "unknown attributes and crlf",
+			in:   "protocol=https\r\nwwwauth[]=Basic realm=\"x\"\r\nhost=example.com\r\n",
+			want: Credential{Protocol: "https", Host: "example.com"},
+		},
+		{
+			name: "value with equals sign",
+			in:   "password=a=b\n",
+			want: Credential{Password: "a=b"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := Read(strings.NewReader(tc.in))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, *c)
+		})
+	}
+
+	_, err := Read(strings.NewReader("protocol\n"))
+	assert.Error(t, err)
+}
+
+func TestWriteTo(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	c := &Credential{Protocol: "https", Host: "example.com", Username: "alice", Password: "secret"}
+	n, err := c.WriteTo(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "username=alice\npassword=secret\n", buf.String())
+	assert.Equal(t, int64(buf.Len()), n)
+
+	buf.Reset()
+	c = &Credential{Password: "secret"}
+	_, err = c.WriteTo(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "password=secret\n", buf.String())
+}
+
+func TestName(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		prefix string
+		c      Credential
+		want   string
+	}{
+		{
+			c:    Credential{Protocol: "https", Host: "example.com"},
+			want: "git/https/example.com",
+		},
+		{
+			prefix: "dev/git/",
+			c:      Credential{Protocol: "https", Host: "example.com:8443", Path: "org/repo.git"},
+			want:   "dev/git/https/example.com_8443/org/repo.git",
+		},
+		{
+			c:    Credential{Protocol: "https", Host: "example.com", Path: "../../etc/./passwd"},
+			want: "git/https/example.com/etc/passwd",
+		},
+	} {
+		name, err := tc.c.Name(tc.prefix)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, name)
+	}
+
+	_, err := (&Credential{Protocol: "https"}).Name("")
+	assert.Error(t, err)
+}
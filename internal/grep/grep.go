@@ -0,0 +1,265 @@
+// Package grep searches the decrypted content of secrets. Secrets are
+// decrypted concurrently, limited by the concurrency of the store.
+package grep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+)
+
+// Special field names. All other fields are keys of the secret.
+const (
+	FieldPassword = "password"
+	FieldBody     = "body"
+)
+
+// MatchFunc returns true if the line matches.
+type MatchFunc func(line string) bool
+
+// NewMatcher returns a MatchFunc for a substring or a RE2 regular
+// expression.
+func NewMatcher(pattern string, isRegexp, ignoreCase bool) (MatchFunc, error) {
+	if !isRegexp {
+		if ignoreCase {
+			pattern = strings.ToLower(pattern)
+
+			return func(line string) bool {
+				return strings.Contains(strings.ToLower(line), pattern)
+			}, nil
+		}
+
+		return func(line string) bool {
+			return strings.Contains(line, pattern)
+		}, nil
+	}
+
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regexp %q: %w", pattern, err)
+	}
+
+	return re.MatchString, nil
+}
+
+// Options configure a search.
+type Options struct {
+	// Fields restricts the search to these fields. The whole secret is
+	// searched if it is empty.
+	Fields []string
+	// Context is the number of lines to include before and after every
+	// matching line.
+	Context int
+}
+
+// Line is a single line of a field.
+type Line struct {
+	// No is the 1-based number of the line in its field.
+	No    int
+	Text  string
+	Match bool
+}
+
+// Field holds the matching lines of a single field. Field is empty if the
+// whole secret was searched.
+type Field struct {
+	Field string
+	// Groups are runs of adjacent lines, i.e. matches and their context.
+	Groups [][]Line
+}
+
+// Result is the result for a single secret. It either contains the
+// matching fields or an error.
+type Result struct {
+	Name   string
+	Fields []Field
+	Err    error
+}
+
+// Matches returns the number of matching lines.
+func (r Result) Matches() int {
+	n := 0
+	for _, f := range r.Fields {
+		for _, g := range f.Groups {
+			for _, l := range g {
+				if l.Match {
+					n++
+				}
+			}
+		}
+	}
+
+	return n
+}
+
+type secretGetter interface {
+	Get(context.Context, string) (gopass.Secret, error)
+	Concurrency() int
+}
+
+// Search decrypts all named secrets and returns the ones that match or that
+// failed to decrypt, sorted by name. The callback is invoked after each
+// secret, e.g. to advance a progress bar.
+func Search(ctx context.Context, names []string, store secretGetter, match MatchFunc, opts Options, progress func()) []Result {
+	// the raw content is searched unless we need to look at single fields.
+	ctx = ctxutil.WithShowParsing(ctx, len(opts.Fields) > 0)
+
+	pending := make(chan string, 100)
+	results := make(chan Result, 100)
+
+	maxJobs := store.Concurrency()
+	if maxJobs < 1 {
+		maxJobs = 1
+	}
+
+	done := make(chan struct{}, maxJobs)
+	for i := 0; i < maxJobs; i++ {
+		go func() {
+			for name := range pending {
+				results <- search(ctx, name, store, match, opts)
+			}
+			done <- struct{}{}
+		}()
+	}
+
+	go func() {
+		for _, name := range names {
+			pending <- name
+		}
+		close(pending)
+	}()
+	go func() {
+		for i := 0; i < maxJobs; i++ {
+			<-done
+		}
+		close(results)
+	}()
+
+	found := make([]Result, 0, 16)
+	for r := range results {
+		if progress != nil {
+			progress()
+		}
+
+		if r.Err != nil || len(r.Fields) > 0 {
+			found = append(found, r)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Name < found[j].Name
+	})
+
+	return found
+}
+
+func search(ctx context.Context, name string, store secretGetter, match MatchFunc, opts Options) Result {
+	r := Result{Name: name}
+
+	select {
+	case <-ctx.Done():
+		r.Err = errors.New("user aborted")
+
+		return r
+	default:
+	}
+
+	sec, err := store.Get(ctx, name)
+	if err != nil {
+		debug.Log("failed to decrypt %s: %s", name, err)
+		r.Err = err
+
+		return r
+	}
+
+	if len(opts.Fields) < 1 {
+		if groups := matchLines(splitLines(string(sec.Bytes())), match, opts.Context); len(groups) > 0 {
+			r.Fields = append(r.Fields, Field{Groups: groups})
+		}
+
+		return r
+	}
+
+	for _, field := range opts.Fields {
+		if groups := matchLines(fieldLines(sec, field), match, opts.Context); len(groups) > 0 {
+			r.Fields = append(r.Fields, Field{Field: field, Groups: groups})
+		}
+	}
+
+	return r
+}
+
+// fieldLines returns the lines of a field. A key with multiple values has
+// one line per value.
+func fieldLines(sec gopass.Secret, field string) []string {
+	switch field {
+	case FieldPassword:
+		return []string{sec.Password()}
+	case FieldBody:
+		return splitLines(sec.Body())
+	default:
+		values, _ := sec.Values(field)
+
+		return values
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// matchLines returns all matching lines together with numContext lines of
+// context, grouped into runs of adjacent lines.
+func matchLines(lines []string, match MatchFunc, numContext int) [][]Line {
+	matched := make([]bool, len(lines))
+	include := make([]bool, len(lines))
+	for i, l := range lines {
+		if !match(l) {
+			continue
+		}
+
+		matched[i] = true
+		for j := i - numContext; j <= i+numContext; j++ {
+			if j >= 0 && j < len(lines) {
+				include[j] = true
+			}
+		}
+	}
+
+	var groups [][]Line
+	var cur []Line
+	for i, l := range lines {
+		if !include[i] {
+			if len(cur) > 0 {
+				groups = append(groups, cur)
+				cur = nil
+			}
+
+			continue
+		}
+
+		cur = append(cur, Line{No: i + 1, Text: l, Match: matched[i]})
+	}
+
+	if len(cur) > 0 {
+		groups = append(groups, cur)
+	}
+
+	return groups
+}
@@ -0,0 +1,139 @@
+package grep
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/pkg/gopass/secrets/secparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore map[string]string
+
+func (f fakeStore) Get(_ context.Context, name string) (gopass.Secret, error) {
+	content, found := f[name]
+	if !found {
+		return nil, fmt.Errorf("decryption failed")
+	}
+
+	return secparse.Parse([]byte(content))
+}
+
+func (f fakeStore) Concurrency() int {
+	return 4
+}
+
+func TestNewMatcher(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		pattern    string
+		regexp     bool
+		ignoreCase bool
+		line       string
+		want       bool
+	}{
+		{pattern: "foo", line: "a foo b", want: true},
+		{pattern: "foo", line: "a FOO b", want: false},
+		{pattern: "foo", ignoreCase: true, line: "a FOO b", want: true},
+		{pattern: "^f.o$", regexp: true, line: "fao", want: true},
+		{pattern: "^f.o$", regexp: true, line: "FAO", want: false},
+		{pattern: "^f.o$", regexp: true, ignoreCase: true, line: "FAO", want: true},
+	} {
+		m, err := NewMatcher(tc.pattern, tc.regexp, tc.ignoreCase)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, m(tc.line), "%+v", tc)
+	}
+
+	_, err := NewMatcher("(", true, false)
+	assert.Error(t, err)
+}
+
+func TestMatchLines(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"a", "match 1", "b", "c", "d", "e", "match 2", "match 3", "f"}
+	m, err := NewMatcher("match", false, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]Line{
+		{{No: 2, Text: "match 1", Match: true}},
+		{{No: 7, Text: "match 2", Match: true}, {No: 8, Text: "match 3", Match: true}},
+	}, matchLines(lines, m, 0))
+
+	assert.Equal(t, [][]Line{
+		{{No: 1, Text: "a"}, {No: 2, Text: "match 1", Match: true}, {No: 3, Text: "b"}},
+		{{No: 6, Text: "e"}, {No: 7, Text: "match 2", Match: true}, {No: 8, Text: "match 3", Match: true}, {No: 9, Text: "f"}},
+	}, matchLines(lines, m, 1))
+
+	assert.Len(t, matchLines(lines, m, 3), 1)
+	assert.Nil(t, matchLines(nil, m, 3))
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	store := fakeStore{
+		"web/example.org": "foobar\nusername: alice\nurl: example.org\nnotes about foo\n",
+		"web/other.org":   "s3cr3t\nusername: foo\n",
+		"db/root":         "hunter2\n",
+	}
+	names := []string{"web/example.org", "web/other.org", "db/root", "broken"}
+
+	m, err := NewMatcher("foo", false, false)
+	require.NoError(t, err)
+
+	var calls int32
+	progress := func() {
+		atomic.AddInt32(&calls, 1)
+	}
+
+	res := Search(context.Background(), names, store, m, Options{}, progress)
+	assert.Equal(t, int32(4), calls)
+	require.Len(t, res, 3)
+	assert.Equal(t, "broken", res[0].Name)
+	assert.Error(t, res[0].Err)
+	assert.Equal(t, "web/example.org", res[1].Name)
+	assert.Equal(t, 2, res[1].Matches())
+	assert.Equal(t, "web/other.org", res[2].Name)
+	assert.Equal(t, []Field{{Groups: [][]Line{{{No: 2, Text: "username: foo", Match: true}}}}}, res[2].Fields)
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+
+		res := Search(context.Background(), names[:3], store, m, Options{Fields: []string{FieldPassword, "username"}}, nil)
+		require.Len(t, res, 2)
+		assert.Equal(t, []Field{{Field: FieldPassword, Groups: [][]Line{{{No: 1, Text: "foobar", Match: true}}}}}, res[0].Fields)
+		assert.Equal(t, []Field{{Field: "username", Groups: [][]Line{{{No: 1, Text: "foo", Match: true}}}}}, res[1].Fields)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		t.Parallel()
+
+		sec := secrets.NewKV()
+		sec.SetPassword("foo")
+		_, err := sec.Write([]byte("line 1\nfoo in the body\n"))
+		require.NoError(t, err)
+		store := fakeStore{"kv": string(sec.Bytes())}
+
+		res := Search(context.Background(), []string{"kv"}, store, m, Options{Fields: []string{FieldBody}, Context: 1}, nil)
+		require.Len(t, res, 1)
+		assert.Equal(t, []Field{{Field: FieldBody, Groups: [][]Line{{{No: 1, Text: "line 1"}, {No: 2, Text: "foo in the body", Match: true}}}}}, res[0].Fields)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		res := Search(ctx, names[:3], store, m, Options{}, nil)
+		require.Len(t, res, 3)
+		assert.Error(t, res[0].Err)
+	})
+}
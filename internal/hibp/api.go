@@ -0,0 +1,111 @@
+package hibp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kpitt/gopass/pkg/debug"
+)
+
+// URL is the base URL of the range API.
+var URL = "https://api.pwnedpasswords.com/range/"
+
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	},
+}
+
+// Client queries the range API. It is safe for concurrent use. Responses
+// are cached for the lifetime of the client.
+type Client struct {
+	mu    sync.Mutex
+	cache map[string]map[string]uint64
+}
+
+// NewClient creates a new API client.
+func NewClient() *Client {
+	return &Client{
+		cache: make(map[string]map[string]uint64, 16),
+	}
+}
+
+// Lookup returns how often the password was seen in data breaches.
+func (c *Client) Lookup(ctx context.Context, password string) (uint64, error) {
+	h := Hash(password)
+	prefix, suffix := h[:prefixLen], h[prefixLen:]
+
+	c.mu.Lock()
+	hashes, found := c.cache[prefix]
+	c.mu.Unlock()
+
+	if !found {
+		var err error
+		if hashes, err = fetchRange(ctx, prefix); err != nil {
+			return 0, err
+		}
+
+		c.mu.Lock()
+		c.cache[prefix] = hashes
+		c.mu.Unlock()
+	}
+
+	return hashes[suffix], nil
+}
+
+// fetchRange returns all suffixes with their count for a hash prefix.
+func fetchRange(ctx context.Context, prefix string) (map[string]uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	debug.Log("fetching hash range %s", prefix)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "gopass")
+	// padding hides the number of matching hashes from observers.
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query HIBP: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query HIBP: %s", resp.Status)
+	}
+
+	hashes := make(map[string]uint64, 1024)
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if sc.Text() == "" {
+			continue
+		}
+
+		suffix, count, err := parseLine(sc.Text())
+		if err != nil {
+			return nil, err
+		}
+
+		if count > 0 {
+			hashes[suffix] = count
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read HIBP response: %w", err)
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,53 @@
+package hibp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) { //nolint:paralleltest
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+
+		if r.URL.Path != "/range/5BAA6" {
+			http.Error(w, "boom", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		fmt.Fprint(w, "003D68EB55068C33ACE09247EE4C639306B:3\r\n")
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n")
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD9:0\r\n")
+	}))
+	defer srv.Close()
+
+	oldURL := URL
+	URL = srv.URL + "/range/"
+	defer func() {
+		URL = oldURL
+	}()
+
+	ctx := context.Background()
+	c := NewClient()
+
+	n, err := c.Lookup(ctx, "password")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3861493), n)
+
+	// cached
+	n, err = c.Lookup(ctx, "password")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3861493), n)
+	assert.Equal(t, []string{"/range/5BAA6"}, requests)
+
+	_, err = c.Lookup(ctx, "not in the mock")
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package hibp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Dump is a local copy of the password database, as downloaded from
+// haveibeenpwned.com. It must be the SHA1 version ordered by hash, i.e. one
+// "HASH:COUNT" line per password, sorted by HASH. The file is searched with
+// a binary search, so it is never read completely.
+type Dump struct {
+	mu   sync.Mutex
+	fh   *os.File
+	size int64
+}
+
+// OpenDump opens a dump file.
+func OpenDump(path string) (*Dump, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dump: %w", err)
+	}
+
+	fi, err := fh.Stat()
+	if err != nil {
+		_ = fh.Close()
+
+		return nil, fmt.Errorf("failed to stat dump: %w", err)
+	}
+
+	return &Dump{
+		fh:   fh,
+		size: fi.Size(),
+	}, nil
+}
+
+// Close closes the dump file.
+func (d *Dump) Close() error {
+	return d.fh.Close()
+}
+
+// Lookup returns how often the password was seen in data breaches.
+func (d *Dump) Lookup(_ context.Context, password string) (uint64, error) {
+	return d.LookupHash(Hash(password))
+}
+
+// LookupHash returns the count of a SHA1 hash.
+func (d *Dump) LookupHash(hash string) (uint64, error) {
+	hash = strings.ToUpper(hash)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// lo and hi are byte offsets. The line we are looking for, if it exists,
+	// always starts in [lo, hi).
+	lo, hi := int64(0), d.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := d.lineAt(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if line == "" {
+			hi = mid
+
+			continue
+		}
+
+		h, count, err := parseLine(line)
+		if err != nil {
+			return 0, fmt.Errorf("invalid dump at offset %d: %w", start, err)
+		}
+
+		switch {
+		case h == hash:
+			return count, nil
+		case h < hash:
+			lo = start + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// lineAt returns the first line that starts at or after pos together with
+// its offset. line is empty if there is no such line.
+func (d *Dump) lineAt(pos int64) (int64, string, error) {
+	start := pos
+	if pos > 0 {
+		// the line starts after the first newline at or after pos-1.
+		start = pos - 1
+	}
+
+	r := bufio.NewReaderSize(io.NewSectionReader(d.fh, start, d.size-start), 128)
+	if pos > 0 {
+		skipped, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF { //nolint:errorlint
+				return d.size, "", nil
+			}
+
+			return 0, "", fmt.Errorf("failed to read dump: %w", err)
+		}
+
+		start += int64(len(skipped))
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF { //nolint:errorlint
+		return 0, "", fmt.Errorf("failed to read dump: %w", err)
+	}
+
+	return start, strings.TrimSpace(line), nil
+}
@@ -0,0 +1,80 @@
+package hibp
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	counts := make(map[string]uint64, 1000)
+	for i := 0; i < 1000; i++ {
+		counts[Hash(strconv.Itoa(i))] = uint64(i + 1)
+	}
+
+	hashes := make([]string, 0, len(counts))
+	for h := range counts {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+
+	var sb strings.Builder
+	for _, h := range hashes {
+		sb.WriteString(h + ":" + strconv.FormatUint(counts[h], 10) + "\r\n")
+	}
+
+	fn := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(fn, []byte(sb.String()), 0o644))
+
+	d, err := OpenDump(fn)
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, d.Close())
+	}()
+
+	for h, want := range counts {
+		got, err := d.LookupHash(h)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, h)
+	}
+
+	for _, h := range []string{
+		"0000000000000000000000000000000000000000",
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		Hash("not in the dump"),
+	} {
+		got, err := d.LookupHash(h)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), got, h)
+	}
+
+	got, err := d.LookupHash(strings.ToLower(hashes[0]))
+	require.NoError(t, err)
+	assert.Equal(t, counts[hashes[0]], got)
+}
+
+func TestDumpEmpty(t *testing.T) {
+	t.Parallel()
+
+	fn := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(fn, nil, 0o644))
+
+	d, err := OpenDump(fn)
+	require.NoError(t, err)
+
+	got, err := d.LookupHash(Hash("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), got)
+
+	_, err = OpenDump(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+// Package hibp checks passwords against the Have I Been Pwned password
+// database. Passwords never leave this machine: the API is queried with the
+// first five characters of the SHA1 hash only (k-anonymity) and the offline
+// dump is searched locally.
+package hibp
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prefixLen is the length of the hash prefix sent to the range API.
+const prefixLen = 5
+
+// Hash returns the upper case, hex encoded SHA1 hash of a password. This is
+// the format used by the API and the dump files.
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec
+
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// parseLine parses a single "HASH:COUNT" line.
+func parseLine(line string) (string, uint64, error) {
+	line = strings.TrimSpace(line)
+
+	p := strings.IndexByte(line, ':')
+	if p < 0 {
+		return "", 0, fmt.Errorf("invalid line %q", line)
+	}
+
+	count, err := strconv.ParseUint(line[p+1:], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid count in line %q: %w", line, err)
+	}
+
+	return strings.ToUpper(line[:p]), count, nil
+}
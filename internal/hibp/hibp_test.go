@@ -0,0 +1,28 @@
+package hibp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", Hash("password"))
+}
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+
+	h, c, err := parseLine("1e4c9b93f3f0682250b6cf8331b7ee68fd8:3861493\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, "1E4C9B93F3F0682250B6CF8331B7EE68FD8", h)
+	assert.Equal(t, uint64(3861493), c)
+
+	for _, in := range []string{"foo", "foo:bar", "foo:-1"} {
+		_, _, err := parseLine(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,65 @@
+package hook
+
+import (
+	"sort"
+
+	"github.com/kpitt/gopass/pkg/gopass"
+)
+
+// Diff returns the changes from old to sec. old is nil for new secrets.
+// Only key names are recorded, never values.
+func Diff(old, sec gopass.Secret) *Changes {
+	c := &Changes{}
+	if old == nil {
+		c.Created = true
+		c.Added = sortedKeys(sec)
+
+		return c
+	}
+
+	c.Password = old.Password() != sec.Password()
+	c.Body = old.Body() != sec.Body()
+
+	for _, k := range sortedKeys(sec) {
+		ov, found := old.Values(k)
+		if !found {
+			c.Added = append(c.Added, k)
+
+			continue
+		}
+
+		nv, _ := sec.Values(k)
+		if !equal(ov, nv) {
+			c.Changed = append(c.Changed, k)
+		}
+	}
+
+	for _, k := range sortedKeys(old) {
+		if _, found := sec.Values(k); !found {
+			c.Removed = append(c.Removed, k)
+		}
+	}
+
+	return c
+}
+
+func sortedKeys(sec gopass.Secret) []string {
+	keys := append([]string{}, sec.Keys()...)
+	sort.Strings(keys)
+
+	return keys
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
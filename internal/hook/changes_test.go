@@ -0,0 +1,32 @@
+package hook
+
+import (
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	sec, err := secrets.ParseKV([]byte("secret\nuser: foo\nurl: example.com\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, &Changes{
+		Created: true,
+		Added:   []string{"url", "user"},
+	}, Diff(nil, sec))
+
+	old, err := secrets.ParseKV([]byte("old\nuser: bar\nurl: example.com\nnote: baz\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, &Changes{
+		Password: true,
+		Removed:  []string{"note"},
+		Changed:  []string{"user"},
+	}, Diff(old, sec))
+
+	assert.Equal(t, &Changes{}, Diff(sec, sec))
+}
@@ -0,0 +1,31 @@
+package hook
+
+import "context"
+
+type contextKey int
+
+const (
+	ctxKeyConfig contextKey = iota
+)
+
+// WithConfig returns a context with the hook config set.
+func WithConfig(ctx context.Context, cfg *Config) context.Context {
+	return context.WithValue(ctx, ctxKeyConfig, cfg)
+}
+
+// HasConfig returns true if a hook config has been set in this context.
+func HasConfig(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxKeyConfig).(*Config)
+
+	return ok
+}
+
+// GetConfig returns the hook config from the context or nil.
+func GetConfig(ctx context.Context) *Config {
+	cfg, ok := ctx.Value(ctxKeyConfig).(*Config)
+	if !ok {
+		return nil
+	}
+
+	return cfg
+}
@@ -0,0 +1,197 @@
+// Package hook runs user defined commands before and after a store is
+// changed.
+//
+// Every hook is a command line. If it contains template actions it is
+// rendered with the Event first. The command receives the event in its
+// environment (GOPASS_HOOK_*) and as a JSON document on stdin. A pre-hook
+// that exits with a non-zero code aborts the operation.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	shellquote "github.com/kballard/go-shellquote"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/pkg/debug"
+)
+
+// Operations that can be hooked.
+const (
+	OpSet    = "set"
+	OpDelete = "delete"
+	OpMove   = "move"
+	OpSync   = "sync"
+)
+
+// Stages of an operation.
+const (
+	Pre  = "pre"
+	Post = "post"
+)
+
+// Stderr is where the output of all hooks goes. Hooks never write to
+// stdout so they don't interfere with the output of gopass.
+var Stderr io.Writer = os.Stderr
+
+// Config holds the hook commands. Empty commands are not run.
+type Config struct {
+	PreSet     string `yaml:"pre-set,omitempty"`
+	PostSet    string `yaml:"post-set,omitempty"`
+	PreDelete  string `yaml:"pre-delete,omitempty"`
+	PostDelete string `yaml:"post-delete,omitempty"`
+	PreMove    string `yaml:"pre-move,omitempty"`
+	PostMove   string `yaml:"post-move,omitempty"`
+	PreSync    string `yaml:"pre-sync,omitempty"`
+	PostSync   string `yaml:"post-sync,omitempty"`
+	// Changes enables the list of changed keys for set hooks. This requires
+	// decrypting the previous version of the secret.
+	Changes bool `yaml:"changes,omitempty"`
+
+	// Catches all undefined files and must be empty after parsing.
+	XXX map[string]any `yaml:",inline"`
+}
+
+// Command returns the command for the given stage and operation.
+func (c *Config) Command(stage, op string) string {
+	if c == nil {
+		return ""
+	}
+
+	switch stage + "-" + op {
+	case "pre-set":
+		return c.PreSet
+	case "post-set":
+		return c.PostSet
+	case "pre-delete":
+		return c.PreDelete
+	case "post-delete":
+		return c.PostDelete
+	case "pre-move":
+		return c.PreMove
+	case "post-move":
+		return c.PostMove
+	case "pre-sync":
+		return c.PreSync
+	case "post-sync":
+		return c.PostSync
+	default:
+		return ""
+	}
+}
+
+// Event describes a single operation. It never contains any secret values.
+type Event struct {
+	Op      string   `json:"op"`
+	Stage   string   `json:"stage"`
+	Mount   string   `json:"mount"`
+	Name    string   `json:"name,omitempty"`
+	From    string   `json:"from,omitempty"`
+	Changes *Changes `json:"changes,omitempty"`
+}
+
+// Changes lists the keys that are changed by a set operation.
+type Changes struct {
+	Created  bool     `json:"created"`
+	Password bool     `json:"password"`
+	Body     bool     `json:"body"`
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Changed  []string `json:"changed,omitempty"`
+}
+
+// Enabled returns true if any command is configured for this operation.
+// It can be used to skip expensive preparations of the event.
+func Enabled(ctx context.Context, op string) bool {
+	cfg := GetConfig(ctx)
+
+	return cfg.Command(Pre, op) != "" || cfg.Command(Post, op) != ""
+}
+
+// Run runs the hook for the given stage of the event, if any.
+func Run(ctx context.Context, stage string, ev *Event) error {
+	ev.Stage = stage
+
+	cmdline := GetConfig(ctx).Command(stage, ev.Op)
+	if cmdline == "" {
+		return nil
+	}
+
+	args, err := commandArgs(cmdline, ev)
+	if err != nil {
+		return fmt.Errorf("invalid %s-%s hook: %w", stage, ev.Op, err)
+	}
+
+	buf, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook event: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(append(buf, '\n'))
+	cmd.Stdout = Stderr
+	cmd.Stderr = Stderr
+	cmd.Env = append(os.Environ(),
+		"GOPASS_HOOK_OP="+ev.Op,
+		"GOPASS_HOOK_STAGE="+ev.Stage,
+		"GOPASS_HOOK_MOUNT="+ev.Mount,
+		"GOPASS_HOOK_NAME="+ev.Name,
+		"GOPASS_HOOK_FROM="+ev.From,
+	)
+
+	debug.Log("running %s-%s hook: %q", stage, ev.Op, args)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s-%s hook %q failed: %w", stage, ev.Op, args[0], err)
+	}
+
+	return nil
+}
+
+// RunPost runs the post hook of the event. The operation is already done so
+// errors are only reported.
+func RunPost(ctx context.Context, ev *Event) {
+	if err := Run(ctx, Post, ev); err != nil {
+		out.Warningf(ctx, "%s", err)
+	}
+}
+
+// commandArgs renders the command line, if necessary, and splits it into
+// arguments.
+func commandArgs(cmdline string, ev *Event) ([]string, error) {
+	if strings.Contains(cmdline, "{{") {
+		tmpl, err := template.New("hook").Option("missingkey=error").Funcs(template.FuncMap{
+			"quote": func(s string) string {
+				return shellquote.Join(s)
+			},
+		}).Parse(cmdline)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, ev); err != nil {
+			return nil, fmt.Errorf("failed to render template: %w", err)
+		}
+
+		cmdline = buf.String()
+	}
+
+	args, err := shellquote.Split(cmdline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split %q: %w", cmdline, err)
+	}
+
+	if len(args) < 1 {
+		return nil, fmt.Errorf("no command")
+	}
+
+	return args, nil
+}
@@ -0,0 +1,119 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScript(t *testing.T, content string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+
+	fn := filepath.Join(t.TempDir(), "hook.sh")
+	require.NoError(t, os.WriteFile(fn, []byte("#!/bin/sh\n"+content), 0o755))
+
+	return fn
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	var nc *Config
+	assert.Equal(t, "", nc.Command(Pre, OpSet))
+
+	c := &Config{
+		PreSet:   "a",
+		PostMove: "b",
+		PreSync:  "c",
+	}
+	assert.Equal(t, "a", c.Command(Pre, OpSet))
+	assert.Equal(t, "", c.Command(Post, OpSet))
+	assert.Equal(t, "b", c.Command(Post, OpMove))
+	assert.Equal(t, "c", c.Command(Pre, OpSync))
+	assert.Equal(t, "", c.Command(Pre, "foo"))
+}
+
+func TestCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	ev := &Event{Op: OpSet, Name: "foo bar", Mount: "work"}
+
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{
+			in:   "notify --mount work",
+			want: []string{"notify", "--mount", "work"},
+		},
+		{
+			in:   "notify {{ quote .Name }} {{ .Op }}",
+			want: []string{"notify", "foo bar", "set"},
+		},
+	} {
+		args, err := commandArgs(tc.in, ev)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, args, tc.in)
+	}
+
+	_, err := commandArgs("{{ .Foo }}", ev)
+	assert.Error(t, err)
+
+	_, err = commandArgs("  ", ev)
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) { //nolint:paralleltest
+	buf := &bytes.Buffer{}
+	Stderr = buf
+
+	defer func() {
+		Stderr = os.Stderr
+	}()
+
+	ctx := context.Background()
+	ev := &Event{Op: OpMove, Mount: "work", Name: "foo", From: "bar"}
+
+	// no config
+	assert.NoError(t, Run(ctx, Pre, ev))
+	assert.False(t, Enabled(ctx, OpMove))
+
+	ok := writeScript(t, `echo "$GOPASS_HOOK_STAGE $GOPASS_HOOK_OP $GOPASS_HOOK_MOUNT $GOPASS_HOOK_FROM $GOPASS_HOOK_NAME"
+cat
+`)
+	fail := writeScript(t, "echo denied\nexit 3\n")
+
+	ctx = WithConfig(ctx, &Config{
+		PreMove:  fail,
+		PostMove: ok,
+	})
+	assert.True(t, Enabled(ctx, OpMove))
+	assert.False(t, Enabled(ctx, OpSet))
+
+	t.Run("failing hook", func(t *testing.T) { //nolint:paralleltest
+		buf.Reset()
+
+		err := Run(ctx, Pre, ev)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-move hook")
+		assert.Equal(t, "denied\n", buf.String())
+	})
+
+	t.Run("event", func(t *testing.T) { //nolint:paralleltest
+		buf.Reset()
+
+		require.NoError(t, Run(ctx, Post, ev))
+		assert.Equal(t, "post move work bar foo\n"+
+			`{"op":"move","stage":"post","mount":"work","name":"foo","from":"bar"}`+"\n", buf.String())
+	})
+}
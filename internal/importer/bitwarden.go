@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+func init() {
+	Register("bitwarden", readBitwarden, false, ".json")
+}
+
+// Bitwarden item types.
+const (
+	bwLogin    = 1
+	bwNote     = 2
+	bwCard     = 3
+	bwIdentity = 4
+)
+
+type bwExport struct {
+	Encrypted   bool       `json:"encrypted"`
+	Folders     []bwFolder `json:"folders"`
+	Collections []bwFolder `json:"collections"`
+	Items       []bwItem   `json:"items"`
+}
+
+type bwFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type bwItem struct {
+	Type          int            `json:"type"`
+	Name          string         `json:"name"`
+	Notes         string         `json:"notes"`
+	FolderID      string         `json:"folderId"`
+	CollectionIDs []string       `json:"collectionIds"`
+	Fields        []bwField      `json:"fields"`
+	Login         *bwLoginData   `json:"login"`
+	Card          map[string]any `json:"card"`
+	Identity      map[string]any `json:"identity"`
+}
+
+type bwField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type bwLoginData struct {
+	Username string  `json:"username"`
+	Password string  `json:"password"`
+	TOTP     string  `json:"totp"`
+	URIs     []bwURI `json:"uris"`
+}
+
+type bwURI struct {
+	URI string `json:"uri"`
+}
+
+// readBitwarden reads an unencrypted Bitwarden JSON export. Bitwarden
+// exports don't contain attachments.
+func readBitwarden(r io.Reader, _ Options) ([]Entry, error) {
+	var ex bwExport
+	if err := json.NewDecoder(r).Decode(&ex); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitwarden export: %w", err)
+	}
+
+	if ex.Encrypted {
+		return nil, fmt.Errorf("encrypted Bitwarden exports are not supported. Please export in the unencrypted JSON format")
+	}
+
+	folders := make(map[string]string, len(ex.Folders)+len(ex.Collections))
+	for _, f := range append(ex.Folders, ex.Collections...) {
+		folders[f.ID] = f.Name
+	}
+
+	entries := make([]Entry, 0, len(ex.Items))
+	for _, it := range ex.Items {
+		e := Entry{
+			Title: it.Name,
+			Notes: it.Notes,
+		}
+
+		folder := folders[it.FolderID]
+		if folder == "" && len(it.CollectionIDs) > 0 {
+			folder = folders[it.CollectionIDs[0]]
+		}
+		if folder != "" {
+			// nested folders are separated by a slash.
+			e.Folder = strings.Split(folder, "/")
+		}
+
+		switch it.Type {
+		case bwLogin:
+			if it.Login != nil {
+				e.Username = it.Login.Username
+				e.Password = it.Login.Password
+				e.OTP = it.Login.TOTP
+				for i, u := range it.Login.URIs {
+					if i == 0 {
+						e.URL = u.URI
+
+						continue
+					}
+					e.Fields = append(e.Fields, Field{Key: "url", Value: u.URI})
+				}
+			}
+		case bwCard:
+			e.Fields = append(e.Fields, bwFlatten(it.Card)...)
+		case bwIdentity:
+			e.Fields = append(e.Fields, bwFlatten(it.Identity)...)
+		}
+
+		for _, f := range it.Fields {
+			e.Fields = append(e.Fields, Field{Key: f.Name, Value: f.Value})
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// bwFlatten returns all non-empty string values of a card or identity in
+// a stable order.
+func bwFlatten(m map[string]any) []Field {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(m))
+	for _, k := range keys {
+		if v, ok := m[k].(string); ok && v != "" {
+			fields = append(fields, Field{Key: k, Value: v})
+		}
+	}
+
+	return fields
+}
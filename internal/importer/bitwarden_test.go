@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bwTestExport = `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Social/Chat"}],
+  "items": [
+    {
+      "type": 1,
+      "name": "Example",
+      "notes": "a note",
+      "folderId": "f1",
+      "fields": [{"name": "PIN", "value": "1234", "type": 1}],
+      "login": {
+        "uris": [{"match": null, "uri": "https://example.org"}, {"match": null, "uri": "https://example.com"}],
+        "username": "alice",
+        "password": "s3cr3t",
+        "totp": "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP"
+      }
+    },
+    {
+      "type": 3,
+      "name": "Visa",
+      "folderId": null,
+      "card": {"cardholderName": "Alice", "brand": "Visa", "number": "4111111111111111", "code": "123", "expMonth": null}
+    }
+  ]
+}`
+
+func TestBitwarden(t *testing.T) {
+	t.Parallel()
+
+	entries, err := readBitwarden(strings.NewReader(bwTestExport), Options{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	e := entries[0]
+	assert.Equal(t, []string{"Social", "Chat"}, e.Folder)
+	assert.Equal(t, "Example", e.Title)
+	assert.Equal(t, "alice", e.Username)
+	assert.Equal(t, "s3cr3t", e.Password)
+	assert.Equal(t, "https://example.org", e.URL)
+	assert.Equal(t, "a note", e.Notes)
+	assert.Equal(t, "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP", e.OTP)
+	assert.Equal(t, []Field{{Key: "url", Value: "https://example.com"}, {Key: "PIN", Value: "1234"}}, e.Fields)
+
+	assert.Equal(t, []Field{
+		{Key: "brand", Value: "Visa"},
+		{Key: "cardholderName", Value: "Alice"},
+		{Key: "code", Value: "123"},
+		{Key: "number", Value: "4111111111111111"},
+	}, entries[1].Fields)
+
+	_, err = readBitwarden(strings.NewReader(`{"encrypted": true}`), Options{})
+	assert.Error(t, err)
+}
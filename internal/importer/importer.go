@@ -0,0 +1,274 @@
+// Package importer reads the exports of other password managers and maps
+// their entries to gopass secrets.
+//
+// Each supported format registers a Reader. Readers return a flat list of
+// entries which are converted to secrets by Plan. Plan also decides what to
+// do if a secret already exists.
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+)
+
+// Entry is a single entry read from an export.
+type Entry struct {
+	// Folder is the path of the entry, e.g. the KeePass group.
+	Folder   []string
+	Title    string
+	Username string
+	Password string
+	URL      string
+	Notes    string
+	// OTP is either an otpauth:// URL or a base32 encoded TOTP secret.
+	OTP         string
+	Fields      []Field
+	Attachments []Attachment
+}
+
+// Field is any other field of an entry. Keys may repeat.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Attachment is a file attached to an entry.
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// Options are passed to all readers. Readers ignore options they don't
+// support.
+type Options struct {
+	// Password unlocks encrypted exports.
+	Password string
+	// KeyFile holds the content of a KeePass key file.
+	KeyFile []byte
+}
+
+// Reader parses an export.
+type Reader func(r io.Reader, opts Options) ([]Entry, error)
+
+type format struct {
+	reader     Reader
+	extensions []string
+	password   bool
+}
+
+var formats = map[string]format{}
+
+// Register adds a new format. Files with one of the extensions are
+// detected as this format. Formats that need a password to be read set
+// password.
+func Register(name string, r Reader, password bool, extensions ...string) {
+	formats[name] = format{
+		reader:     r,
+		extensions: extensions,
+		password:   password,
+	}
+}
+
+// Formats returns the names of all registered formats.
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the reader for a format.
+func Get(name string) (Reader, error) {
+	f, found := formats[name]
+	if !found {
+		return nil, fmt.Errorf("unknown format %q. Supported formats: %s", name, strings.Join(Formats(), ", "))
+	}
+
+	return f.reader, nil
+}
+
+// NeedsPassword returns true if the format needs a password.
+func NeedsPassword(name string) bool {
+	return formats[name].password
+}
+
+// Detect guesses the format from the file name.
+func Detect(filename string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, name := range Formats() {
+		for _, e := range formats[name].extensions {
+			if e == ext {
+				return name, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("can not detect the format of %q. Please use --format", filename)
+}
+
+// Secret converts the entry to a secret. Entries with multi-line fields
+// are stored as YAML secrets, all others as KV secrets.
+func (e Entry) Secret() gopass.Secret { //nolint:ireturn
+	fields := make([]Field, 0, len(e.Fields)+3)
+	if e.Username != "" {
+		fields = append(fields, Field{Key: "username", Value: e.Username})
+	}
+	if e.URL != "" {
+		fields = append(fields, Field{Key: "url", Value: e.URL})
+	}
+	if otp := otpURL(e.OTP, e.Title, e.Username); otp != "" {
+		// pkg/otp expects the value of the otpauth key without the
+		// scheme, like in a line "otpauth://totp/...".
+		fields = append(fields, Field{Key: "otpauth", Value: strings.TrimPrefix(otp, "otpauth:")})
+	}
+	for _, f := range e.Fields {
+		if key := fieldKey(f.Key); key != "" && f.Value != "" {
+			fields = append(fields, Field{Key: key, Value: f.Value})
+		}
+	}
+
+	multiline := false
+	for _, f := range fields {
+		if strings.Contains(f.Value, "\n") {
+			multiline = true
+		}
+	}
+
+	if multiline {
+		return yamlSecret(e, fields)
+	}
+
+	sec := secrets.NewKV()
+	sec.SetPassword(e.Password)
+	for _, f := range fields {
+		_ = sec.Add(f.Key, f.Value)
+	}
+	if e.Notes != "" {
+		_, _ = sec.Write([]byte(e.Notes))
+	}
+
+	return sec
+}
+
+func yamlSecret(e Entry, fields []Field) gopass.Secret { //nolint:ireturn
+	values := make(map[string][]string, len(fields))
+	for _, f := range fields {
+		values[f.Key] = append(values[f.Key], f.Value)
+	}
+
+	sec := &secrets.YAML{}
+	sec.SetPassword(e.Password)
+	for k, v := range values {
+		if len(v) == 1 {
+			_ = sec.Set(k, v[0])
+
+			continue
+		}
+		_ = sec.Set(k, v)
+	}
+	if e.Notes != "" {
+		notes := e.Notes
+		if !strings.HasSuffix(notes, "\n") {
+			notes += "\n"
+		}
+		_, _ = sec.Write([]byte(notes))
+	}
+
+	return sec
+}
+
+// binarySecret stores data in the same format as gopass fscopy.
+func binarySecret(filename string, data []byte) gopass.Secret { //nolint:ireturn
+	sec := secrets.NewKV()
+	if err := sec.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename)); err != nil {
+		debug.Log("Failed to set Content-Disposition: %q", err)
+	}
+
+	_, _ = sec.Write([]byte(base64.StdEncoding.EncodeToString(data)))
+	if err := sec.Set("Content-Transfer-Encoding", "Base64"); err != nil {
+		debug.Log("Failed to set Content-Transfer-Encoding: %q", err)
+	}
+
+	return sec
+}
+
+// otpURL turns a TOTP secret into an otpauth URL. URLs are returned as is.
+func otpURL(otp, title, username string) string {
+	otp = strings.TrimSpace(otp)
+	if otp == "" || strings.HasPrefix(otp, "otpauth://") {
+		return otp
+	}
+
+	label := title
+	if username != "" {
+		label += ":" + username
+	}
+
+	v := url.Values{}
+	v.Set("secret", strings.ToUpper(strings.ReplaceAll(otp, " ", "")))
+	if title != "" {
+		v.Set("issuer", title)
+	}
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", url.PathEscape(label), v.Encode())
+}
+
+// fieldKey normalizes a field name for use as a key. Colons would break
+// the KV format.
+func fieldKey(key string) string {
+	key = strings.TrimSpace(strings.ReplaceAll(key, ":", ""))
+
+	return strings.ToLower(key)
+}
+
+// cleanName makes a single path component safe to use in a secret name.
+func cleanName(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "/", "-")
+	s = strings.ReplaceAll(s, "\\", "-")
+	s = strings.TrimLeft(s, ".")
+
+	return s
+}
+
+// name returns the secret name of the entry below prefix.
+func (e Entry) name(prefix string) string {
+	parts := []string{prefix}
+	for _, f := range e.Folder {
+		if c := cleanName(f); c != "" {
+			parts = append(parts, c)
+		}
+	}
+
+	title := cleanName(e.Title)
+	if title == "" {
+		title = cleanName(hostname(e.URL))
+	}
+	if title == "" {
+		title = "untitled"
+	}
+
+	return path.Join(append(parts, title)...)
+}
+
+func hostname(u string) string {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+
+	return pu.Hostname()
+}
@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/pkg/otp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntrySecret(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{
+		Title:    "example.org",
+		Username: "alice",
+		Password: "s3cr3t",
+		URL:      "https://example.org",
+		Notes:    "some notes",
+		OTP:      "JBSW Y3DP EHPK 3PXP",
+		Fields:   []Field{{Key: "PIN: code", Value: "1234"}, {Key: "empty"}},
+	}
+
+	sec := e.Secret()
+	assert.Equal(t, "s3cr3t", sec.Password())
+	assert.Equal(t, "some notes", sec.Body())
+	v, _ := sec.Get("username")
+	assert.Equal(t, "alice", v)
+	v, _ = sec.Get("pin code")
+	assert.Equal(t, "1234", v)
+	_, found := sec.Get("empty")
+	assert.False(t, found)
+
+	// the secret must survive a round trip through the store.
+	parsed, err := secrets.ParseKV(sec.Bytes())
+	require.NoError(t, err)
+
+	key, err := otp.Calculate("example.org", parsed)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", key.Secret())
+	assert.Equal(t, "example.org", key.Issuer())
+}
+
+func TestEntrySecretYAML(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{
+		Title:    "ssh",
+		Password: "foo",
+		Fields:   []Field{{Key: "key", Value: "line1\nline2"}},
+	}
+
+	sec := e.Secret()
+	_, ok := sec.(*secrets.YAML)
+	require.True(t, ok)
+
+	parsed, err := secrets.ParseYAML(sec.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "foo", parsed.Password())
+	v, _ := parsed.Get("key")
+	assert.Equal(t, "line1\nline2", v)
+}
+
+func TestEntryName(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		entry  Entry
+		prefix string
+		want   string
+	}{
+		{Entry{Title: "foo"}, "", "foo"},
+		{Entry{Title: "foo/bar", Folder: []string{"Internet", " "}}, "imported", "imported/Internet/foo-bar"},
+		{Entry{URL: "https://www.example.org/login"}, "", "www.example.org"},
+		{Entry{Title: ".."}, "", "untitled"},
+	} {
+		assert.Equal(t, tc.want, tc.entry.name(tc.prefix))
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	for file, want := range map[string]string{
+		"export.kdbx": "keepass",
+		"bw.JSON":     "bitwarden",
+		"1p.csv":      "1password",
+	} {
+		got, err := Detect(file)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := Detect("foo.txt")
+	assert.Error(t, err)
+
+	_, err = Get("lastpass")
+	assert.Error(t, err)
+	assert.True(t, NeedsPassword("keepass"))
+	assert.False(t, NeedsPassword("bitwarden"))
+}
@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/kdbx"
+)
+
+func init() {
+	Register("keepass", readKeePass, true, ".kdbx")
+}
+
+// kpOTPFields are the fields different KeePass clients use to store TOTP
+// secrets, in order of preference.
+var kpOTPFields = []string{
+	"otp",                   // KeePassXC, otpauth:// URL
+	"TimeOtp-Secret-Base32", // KeePass 2.47+
+	"TOTP Seed",             // KeeTrayTOTP and older KeePassXC versions
+}
+
+// kpIgnoredFields are not imported as additional fields.
+var kpIgnoredFields = map[string]bool{
+	"TOTP Settings":     true,
+	"TimeOtp-Length":    true,
+	"TimeOtp-Period":    true,
+	"TimeOtp-Algorithm": true,
+}
+
+// readKeePass reads a KeePass KDBX 4 database. The name of the root group
+// is not part of the secret names.
+func readKeePass(r io.Reader, opts Options) ([]Entry, error) {
+	db, err := kdbx.Decode(r, kdbx.Credentials{
+		Password: opts.Password,
+		KeyFile:  opts.KeyFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KeePass database: %w", err)
+	}
+
+	if db.Root == nil {
+		return nil, nil
+	}
+
+	return kpWalk(db.Root, nil), nil
+}
+
+func kpWalk(g *kdbx.Group, folder []string) []Entry {
+	var entries []Entry
+
+	for _, ke := range g.Entries {
+		e := Entry{
+			Folder:   folder,
+			Title:    ke.Get(kdbx.FieldTitle),
+			Username: ke.Get(kdbx.FieldUserName),
+			Password: ke.Get(kdbx.FieldPassword),
+			URL:      ke.Get(kdbx.FieldURL),
+			Notes:    ke.Get(kdbx.FieldNotes),
+		}
+
+		otpField := ""
+		for _, k := range kpOTPFields {
+			if v := strings.TrimSpace(ke.Get(k)); v != "" {
+				e.OTP = v
+				otpField = k
+
+				break
+			}
+		}
+
+		for _, f := range ke.Fields {
+			switch f.Key {
+			case kdbx.FieldTitle, kdbx.FieldUserName, kdbx.FieldPassword, kdbx.FieldURL, kdbx.FieldNotes, otpField:
+				continue
+			}
+			if kpIgnoredFields[f.Key] {
+				continue
+			}
+			e.Fields = append(e.Fields, Field{Key: f.Key, Value: f.Value})
+		}
+
+		for _, a := range ke.Attachments {
+			e.Attachments = append(e.Attachments, Attachment{Name: a.Name, Data: a.Data})
+		}
+
+		entries = append(entries, e)
+	}
+
+	for _, sg := range g.Groups {
+		sub := append(append([]string{}, folder...), sg.Name)
+		entries = append(entries, kpWalk(sg, sub)...)
+	}
+
+	return entries
+}
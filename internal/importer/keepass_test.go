@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/kdbx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeePass(t *testing.T) {
+	t.Parallel()
+
+	db := &kdbx.Database{
+		Name: "test",
+		Root: &kdbx.Group{
+			Name: "test",
+			Groups: []*kdbx.Group{
+				{
+					Name: "Internet",
+					Entries: []*kdbx.Entry{
+						{
+							Fields: []kdbx.Field{
+								{Key: kdbx.FieldTitle, Value: "Example"},
+								{Key: kdbx.FieldUserName, Value: "alice"},
+								{Key: kdbx.FieldPassword, Value: "s3cr3t"},
+								{Key: kdbx.FieldURL, Value: "https://example.org"},
+								{Key: kdbx.FieldNotes, Value: "a note"},
+								{Key: "TOTP Seed", Value: "JBSWY3DPEHPK3PXP"},
+								{Key: "TOTP Settings", Value: "30;6"},
+								{Key: "PIN", Value: "1234"},
+							},
+							Attachments: []kdbx.Attachment{{Name: "id_rsa", Data: []byte("key")}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, kdbx.Encode(buf, db, kdbx.Credentials{Password: "foo"}))
+
+	_, err := readKeePass(bytes.NewReader(buf.Bytes()), Options{Password: "bar"})
+	assert.Error(t, err)
+
+	entries, err := readKeePass(bytes.NewReader(buf.Bytes()), Options{Password: "foo"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	assert.Equal(t, Entry{
+		Folder:      []string{"Internet"},
+		Title:       "Example",
+		Username:    "alice",
+		Password:    "s3cr3t",
+		URL:         "https://example.org",
+		Notes:       "a note",
+		OTP:         "JBSWY3DPEHPK3PXP",
+		Fields:      []Field{{Key: "PIN", Value: "1234"}},
+		Attachments: []Attachment{{Name: "id_rsa", Data: []byte("key")}},
+	}, entries[0])
+}
@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	Register("1password", read1Password, false, ".csv")
+}
+
+// opColumns maps the column headers used by different 1Password versions
+// to entry fields. All other columns are imported as additional fields.
+var opColumns = map[string]string{
+	"title":             "title",
+	"name":              "title",
+	"url":               "url",
+	"urls":              "url",
+	"website":           "url",
+	"login url":         "url",
+	"username":          "username",
+	"login username":    "username",
+	"password":          "password",
+	"login password":    "password",
+	"otpauth":           "otp",
+	"one-time password": "otp",
+	"notes":             "notes",
+	"notesplain":        "notes",
+	"vault":             "folder",
+	"favorite":          "",
+	"archived":          "",
+	"uuid":              "",
+}
+
+// read1Password reads a 1Password CSV export. The first row must contain
+// the column headers.
+func read1Password(r io.Reader, _ Options) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read 1Password CSV header: %w", err)
+	}
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+
+	var entries []Entry
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read 1Password CSV: %w", err)
+		}
+
+		e := Entry{}
+		for i, v := range rec {
+			if i >= len(header) || v == "" {
+				continue
+			}
+
+			col, known := opColumns[strings.ToLower(header[i])]
+			switch {
+			case !known:
+				e.Fields = append(e.Fields, Field{Key: header[i], Value: v})
+			case col == "title":
+				e.Title = v
+			case col == "url":
+				e.URL = v
+			case col == "username":
+				e.Username = v
+			case col == "password":
+				e.Password = v
+			case col == "otp":
+				e.OTP = v
+			case col == "notes":
+				e.Notes = v
+			case col == "folder":
+				e.Folder = []string{v}
+			}
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
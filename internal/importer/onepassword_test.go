@@ -0,0 +1,32 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test1Password(t *testing.T) {
+	t.Parallel()
+
+	in := "\ufeffTitle,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+		"Example,https://example.org,alice,s3cr3t,otpauth://totp/x?secret=JBSWY3DPEHPK3PXP,true,false,work,\"multi\nline\"\n" +
+		"Empty,,,,,false,false,,\n"
+
+	entries, err := read1Password(strings.NewReader(in), Options{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	e := entries[0]
+	assert.Equal(t, "Example", e.Title)
+	assert.Equal(t, "https://example.org", e.URL)
+	assert.Equal(t, "alice", e.Username)
+	assert.Equal(t, "s3cr3t", e.Password)
+	assert.Equal(t, "otpauth://totp/x?secret=JBSWY3DPEHPK3PXP", e.OTP)
+	assert.Equal(t, "multi\nline", e.Notes)
+	assert.Equal(t, []Field{{Key: "Tags", Value: "work"}}, e.Fields)
+
+	assert.Equal(t, Entry{Title: "Empty"}, entries[1])
+}
@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/gopass"
+)
+
+// Policy decides what happens if a secret already exists.
+type Policy string
+
+// Supported conflict policies.
+const (
+	// PolicySkip keeps the existing secret.
+	PolicySkip Policy = "skip"
+	// PolicyOverwrite replaces the existing secret.
+	PolicyOverwrite Policy = "overwrite"
+	// PolicySuffix imports the entry under a new name with a numeric
+	// suffix.
+	PolicySuffix Policy = "suffix"
+)
+
+// ParsePolicy validates a policy name. The empty string selects
+// PolicySkip.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(s)); p {
+	case "":
+		return PolicySkip, nil
+	case PolicySkip, PolicyOverwrite, PolicySuffix:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q. Use skip, overwrite or suffix", s)
+	}
+}
+
+// Op is the operation planned for a single secret.
+type Op string
+
+// Planned operations.
+const (
+	OpCreate    Op = "create"
+	OpOverwrite Op = "overwrite"
+	OpSkip      Op = "skip"
+	OpRename    Op = "rename"
+)
+
+// Item is a single secret that will be written (or skipped).
+type Item struct {
+	// Name is the name the secret will be written to.
+	Name string
+	// Original is the name before resolving a conflict.
+	Original string
+	Op       Op
+	Secret   gopass.Secret
+}
+
+// Plan assigns names to all entries and their attachments and resolves
+// conflicts with existing secrets according to the policy. Entries that
+// map to the same name within one import never overwrite each other,
+// they are always renamed. Attachments are stored next to their entry as
+// <entry>/<filename>.
+func Plan(entries []Entry, prefix string, policy Policy, exists func(string) bool) []Item {
+	p := planner{
+		policy:  policy,
+		exists:  exists,
+		planned: make(map[string]bool, len(entries)),
+	}
+
+	items := make([]Item, 0, len(entries))
+	for _, e := range entries {
+		it := p.add(e.name(prefix), e.Secret())
+		items = append(items, it)
+
+		if it.Op == OpSkip {
+			continue
+		}
+
+		for _, a := range e.Attachments {
+			fn := cleanName(a.Name)
+			if fn == "" {
+				fn = "attachment"
+			}
+			items = append(items, p.add(path.Join(it.Name, fn), binarySecret(a.Name, a.Data)))
+		}
+	}
+
+	return items
+}
+
+type planner struct {
+	policy  Policy
+	exists  func(string) bool
+	planned map[string]bool
+}
+
+func (p *planner) add(name string, sec gopass.Secret) Item {
+	it := Item{
+		Name:     name,
+		Original: name,
+		Op:       OpCreate,
+		Secret:   sec,
+	}
+
+	switch {
+	case p.planned[name]:
+		it.Name = p.suffix(name)
+		it.Op = OpRename
+	case p.exists(name):
+		switch p.policy {
+		case PolicyOverwrite:
+			it.Op = OpOverwrite
+		case PolicySuffix:
+			it.Name = p.suffix(name)
+			it.Op = OpRename
+		default:
+			it.Op = OpSkip
+		}
+	}
+
+	if it.Op != OpSkip {
+		p.planned[it.Name] = true
+	}
+
+	return it
+}
+
+func (p *planner) suffix(name string) string {
+	for i := 1; ; i++ {
+		cand := fmt.Sprintf("%s-%d", name, i)
+		if !p.planned[cand] && !p.exists(cand) {
+			return cand
+		}
+	}
+}
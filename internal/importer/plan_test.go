@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	existing := map[string]bool{"foo": true, "foo-1": true}
+	exists := func(name string) bool {
+		return existing[name]
+	}
+
+	entries := []Entry{
+		{Title: "foo", Attachments: []Attachment{{Name: "id_rsa", Data: []byte("key")}}},
+		{Title: "bar"},
+		{Title: "bar"},
+	}
+
+	ops := func(items []Item) []string {
+		out := make([]string, 0, len(items))
+		for _, it := range items {
+			out = append(out, string(it.Op)+" "+it.Name)
+		}
+
+		return out
+	}
+
+	assert.Equal(t, []string{"skip foo", "create bar", "rename bar-1"}, ops(Plan(entries, "", PolicySkip, exists)))
+	assert.Equal(t, []string{"overwrite foo", "create foo/id_rsa", "create bar", "rename bar-1"}, ops(Plan(entries, "", PolicyOverwrite, exists)))
+	assert.Equal(t, []string{"rename foo-2", "create foo-2/id_rsa", "create bar", "rename bar-1"}, ops(Plan(entries, "", PolicySuffix, exists)))
+
+	items := Plan(entries, "", PolicySuffix, exists)
+	assert.Equal(t, "foo", items[0].Original)
+	v, _ := items[1].Secret.Get("content-transfer-encoding")
+	assert.Equal(t, "Base64", v)
+}
+
+func TestParsePolicy(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParsePolicy("Overwrite")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyOverwrite, p)
+
+	_, err = ParsePolicy("merge")
+	assert.Error(t, err)
+}
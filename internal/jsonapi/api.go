@@ -0,0 +1,263 @@
+// Package jsonapi implements a native messaging host for browser extensions.
+// The browser starts the host and exchanges length prefixed JSON messages
+// with it over stdin and stdout, see
+// https://developer.mozilla.org/en-US/docs/Mozilla/Add-ons/WebExtensions/Native_messaging.
+//
+// Every request has a "type" and the parameters of that type:
+//
+//	query    {"query": "https://login.example.com/"} -> ["websites/example.com/alice"]
+//	getLogin {"entry": "websites/example.com/alice"} -> {"username": "alice", "password": "..."}
+//	getData  {"entry": "websites/example.com/alice"} -> {"url": "...", "username": "alice"}
+//	create   {"entry_name": "...", "login": "alice", "password": "", "length": 24, "generate": true} -> {"username": "alice", "password": "..."}
+//
+// Errors are returned as {"error": "..."}.
+package jsonapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/pkg/pwgen"
+)
+
+// DefaultLength is the length of generated passwords if the request does not
+// specify one.
+const DefaultLength = 24
+
+// usernameKeys are the keys that may hold the username, in order of
+// preference.
+var usernameKeys = []string{"login", "username", "user"}
+
+// API answers the requests of a browser extension.
+type API struct {
+	// PathOnly disables matching secrets by the host of their url key. That
+	// decrypts every secret that does not match by name on each query.
+	PathOnly bool
+
+	store gopass.Store
+	r     io.Reader
+	w     io.Writer
+}
+
+// New creates a new API reading requests from r and writing responses to w.
+func New(store gopass.Store, r io.Reader, w io.Writer) *API {
+	return &API{
+		store: store,
+		r:     r,
+		w:     w,
+	}
+}
+
+// Serve answers requests until the browser closes the connection or the
+// context is canceled. Failed requests are answered with an error response
+// and do not stop the loop.
+func (a *API) Serve(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		msg, err := readMessage(a.r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := a.handle(ctx, msg)
+		if err != nil {
+			debug.Log("request failed: %s", err)
+			resp = errorResponse{Error: err.Error()}
+		}
+
+		if err := writeMessage(a.w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (a *API) handle(ctx context.Context, msg []byte) (any, error) {
+	var mt messageType
+	if err := json.Unmarshal(msg, &mt); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	debug.Log("received %s request", mt.Type)
+
+	switch mt.Type {
+	case "query":
+		var req queryMessage
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+
+		return a.query(ctx, req.Query)
+	case "getLogin":
+		var req entryMessage
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+
+		return a.getLogin(ctx, req.Entry)
+	case "getData":
+		var req entryMessage
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+
+		return a.getData(ctx, req.Entry)
+	case "create":
+		var req createMessage
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+
+		return a.create(ctx, req)
+	default:
+		return nil, fmt.Errorf("unknown request type %q", mt.Type)
+	}
+}
+
+// query returns the names of all secrets matching the host of the page.
+// A secret matches if any component of its name is the host, one of its
+// parent domains or one of their known aliases. Unless PathOnly is set the
+// host of the url key is checked as well.
+func (a *API) query(ctx context.Context, query string) ([]string, error) {
+	host := parseHost(query)
+	if host == "" {
+		return nil, fmt.Errorf("invalid query %q", query)
+	}
+
+	want := domains(host)
+
+	names, err := a.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	matches := make([]string, 0, 4)
+	for _, name := range names {
+		if matchPath(name, want) {
+			matches = append(matches, name)
+
+			continue
+		}
+
+		if a.PathOnly {
+			continue
+		}
+
+		sec, err := a.store.Get(ctx, name, "latest")
+		if err != nil {
+			debug.Log("failed to decrypt %s: %s", name, err)
+
+			continue
+		}
+
+		if u, found := sec.Get("url"); found && want[parseHost(u)] {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+func (a *API) getLogin(ctx context.Context, name string) (*loginResponse, error) {
+	sec, err := a.store.Get(ctx, name, "latest")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+
+	return &loginResponse{
+		Username: username(name, sec),
+		Password: sec.Password(),
+	}, nil
+}
+
+// username returns the value of the first username key or the last component
+// of the name.
+func username(name string, sec gopass.Secret) string {
+	for _, k := range usernameKeys {
+		if v, found := sec.Get(k); found {
+			return v
+		}
+	}
+
+	return path.Base(name)
+}
+
+// getData returns all keys of a secret except the password.
+func (a *API) getData(ctx context.Context, name string) (map[string]string, error) {
+	sec, err := a.store.Get(ctx, name, "latest")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+
+	data := make(map[string]string, len(sec.Keys()))
+	for _, k := range sec.Keys() {
+		if v, found := sec.Get(k); found {
+			data[k] = v
+		}
+	}
+	delete(data, "password")
+
+	return data, nil
+}
+
+// create adds a new login. Generated passwords follow the password rules of
+// the domain in the name of the secret, if known.
+func (a *API) create(ctx context.Context, req createMessage) (*loginResponse, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("entry_name is required")
+	}
+
+	names, err := a.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, name := range names {
+		if name == req.Name {
+			return nil, fmt.Errorf("secret %s already exists", req.Name)
+		}
+	}
+
+	pw := req.Password
+	if req.Generate {
+		length := req.Length
+		if length < 1 {
+			length = DefaultLength
+		}
+		pw = pwgen.NewCrypticForDomain(length, hostOf(req.Name)).Password()
+	}
+	if pw == "" {
+		return nil, fmt.Errorf("password is required unless generate is set")
+	}
+
+	sec := secrets.NewKV()
+	sec.SetPassword(pw)
+	if req.Login != "" {
+		if err := sec.Set("username", req.Login); err != nil {
+			return nil, fmt.Errorf("failed to set login: %w", err)
+		}
+	}
+
+	if err := a.store.Set(ctxutil.WithCommitMessage(ctx, "Created by browser extension"), req.Name, sec); err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", req.Name, err)
+	}
+
+	return &loginResponse{
+		Username: req.Login,
+		Password: pw,
+	}, nil
+}
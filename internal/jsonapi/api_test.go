@@ -0,0 +1,139 @@
+package jsonapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/gopass/apimock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeMessage(buf, map[string]string{"type": "query"}))
+	assert.Equal(t, []byte{16, 0, 0, 0}, buf.Bytes()[:4])
+
+	msg, err := readMessage(buf)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"query"}`, string(msg))
+
+	_, err = readMessage(buf)
+	assert.ErrorIs(t, err, io.EOF)
+
+	_, err = readMessage(bytes.NewReader([]byte{0, 0, 0, 1}))
+	assert.Error(t, err, "too large")
+
+	_, err = readMessage(bytes.NewReader([]byte{4, 0, 0, 0, '{'}))
+	assert.Error(t, err, "truncated")
+}
+
+// roundtrip sends the requests and returns the decoded responses.
+func roundtrip(t *testing.T, a *API, in *bytes.Buffer, out *bytes.Buffer, reqs ...any) []json.RawMessage {
+	t.Helper()
+
+	for _, req := range reqs {
+		require.NoError(t, writeMessage(in, req))
+	}
+	require.NoError(t, a.Serve(context.Background()))
+
+	resps := make([]json.RawMessage, 0, len(reqs))
+	for {
+		msg, err := readMessage(out)
+		if err != nil {
+			break
+		}
+		resps = append(resps, msg)
+	}
+	require.Len(t, resps, len(reqs))
+
+	return resps
+}
+
+func TestAPI(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := apimock.New()
+	for name, content := range map[string]string{
+		"websites/apple.com/alice":  "s3cr3t\nuser: alice@example.com\nurl: https://apple.com",
+		"websites/example.org/bob":  "hunter2",
+		"work/sso":                  "t0ken\nlogin: carol\nurl: https://icloud.com/",
+		"websites/unrelated.com/xx": "nope",
+	} {
+		require.NoError(t, store.Set(ctx, name, &apimock.Secret{Buf: []byte(content)}))
+	}
+
+	in := &bytes.Buffer{}
+	out := &bytes.Buffer{}
+	a := New(store, in, out)
+
+	resps := roundtrip(t, a, in, out,
+		map[string]string{"type": "query", "query": "https://www.icloud.com/mail"},
+		map[string]string{"type": "query", "query": "example.org"},
+		map[string]string{"type": "getLogin", "entry": "websites/apple.com/alice"},
+		map[string]string{"type": "getLogin", "entry": "websites/example.org/bob"},
+		map[string]string{"type": "getData", "entry": "work/sso"},
+		map[string]string{"type": "getLogin", "entry": "missing"},
+		map[string]string{"type": "unknown"},
+	)
+
+	assert.JSONEq(t, `["websites/apple.com/alice","work/sso"]`, string(resps[0]))
+	assert.JSONEq(t, `["websites/example.org/bob"]`, string(resps[1]))
+	assert.JSONEq(t, `{"username":"alice@example.com","password":"s3cr3t"}`, string(resps[2]))
+	assert.JSONEq(t, `{"username":"bob","password":"hunter2"}`, string(resps[3]))
+	assert.JSONEq(t, `{"login":"carol","url":"https://icloud.com/"}`, string(resps[4]))
+	assert.Contains(t, string(resps[5]), `"error"`)
+	assert.Contains(t, string(resps[6]), `unknown request type`)
+
+	// the url keys are not checked in path only mode.
+	a.PathOnly = true
+	resps = roundtrip(t, a, in, out,
+		map[string]string{"type": "query", "query": "https://www.icloud.com/mail"},
+	)
+	assert.JSONEq(t, `["websites/apple.com/alice"]`, string(resps[0]))
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := apimock.New()
+	in := &bytes.Buffer{}
+	out := &bytes.Buffer{}
+	a := New(store, in, out)
+
+	create := func(req createMessage) any {
+		return struct {
+			Type string `json:"type"`
+			createMessage
+		}{"create", req}
+	}
+
+	resps := roundtrip(t, a, in, out,
+		create(createMessage{Name: "websites/163.com/alice", Login: "alice", Length: 30, Generate: true}),
+		create(createMessage{Name: "websites/example.com/bob", Login: "bob", Password: "hunter2"}),
+		create(createMessage{Name: "websites/example.com/bob", Login: "bob", Password: "again"}),
+		create(createMessage{Name: "websites/example.com/carol"}),
+	)
+
+	var login loginResponse
+	require.NoError(t, json.Unmarshal(resps[0], &login))
+	assert.Equal(t, "alice", login.Username)
+	assert.Len(t, login.Password, 16, "163.com allows at most 16 characters")
+
+	sec, err := store.Get(ctx, "websites/163.com/alice", "")
+	require.NoError(t, err)
+	assert.Equal(t, login.Password, sec.Password())
+	user, _ := sec.Get("username")
+	assert.Equal(t, "alice", user)
+
+	assert.JSONEq(t, `{"username":"bob","password":"hunter2"}`, string(resps[1]))
+	assert.Contains(t, string(resps[2]), "already exists")
+	assert.Contains(t, string(resps[3]), "password is required")
+}
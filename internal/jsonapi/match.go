@@ -0,0 +1,84 @@
+package jsonapi
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/pwgen/pwrules"
+)
+
+// parseHost returns the lower case host name of a URL or host. The port
+// and a leading "www." are removed.
+func parseHost(in string) string {
+	in = strings.TrimSpace(in)
+	if in == "" {
+		return ""
+	}
+
+	if !strings.Contains(in, "://") {
+		in = "https://" + in
+	}
+
+	u, err := url.Parse(in)
+	if err != nil {
+		return ""
+	}
+
+	host := u.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// domains returns all domains that match a host: the host itself, its
+// parent domains and their known aliases, e.g. "login.example.com",
+// "example.com" and "example.net" if the latter is an alias of example.com.
+func domains(host string) map[string]bool {
+	m := make(map[string]bool, 8)
+	if host == "" {
+		return m
+	}
+
+	add := func(d string) {
+		m[d] = true
+		for _, alias := range pwrules.LookupAliases(d) {
+			m[alias] = true
+		}
+	}
+
+	add(host)
+	// stop before the top level domain.
+	labels := strings.Split(host, ".")
+	for i := 1; i < len(labels)-1; i++ {
+		add(strings.Join(labels[i:], "."))
+	}
+
+	return m
+}
+
+// matchPath returns true if any component of the secret name is one of the
+// domains.
+func matchPath(name string, domains map[string]bool) bool {
+	for _, elem := range strings.Split(name, "/") {
+		if domains[parseHost(elem)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostOf returns the first component of the secret name that looks like a
+// domain, e.g. "example.com" for "websites/example.com/alice".
+func hostOf(name string) string {
+	for _, elem := range strings.Split(name, "/") {
+		if strings.Contains(elem, ".") && !strings.HasPrefix(elem, ".") {
+			return parseHost(elem)
+		}
+	}
+
+	return ""
+}
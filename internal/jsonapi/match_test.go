@@ -0,0 +1,50 @@
+package jsonapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHost(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"https://www.Example.com:8443/login?x=1": "example.com",
+		"example.com":                            "example.com",
+		"login.example.com/path":                 "login.example.com",
+		"":                                       "",
+	} {
+		assert.Equal(t, want, parseHost(in), in)
+	}
+}
+
+func TestDomains(t *testing.T) {
+	t.Parallel()
+
+	d := domains("signin.apple.com")
+	assert.True(t, d["signin.apple.com"])
+	assert.True(t, d["apple.com"])
+	assert.True(t, d["icloud.com"], "alias")
+	assert.False(t, d["com"])
+
+	assert.Equal(t, map[string]bool{"localhost": true}, domains("localhost"))
+	assert.Empty(t, domains(""))
+}
+
+func TestMatchPath(t *testing.T) {
+	t.Parallel()
+
+	d := domains("www.icloud.com")
+	assert.True(t, matchPath("websites/apple.com/alice", d))
+	assert.True(t, matchPath("icloud.com", d))
+	assert.False(t, matchPath("websites/apple.com.evil.org/alice", d))
+	assert.False(t, matchPath("websites/example.com", d))
+}
+
+func TestHostOf(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "example.com", hostOf("websites/www.example.com/alice"))
+	assert.Equal(t, "", hostOf("misc/.hidden/alice"))
+}
@@ -0,0 +1,88 @@
+package jsonapi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxMessageSize is the largest message accepted from the browser. Chrome
+// and Firefox limit messages to the host to 4 GiB, but no request of this
+// API comes close to 1 MiB.
+const maxMessageSize = 1 << 20
+
+// messageType is only used to dispatch a request.
+type messageType struct {
+	Type string `json:"type"`
+}
+
+type queryMessage struct {
+	// Query is the host or URL of the current page.
+	Query string `json:"query"`
+}
+
+type entryMessage struct {
+	Entry string `json:"entry"`
+}
+
+type createMessage struct {
+	Name     string `json:"entry_name"`
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	Length   int    `json:"length"`
+	Generate bool   `json:"generate"`
+}
+
+type loginResponse struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// readMessage reads a single message. Every message is prefixed with its
+// length as an unsigned 32 bit integer in native byte order, which is little
+// endian on all platforms supported by the browsers.
+func readMessage(r io.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to read message length: %w", err)
+	}
+
+	if length > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the limit of %d bytes", length, maxMessageSize)
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	return buf, nil
+}
+
+// writeMessage writes v as a length prefixed JSON message.
+func writeMessage(w io.Writer, v any) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(buf))); err != nil {
+		return fmt.Errorf("failed to write message length: %w", err)
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+// Package keygen generates typed key material, e.g. SSH key pairs or API
+// tokens, for storing in gopass.
+//
+// Every generated key has a private part that must be kept secret and,
+// except for tokens, a public part that can be shared.
+package keygen
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/ssh"
+)
+
+// These constants are the supported key types.
+const (
+	TypeSSHEd25519 = "ssh-ed25519"
+	TypeSSHRSA     = "ssh-rsa"
+	TypeAge        = "age"
+	TypeWireGuard  = "wireguard"
+	TypeX509       = "x509"
+	TypeHex        = "hex"
+	TypeBase64URL  = "base64url"
+	TypeUUID       = "uuid"
+)
+
+// These constants are the values of the type key of the generated secrets.
+const (
+	KindSSH       = "ssh-key"
+	KindAge       = "age-identity"
+	KindWireGuard = "wireguard-key"
+	KindX509      = "x509-cert"
+	KindToken     = "token"
+)
+
+const (
+	defaultRSABits    = 3072
+	minRSABits        = 2048
+	defaultTokenBytes = 32
+	x509Validity      = 365 * 24 * time.Hour
+)
+
+// Key is generated key material.
+type Key struct {
+	// Kind describes the kind of key, e.g. ssh-key. It is stored in the
+	// type key of the secret.
+	Kind string
+	// Password is the private part if it fits on a single line.
+	Password string
+	// Private is the private part if it spans multiple lines, e.g. a PEM
+	// encoded private key.
+	Private string
+	// Public is the public part. It is empty for tokens.
+	Public string
+}
+
+type generator func(size int, name string) (*Key, error)
+
+var generators = map[string]generator{
+	TypeSSHEd25519: sshEd25519,
+	TypeSSHRSA:     sshRSA,
+	TypeAge:        ageIdentity,
+	TypeWireGuard:  wireGuard,
+	TypeX509:       selfSigned,
+	TypeHex:        hexToken,
+	TypeBase64URL:  base64URLToken,
+	TypeUUID:       uuid,
+}
+
+// Types returns the names of all supported key types.
+func Types() []string {
+	types := make([]string, 0, len(generators))
+	for t := range generators {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// Generate creates a new key of the given type. The size is the number of
+// bits of RSA keys and the number of random bytes of hex and base64url
+// tokens. It is ignored by the other types, zero selects the default. The
+// name is used as the comment of SSH keys and the common name of
+// certificates.
+func Generate(typ string, size int, name string) (*Key, error) {
+	gen, found := generators[typ]
+	if !found {
+		return nil, fmt.Errorf("unknown key type %q, use one of: %s", typ, strings.Join(Types(), ", "))
+	}
+
+	if size < 0 {
+		return nil, fmt.Errorf("size must not be negative")
+	}
+
+	return gen(size, name)
+}
+
+func sshEd25519(_ int, name string) (*Key, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	return sshKey(pub, priv, name)
+}
+
+func sshRSA(size int, name string) (*Key, error) {
+	if size == 0 {
+		size = defaultRSABits
+	}
+	if size < minRSABits {
+		return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+	}
+
+	priv, err := rsa.GenerateKey(rand.Reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	return sshKey(&priv.PublicKey, priv, name)
+}
+
+func sshKey(pub, priv any, name string) (*Key, error) {
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	block, err := marshalOpenSSH(priv, name)
+	if err != nil {
+		return nil, err
+	}
+
+	authorized := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshPub)), "\n")
+	if name != "" {
+		authorized += " " + name
+	}
+
+	return &Key{
+		Kind:    KindSSH,
+		Private: string(pem.EncodeToMemory(block)),
+		Public:  authorized,
+	}, nil
+}
+
+func ageIdentity(_ int, _ string) (*Key, error) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity: %w", err)
+	}
+
+	return &Key{
+		Kind:     KindAge,
+		Password: id.String(),
+		Public:   id.Recipient().String(),
+	}, nil
+}
+
+func wireGuard(_ int, _ string) (*Key, error) {
+	priv := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(priv); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	// clamp the scalar like wg genkey does.
+	priv[0] &= 248
+	priv[31] = (priv[31] & 127) | 64
+
+	pub, err := curve25519.X25519(priv, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive public key: %w", err)
+	}
+
+	return &Key{
+		Kind:     KindWireGuard,
+		Password: base64.StdEncoding.EncodeToString(priv),
+		Public:   base64.StdEncoding.EncodeToString(pub),
+	}, nil
+}
+
+// selfSigned creates an ECDSA P-256 key and a self-signed certificate that
+// is valid for one year.
+func selfSigned(_ int, name string) (*Key, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(x509Validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	if name != "" && !strings.ContainsAny(name, " /") {
+		tmpl.DNSNames = []string{name}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	return &Key{
+		Kind:    KindX509,
+		Private: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+		Public:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}, nil
+}
+
+func randomBytes(size int) ([]byte, error) {
+	if size == 0 {
+		size = defaultTokenBytes
+	}
+
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	return buf, nil
+}
+
+func hexToken(size int, _ string) (*Key, error) {
+	buf, err := randomBytes(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		Kind:     KindToken,
+		Password: hex.EncodeToString(buf),
+	}, nil
+}
+
+func base64URLToken(size int, _ string) (*Key, error) {
+	buf, err := randomBytes(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		Kind:     KindToken,
+		Password: base64.RawURLEncoding.EncodeToString(buf),
+	}, nil
+}
+
+// uuid creates a random (version 4) UUID.
+func uuid(_ int, _ string) (*Key, error) {
+	buf, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+
+	return &Key{
+		Kind:     KindToken,
+		Password: fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:]),
+	}, nil
+}
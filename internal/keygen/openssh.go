@@ -0,0 +1,120 @@
+package keygen
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// opensshMagic starts every private key in the OpenSSH format.
+const opensshMagic = "openssh-key-v1\x00"
+
+// opensshKey is the outer, unencrypted structure of an OpenSSH private key
+// as described in PROTOCOL.key of OpenSSH.
+type opensshKey struct {
+	CipherName   string
+	KdfName      string
+	KdfOpts      string
+	NumKeys      uint32
+	PubKey       []byte
+	PrivKeyBlock []byte
+}
+
+// opensshPrivate is the private key section. The key fields and the
+// comment follow in Rest.
+type opensshPrivate struct {
+	Check1  uint32
+	Check2  uint32
+	Keytype string
+	Rest    []byte `ssh:"rest"`
+}
+
+type opensshEd25519 struct {
+	Pub     []byte
+	Priv    []byte
+	Comment string
+}
+
+type opensshRSA struct {
+	N       *big.Int
+	E       *big.Int
+	D       *big.Int
+	Iqmp    *big.Int
+	P       *big.Int
+	Q       *big.Int
+	Comment string
+}
+
+// marshalOpenSSH encodes an unencrypted private key in the OpenSSH format.
+// The version of golang.org/x/crypto/ssh in use can only parse it.
+func marshalOpenSSH(key any, comment string) (*pem.Block, error) {
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	ci := binary.BigEndian.Uint32(check[:])
+
+	priv := opensshPrivate{
+		Check1: ci,
+		Check2: ci,
+	}
+
+	var pub ssh.PublicKey
+	var err error
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		pubBytes := k.Public().(ed25519.PublicKey) //nolint:forcetypeassert
+		pub, err = ssh.NewPublicKey(pubBytes)
+		priv.Keytype = ssh.KeyAlgoED25519
+		priv.Rest = ssh.Marshal(opensshEd25519{
+			Pub:     pubBytes,
+			Priv:    k,
+			Comment: comment,
+		})
+	case *rsa.PrivateKey:
+		pub, err = ssh.NewPublicKey(&k.PublicKey)
+		priv.Keytype = ssh.KeyAlgoRSA
+		priv.Rest = ssh.Marshal(opensshRSA{
+			N:       k.N,
+			E:       big.NewInt(int64(k.E)),
+			D:       k.D,
+			Iqmp:    k.Precomputed.Qinv,
+			P:       k.Primes[0],
+			Q:       k.Primes[1],
+			Comment: comment,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	// the private section is padded with 1, 2, 3, ... to the block size
+	// of the (none) cipher.
+	block := ssh.Marshal(priv)
+	for i := byte(1); len(block)%8 != 0; i++ {
+		block = append(block, i)
+	}
+
+	buf := []byte(opensshMagic)
+	buf = append(buf, ssh.Marshal(opensshKey{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       pub.Marshal(),
+		PrivKeyBlock: block,
+	})...)
+
+	return &pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: buf,
+	}, nil
+}
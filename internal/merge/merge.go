@@ -0,0 +1,251 @@
+// Package merge implements a three-way merge of decrypted secrets.
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/set"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/kpitt/gopass/pkg/gopass/secrets/secparse"
+)
+
+// Conflict markers, as used by git.
+const (
+	MarkerOurs   = "<<<<<<< ours"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>> theirs"
+)
+
+// Secrets merges the changes from base to ours and from base to theirs.
+// The password, every key and the body are merged separately, so changes
+// to different keys of a KV or YAML secret do not conflict. base is empty
+// if there is no common ancestor.
+//
+// If a field was changed on both sides the merge fails and the result
+// contains both versions, each with all non-conflicting changes, inside
+// conflict markers. The boolean is true if the merge succeeded.
+func Secrets(base, ours, theirs []byte) ([]byte, bool) {
+	switch {
+	case bytes.Equal(ours, theirs), bytes.Equal(base, theirs):
+		return ours, true
+	case bytes.Equal(base, ours):
+		return theirs, true
+	}
+
+	baseSec, _ := secparse.Parse(base)
+	oursSec, _ := secparse.Parse(ours)
+	theirsSec, _ := secparse.Parse(theirs)
+
+	// a secret that changed its format can not be merged field by field.
+	if fmt.Sprintf("%T", oursSec) != fmt.Sprintf("%T", theirsSec) {
+		debug.Log("can not merge %T and %T", oursSec, theirsSec)
+
+		return conflict(ours, theirs), false
+	}
+
+	bf, of, tf := fields(baseSec), fields(oursSec), fields(theirsSec)
+
+	merged := make(map[string]field, len(of)+len(tf))
+	var conflicts []string
+	for _, k := range set.Sorted(append(append(keys(bf), keys(of)...), keys(tf)...)) {
+		f, ok := merge3(bf[k], of[k], tf[k])
+		if !ok {
+			conflicts = append(conflicts, k)
+
+			continue
+		}
+		merged[k] = f
+	}
+
+	if len(conflicts) < 1 {
+		return apply(ours, merged), true
+	}
+
+	debug.Log("conflicting fields: %+v", conflicts)
+
+	oursMerged := make(map[string]field, len(merged)+len(conflicts))
+	theirsMerged := make(map[string]field, len(merged)+len(conflicts))
+	for k, f := range merged {
+		oursMerged[k] = f
+		theirsMerged[k] = f
+	}
+	for _, k := range conflicts {
+		oursMerged[k] = of[k]
+		theirsMerged[k] = tf[k]
+	}
+
+	return conflict(apply(ours, oursMerged), apply(ours, theirsMerged)), false
+}
+
+// HasConflicts returns true if buf contains conflict markers.
+func HasConflicts(buf []byte) bool {
+	for _, line := range strings.Split(string(buf), "\n") {
+		switch strings.TrimSpace(line) {
+		case MarkerOurs, MarkerSep, MarkerTheirs:
+			return true
+		}
+	}
+
+	return false
+}
+
+// field is the value of the password, a key or the body of a secret.
+type field struct {
+	values  []string
+	present bool
+}
+
+func (f field) equal(o field) bool {
+	return f.present == o.present && strings.Join(f.values, "\n") == strings.Join(o.values, "\n")
+}
+
+// keys are prefixed to keep them apart from the password and the body.
+const (
+	fieldPassword = "\x00password"
+	fieldBody     = "\x00body"
+	keyPrefix     = "key:"
+)
+
+func fields(sec gopass.Secret) map[string]field {
+	fs := map[string]field{
+		fieldPassword: {values: []string{sec.Password()}, present: true},
+		fieldBody:     {values: []string{sec.Body()}, present: true},
+	}
+
+	for _, k := range sec.Keys() {
+		vs, _ := sec.Values(k)
+		fs[keyPrefix+k] = field{values: vs, present: true}
+	}
+
+	return fs
+}
+
+func keys(fs map[string]field) []string {
+	ks := make([]string, 0, len(fs))
+	for k := range fs {
+		ks = append(ks, k)
+	}
+
+	return ks
+}
+
+// merge3 returns the merged field, or false if both sides changed it.
+func merge3(base, ours, theirs field) (field, bool) {
+	switch {
+	case ours.equal(theirs), base.equal(theirs):
+		return ours, true
+	case base.equal(ours):
+		return theirs, true
+	default:
+		return field{}, false
+	}
+}
+
+// apply updates the secret ours with the merged fields. Unchanged keys keep
+// their original representation.
+func apply(ours []byte, merged map[string]field) []byte {
+	sec, _ := secparse.Parse(ours)
+	of := fields(sec)
+
+	for k, f := range merged {
+		if !strings.HasPrefix(k, keyPrefix) || f.equal(of[k]) {
+			continue
+		}
+
+		key := strings.TrimPrefix(k, keyPrefix)
+		sec.Del(key)
+
+		if !f.present {
+			continue
+		}
+
+		// YAML keys are unique, KV keys may repeat.
+		if _, ok := sec.(*secrets.YAML); ok {
+			_ = sec.Set(key, f.values[0])
+
+			continue
+		}
+
+		for _, v := range f.values {
+			_ = sec.Add(key, v)
+		}
+	}
+
+	if pw := merged[fieldPassword].values[0]; pw != sec.Password() {
+		sec.SetPassword(pw)
+	}
+
+	if body := merged[fieldBody].values[0]; body != sec.Body() {
+		return withBody(sec, body)
+	}
+
+	return sec.Bytes()
+}
+
+// withBody returns the secret with its body replaced. None of the secret
+// types can replace the body, so the secret is assembled from its parts.
+func withBody(sec gopass.Secret, body string) []byte {
+	switch s := sec.(type) {
+	case *secrets.KV:
+		kv := secrets.NewKV()
+		kv.SetPassword(s.Password())
+
+		for _, k := range s.Keys() {
+			vs, _ := s.Values(k)
+			for _, v := range vs {
+				_ = kv.Add(k, v)
+			}
+		}
+
+		_, _ = kv.Write([]byte(body))
+
+		return kv.Bytes()
+	case *secrets.YAML:
+		buf := s.Bytes()
+		// the body can not contain the YAML separator, so the first one
+		// starts the YAML section.
+		idx := bytes.Index(buf, []byte("\n---\n"))
+		if idx < 0 {
+			return []byte(s.Password() + "\n" + body)
+		}
+
+		out := s.Password() + "\n" + body
+		if body != "" && !strings.HasSuffix(body, "\n") {
+			out += "\n"
+		}
+
+		return append([]byte(out), buf[idx+1:]...)
+	default:
+		return []byte(sec.Password() + "\n" + body)
+	}
+}
+
+// conflict returns both versions inside conflict markers.
+func conflict(ours, theirs []byte) []byte {
+	buf := &bytes.Buffer{}
+
+	for _, part := range []struct {
+		marker  string
+		content []byte
+	}{
+		{MarkerOurs, ours},
+		{MarkerSep, theirs},
+	} {
+		buf.WriteString(part.marker)
+		buf.WriteString("\n")
+		buf.Write(part.content)
+
+		if len(part.content) > 0 && !bytes.HasSuffix(part.content, []byte("\n")) {
+			buf.WriteString("\n")
+		}
+	}
+
+	buf.WriteString(MarkerTheirs)
+	buf.WriteString("\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,93 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecrets(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		base   string
+		ours   string
+		theirs string
+		want   string
+		ok     bool
+	}{
+		{
+			name:   "unchanged",
+			base:   "foo\n",
+			ours:   "foo\n",
+			theirs: "bar\n",
+			want:   "bar\n",
+			ok:     true,
+		},
+		{
+			name:   "different keys",
+			base:   "foo\nuser: alice\nurl: example.org\n",
+			ours:   "foo\nuser: bob\nurl: example.org\n",
+			theirs: "foo\nuser: alice\nurl: example.com\n",
+			want:   "foo\nurl: example.com\nuser: bob",
+			ok:     true,
+		},
+		{
+			name:   "password and key",
+			base:   "foo\nuser: alice\n",
+			ours:   "bar\nuser: alice\n",
+			theirs: "foo\nuser: alice\npin: 1234\n",
+			want:   "bar\npin: 1234\nuser: alice",
+			ok:     true,
+		},
+		{
+			name:   "removed key",
+			base:   "foo\nuser: alice\nurl: example.org\n",
+			ours:   "foo\nuser: alice\n",
+			theirs: "bar\nuser: alice\nurl: example.org\n",
+			want:   "bar\nuser: alice",
+			ok:     true,
+		},
+		{
+			name:   "yaml",
+			base:   "foo\n---\nuser: alice\nport: 22\n",
+			ours:   "foo\n---\nuser: bob\nport: 22\n",
+			theirs: "foo\nnotes\n---\nuser: alice\nport: 22\n",
+			want:   "foo\nnotes\n---\nport: 22\nuser: bob\n",
+			ok:     true,
+		},
+		{
+			name:   "conflicting key",
+			base:   "foo\nuser: alice\nurl: example.org\n",
+			ours:   "foo\nuser: bob\nurl: example.org\n",
+			theirs: "bar\nuser: carol\nurl: example.org\n",
+			want:   "<<<<<<< ours\nbar\nurl: example.org\nuser: bob\n=======\nbar\nurl: example.org\nuser: carol\n>>>>>>> theirs\n",
+		},
+		{
+			name:   "conflicting body",
+			base:   "foo\nline1\n",
+			ours:   "foo\nline2\n",
+			theirs: "foo\nline3\n",
+			want:   "<<<<<<< ours\nfoo\nline2\n=======\nfoo\nline3\n>>>>>>> theirs\n",
+		},
+		{
+			name:   "no base",
+			ours:   "foo\nuser: alice\n",
+			theirs: "foo\nurl: example.org\n",
+			want:   "foo\nurl: example.org\nuser: alice",
+			ok:     true,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := Secrets([]byte(tc.base), []byte(tc.ours), []byte(tc.theirs))
+			assert.Equal(t, tc.want, string(got))
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, !tc.ok, HasConflicts(got))
+		})
+	}
+}
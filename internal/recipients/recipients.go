@@ -2,6 +2,7 @@ package recipients
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	"github.com/kpitt/gopass/internal/set"
@@ -32,3 +33,28 @@ func Unmarshal(buf []byte) []string {
 		return strings.TrimSpace(k)
 	})
 }
+
+// UnmarshalMapping reads a recipient mapping, one "<old> <new>" pair per
+// line. An old recipient can be mapped to more than one new recipient by
+// repeating it. Empty lines and lines starting with # are ignored.
+func UnmarshalMapping(buf []byte) (map[string][]string, error) {
+	in := strings.ReplaceAll(string(buf), "\r\n", "\n")
+	in = strings.ReplaceAll(in, "\r", "\n")
+
+	m := make(map[string][]string)
+	for i, line := range strings.Split(in, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid mapping in line %d: %q", i+1, line)
+		}
+
+		m[fields[0]] = append(m[fields[0]], fields[1])
+	}
+
+	return m, nil
+}
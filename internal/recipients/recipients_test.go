@@ -47,3 +47,17 @@ func TestUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalMapping(t *testing.T) {
+	t.Parallel()
+
+	m, err := UnmarshalMapping([]byte("# gpg to age\r\n0xDEADBEEF age1foo\r\n\r\nbar@example.org age1bar\n0xDEADBEEF age1baz\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"0xDEADBEEF":      {"age1foo", "age1baz"},
+		"bar@example.org": {"age1bar"},
+	}, m)
+
+	_, err = UnmarshalMapping([]byte("0xDEADBEEF\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+)
+
+// maxBodySize limits the size of request bodies.
+const maxBodySize = 1 << 20
+
+// The types below define the request and response documents.
+
+// Secret is a single secret.
+type Secret struct {
+	Name     string              `json:"name"`
+	Revision string              `json:"revision,omitempty"`
+	Password string              `json:"password"`
+	Keys     map[string][]string `json:"keys,omitempty"`
+	Body     string              `json:"body,omitempty"`
+}
+
+// List is the response to a listing.
+type List struct {
+	Secrets []string `json:"secrets"`
+}
+
+// Revisions is the response to a revision listing.
+type Revisions struct {
+	Name      string   `json:"name"`
+	Revisions []string `json:"revisions"`
+}
+
+// Rename is a request to move a secret or folder.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Error is returned for all failed requests.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Handler returns the HTTP handler. Connections must be set up by Serve,
+// otherwise all requests are rejected.
+//
+//	GET    /v1/secrets                list secrets
+//	GET    /v1/secrets/<name>         get a secret (?revision=)
+//	PUT    /v1/secrets/<name>         set a secret
+//	DELETE /v1/secrets/<name>         remove a secret (?recursive=true)
+//	GET    /v1/revisions/<name>       list revisions of a secret
+//	POST   /v1/rename                 rename a secret or folder
+//	POST   /v1/sync                   sync with the remotes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/secrets", s.handleList)
+	mux.HandleFunc("/v1/secrets/", s.handleSecret)
+	mux.HandleFunc("/v1/revisions/", s.handleRevisions)
+	mux.HandleFunc("/v1/rename", s.handleRename)
+	mux.HandleFunc("/v1/sync", s.handleSync)
+
+	return s.authorize(mux)
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.authorized(r.Context()); err != nil {
+			debug.Log("rejected request %s %s: %s", r.Method, r.URL.Path, err)
+			writeError(w, http.StatusForbidden, "access denied")
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	names, err := s.store.List(r.Context())
+	if err != nil {
+		writeStoreError(w, err)
+
+		return
+	}
+
+	prefix := strings.Trim(r.URL.Query().Get("prefix"), "/")
+	res := List{Secrets: make([]string, 0, len(names))}
+	for _, name := range names {
+		if !s.allowed(name) {
+			continue
+		}
+		if prefix != "" && name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		res.Secrets = append(res.Secrets, name)
+	}
+	sort.Strings(res.Secrets)
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleSecret(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/secrets/"), "/")
+	if !s.checkName(w, name) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getSecret(w, r, name)
+	case http.MethodPut:
+		if s.checkWritable(w) {
+			s.setSecret(w, r, name)
+		}
+	case http.MethodDelete:
+		if s.checkWritable(w) {
+			s.removeSecret(w, r, name)
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) getSecret(w http.ResponseWriter, r *http.Request, name string) {
+	revision := r.URL.Query().Get("revision")
+	sec, err := s.store.Get(r.Context(), name, revision)
+	if err != nil {
+		writeStoreError(w, err)
+
+		return
+	}
+
+	res := Secret{
+		Name:     name,
+		Revision: revision,
+		Password: sec.Password(),
+		Body:     sec.Body(),
+	}
+	if keys := sec.Keys(); len(keys) > 0 {
+		res.Keys = make(map[string][]string, len(keys))
+		for _, k := range keys {
+			res.Keys[k], _ = sec.Values(k)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) setSecret(w http.ResponseWriter, r *http.Request, name string) {
+	var req Secret
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	sec := secrets.NewKV()
+	sec.SetPassword(req.Password)
+
+	keys := make([]string, 0, len(req.Keys))
+	for k := range req.Keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range req.Keys[k] {
+			if err := sec.Add(k, v); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid key %q: %s", k, err))
+
+				return
+			}
+		}
+	}
+
+	if req.Body != "" {
+		if _, err := sec.Write([]byte(req.Body)); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid body: %s", err))
+
+			return
+		}
+	}
+
+	if err := s.store.Set(r.Context(), name, sec); err != nil {
+		writeStoreError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) removeSecret(w http.ResponseWriter, r *http.Request, name string) {
+	var err error
+	if r.URL.Query().Get("recursive") == "true" {
+		err = s.store.RemoveAll(r.Context(), name)
+	} else {
+		err = s.store.Remove(r.Context(), name)
+	}
+
+	if err != nil {
+		writeStoreError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRevisions(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/revisions/"), "/")
+	if !s.checkName(w, name) {
+		return
+	}
+
+	revs, err := s.store.Revisions(r.Context(), name)
+	if err != nil {
+		writeStoreError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Revisions{Name: name, Revisions: revs})
+}
+
+func (s *Server) handleRename(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) || !s.checkWritable(w) {
+		return
+	}
+
+	var req Rename
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	if req.From == "" || req.To == "" {
+		writeError(w, http.StatusBadRequest, "from and to are required")
+
+		return
+	}
+
+	req.From, req.To = strings.Trim(req.From, "/"), strings.Trim(req.To, "/")
+	if !s.checkName(w, req.From) || !s.checkName(w, req.To) {
+		return
+	}
+
+	if err := s.store.Rename(r.Context(), req.From, req.To); err != nil {
+		writeStoreError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) || !s.checkWritable(w) {
+		return
+	}
+
+	if err := s.store.Sync(r.Context()); err != nil {
+		writeStoreError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkName makes sure name is valid and may be accessed. It must be
+// called for every name taken from a request.
+func (s *Server) checkName(w http.ResponseWriter, name string) bool {
+	if err := validName(name); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return false
+	}
+
+	if !s.allowed(name) {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("access to %q denied", name))
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) checkWritable(w http.ResponseWriter) bool {
+	if s.cfg.ReadOnly {
+		writeError(w, http.StatusForbidden, "server is read-only")
+
+		return false
+	}
+
+	return true
+}
+
+func checkMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+
+		return false
+	}
+
+	return true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %s", err))
+
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		debug.Log("failed to write response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, Error{Error: msg})
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, store.ErrNotFound) {
+		code = http.StatusNotFound
+	}
+
+	writeError(w, code, err.Error())
+}
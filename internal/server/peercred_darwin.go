@@ -0,0 +1,32 @@
+//go:build darwin
+// +build darwin
+
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+func peerUID(c *net.UnixConn) (int, error) {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return -1, fmt.Errorf("failed to get raw connection: %w", err)
+	}
+
+	var cred *unix.Xucred
+	var serr error
+	if err := rc.Control(func(fd uintptr) {
+		cred, serr = unix.GetsockoptXucred(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	}); err != nil {
+		return -1, fmt.Errorf("failed to access connection: %w", err)
+	}
+
+	if serr != nil {
+		return -1, fmt.Errorf("failed to get peer credentials: %w", serr)
+	}
+
+	return int(cred.Uid), nil
+}
@@ -0,0 +1,32 @@
+//go:build linux
+// +build linux
+
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+func peerUID(c *net.UnixConn) (int, error) {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return -1, fmt.Errorf("failed to get raw connection: %w", err)
+	}
+
+	var cred *unix.Ucred
+	var serr error
+	if err := rc.Control(func(fd uintptr) {
+		cred, serr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return -1, fmt.Errorf("failed to access connection: %w", err)
+	}
+
+	if serr != nil {
+		return -1, fmt.Errorf("failed to get peer credentials: %w", serr)
+	}
+
+	return int(cred.Uid), nil
+}
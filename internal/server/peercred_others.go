@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package server
+
+import "net"
+
+func peerUID(*net.UnixConn) (int, error) {
+	return -1, ErrNotSupported
+}
@@ -0,0 +1,220 @@
+// Package server exposes a gopass.Store over HTTP on a Unix domain socket.
+//
+// Every connection is authorized by the UID of the connecting process, so
+// only the listed users can talk to the server, even if the socket file is
+// accessible to others. The server can be restricted to read-only requests
+// and to a set of path prefixes.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kpitt/gopass/pkg/appdir"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+)
+
+// ErrNotSupported is returned if the peer credentials of a connection can
+// not be determined on this platform.
+var ErrNotSupported = fmt.Errorf("peer credentials are not supported on this platform")
+
+// Config controls what clients may do.
+type Config struct {
+	// ReadOnly rejects all requests that would modify the store.
+	ReadOnly bool
+	// Allow restricts access to secrets below these prefixes. An empty
+	// list allows access to all secrets.
+	Allow []string
+	// UIDs lists the users that may connect. An empty list only allows
+	// the user running the server.
+	UIDs []int
+}
+
+// Server serves a gopass.Store.
+type Server struct {
+	store gopass.Store
+	cfg   Config
+}
+
+// New creates a new server.
+func New(store gopass.Store, cfg Config) *Server {
+	if len(cfg.UIDs) < 1 {
+		cfg.UIDs = []int{os.Getuid()}
+	}
+
+	allow := make([]string, 0, len(cfg.Allow))
+	for _, p := range cfg.Allow {
+		if p = strings.Trim(p, "/"); p != "" {
+			allow = append(allow, p)
+		}
+	}
+	cfg.Allow = allow
+
+	return &Server{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Listen creates a Unix domain socket at path. A stale socket left over
+// from an earlier run is removed. The socket is only accessible by the
+// current user.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket dir: %w", err)
+	}
+
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = l.Close()
+
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return l, nil
+}
+
+// Serve handles connections on l until ctx is canceled.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	hs := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+		ConnContext: connContext,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = hs.Shutdown(sctx)
+	}()
+
+	if err := hs.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	return nil
+}
+
+type contextKey int
+
+const ctxKeyPeer contextKey = iota
+
+// peer is the result of looking up the credentials of a connection.
+type peer struct {
+	uid int
+	err error
+}
+
+// connContext records the UID of the connecting process. This happens once
+// per connection, every request on that connection is authorized against it.
+func connContext(ctx context.Context, c net.Conn) context.Context {
+	p := peer{uid: -1}
+
+	uc, ok := c.(*net.UnixConn)
+	if ok {
+		p.uid, p.err = peerUID(uc)
+	} else {
+		p.err = fmt.Errorf("not a unix socket connection")
+	}
+
+	if p.err != nil {
+		debug.Log("failed to get peer credentials: %s", p.err)
+	}
+
+	return context.WithValue(ctx, ctxKeyPeer, p)
+}
+
+// authorized returns nil if the peer of the request may use the server.
+func (s *Server) authorized(ctx context.Context) error {
+	p, ok := ctx.Value(ctxKeyPeer).(peer)
+	if !ok {
+		return fmt.Errorf("unknown peer")
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+
+	for _, uid := range s.cfg.UIDs {
+		if uid == p.uid {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("uid %d is not allowed", p.uid)
+}
+
+// validName returns an error if name is not a clean secret name. A name like
+// "team/../private/x" would pass the allow list but refer to a secret outside
+// of it once the storage resolves it.
+func validName(name string) error {
+	if name == "" {
+		return fmt.Errorf("missing secret name")
+	}
+
+	for _, seg := range strings.Split(name, "/") {
+		if seg == ".." {
+			return fmt.Errorf("invalid secret name %q", name)
+		}
+	}
+
+	if name != path.Clean(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+
+	return nil
+}
+
+// allowed returns true if name is below one of the allowed prefixes.
+func (s *Server) allowed(name string) bool {
+	if len(s.cfg.Allow) < 1 {
+		return true
+	}
+
+	name = strings.Trim(name, "/")
+	for _, p := range s.cfg.Allow {
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DefaultSocket returns the default location of the socket. It is placed
+// in the user's runtime dir, if there is one, and in the cache dir otherwise.
+func DefaultSocket() string {
+	if rd := os.Getenv("XDG_RUNTIME_DIR"); rd != "" {
+		return filepath.Join(rd, appdir.Name, "gopass.sock")
+	}
+
+	return filepath.Join(appdir.UserCache(), "gopass.sock")
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/gopass/apimock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type client struct {
+	t  *testing.T
+	hc *http.Client
+}
+
+func newClient(t *testing.T, sock string) *client {
+	t.Helper()
+
+	return &client{
+		t: t,
+		hc: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+
+					return d.DialContext(ctx, "unix", sock)
+				},
+			},
+		},
+	}
+}
+
+func (c *client) do(method, path string, in, out any) int {
+	c.t.Helper()
+
+	var body bytes.Buffer
+	if in != nil {
+		require.NoError(c.t, json.NewEncoder(&body).Encode(in))
+	}
+
+	req, err := http.NewRequest(method, "http://gopass"+path, &body)
+	require.NoError(c.t, err)
+
+	resp, err := c.hc.Do(req)
+	require.NoError(c.t, err)
+	defer resp.Body.Close() //nolint:errcheck
+
+	if out != nil && resp.StatusCode < 300 {
+		require.NoError(c.t, json.NewDecoder(resp.Body).Decode(out))
+	}
+
+	return resp.StatusCode
+}
+
+func serve(t *testing.T, cfg Config) *client {
+	t.Helper()
+
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("peer credentials are not supported")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := apimock.New()
+	require.NoError(t, store.Set(ctx, "foo/bar", &apimock.Secret{Buf: []byte("secret\nuser: zab\nbody")}))
+	require.NoError(t, store.Set(ctx, "baz", &apimock.Secret{Buf: []byte("other")}))
+
+	sock := filepath.Join(t.TempDir(), "gopass.sock")
+	l, err := Listen(sock)
+	require.NoError(t, err)
+
+	fi, err := os.Stat(sock)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- New(store, cfg).Serve(ctx, l)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	return newClient(t, sock)
+}
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	c := serve(t, Config{})
+
+	var ls List
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets", nil, &ls))
+	assert.Equal(t, []string{"baz", "foo/bar"}, ls.Secrets)
+
+	var sec Secret
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets/foo/bar", nil, &sec))
+	assert.Equal(t, "secret", sec.Password)
+	assert.Equal(t, []string{"zab"}, sec.Keys["user"])
+	assert.Contains(t, sec.Body, "body")
+
+	in := Secret{Password: "new", Keys: map[string][]string{"url": {"example.org"}}}
+	assert.Equal(t, http.StatusNoContent, c.do(http.MethodPut, "/v1/secrets/new/one", in, nil))
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets/new/one", nil, &sec))
+	assert.Equal(t, "new", sec.Password)
+	assert.Equal(t, []string{"example.org"}, sec.Keys["url"])
+
+	assert.Equal(t, http.StatusNoContent, c.do(http.MethodPost, "/v1/rename", Rename{From: "new/one", To: "new/two"}, nil))
+	assert.Equal(t, http.StatusNoContent, c.do(http.MethodDelete, "/v1/secrets/new/two", nil, nil))
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets", nil, &ls))
+	assert.Equal(t, []string{"baz", "foo/bar"}, ls.Secrets)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, c.do(http.MethodPost, "/v1/secrets", nil, nil))
+	assert.Equal(t, http.StatusBadRequest, c.do(http.MethodPost, "/v1/rename", map[string]string{"bogus": "1"}, nil))
+}
+
+func TestServeReadOnly(t *testing.T) {
+	t.Parallel()
+
+	c := serve(t, Config{ReadOnly: true})
+
+	var sec Secret
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets/baz", nil, &sec))
+	assert.Equal(t, "other", sec.Password)
+
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodPut, "/v1/secrets/baz", Secret{Password: "x"}, nil))
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodDelete, "/v1/secrets/baz", nil, nil))
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodPost, "/v1/rename", Rename{From: "baz", To: "zab"}, nil))
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodPost, "/v1/sync", nil, nil))
+
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets/baz", nil, &sec))
+	assert.Equal(t, "other", sec.Password)
+}
+
+func TestServeAllow(t *testing.T) {
+	t.Parallel()
+
+	c := serve(t, Config{Allow: []string{"foo/"}})
+
+	var ls List
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets", nil, &ls))
+	assert.Equal(t, []string{"foo/bar"}, ls.Secrets)
+
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets/foo/bar", nil, &Secret{}))
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodGet, "/v1/secrets/baz", nil, nil))
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodGet, "/v1/secrets/foobar", nil, nil))
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodPost, "/v1/rename", Rename{From: "foo/bar", To: "baz2"}, nil))
+
+	// names must not leave the allowed prefixes.
+	assert.Equal(t, http.StatusBadRequest, c.do(http.MethodPost, "/v1/rename", Rename{From: "foo/../baz", To: "foo/baz"}, nil))
+	assert.Equal(t, http.StatusBadRequest, c.do(http.MethodPost, "/v1/rename", Rename{From: "foo/bar", To: "foo/../../baz"}, nil))
+	assert.Equal(t, http.StatusBadRequest, c.do(http.MethodPost, "/v1/rename", Rename{From: "foo/./bar", To: "foo/baz"}, nil))
+	assert.Equal(t, http.StatusOK, c.do(http.MethodGet, "/v1/secrets", nil, &ls))
+	assert.Equal(t, []string{"foo/bar"}, ls.Secrets)
+}
+
+func TestValidName(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"foo", "foo/bar", "foo..bar/baz"} {
+		assert.NoError(t, validName(name), name)
+	}
+
+	for _, name := range []string{"", "..", "../foo", "foo/../bar", "foo//bar", "foo/./bar", "foo/.."} {
+		assert.Error(t, validName(name), name)
+	}
+}
+
+func TestServeUIDs(t *testing.T) {
+	t.Parallel()
+
+	c := serve(t, Config{UIDs: []int{os.Getuid() + 1}})
+
+	assert.Equal(t, http.StatusForbidden, c.do(http.MethodGet, "/v1/secrets", nil, nil))
+}
+
+func TestListenStale(t *testing.T) {
+	t.Parallel()
+
+	sock := filepath.Join(t.TempDir(), "gopass.sock")
+	l, err := Listen(sock)
+	require.NoError(t, err)
+	// leaves the socket file behind, like a crashed server.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, l.Close())
+
+	l, err = Listen(sock)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, []byte("foo"), 0o600))
+	_, err = Listen(file)
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+// Package sshagent implements an SSH agent that serves private keys stored
+// in gopass.
+//
+// Keys are parsed from the decrypted secrets and only kept in memory, they
+// are never written to disk. Keys can be restricted to a lifetime and can
+// require a confirmation before every use.
+package sshagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kpitt/gopass/pkg/appdir"
+	"github.com/kpitt/gopass/pkg/debug"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ErrDenied is returned if the use of a key was not confirmed.
+var ErrDenied = fmt.Errorf("use of key denied")
+
+// ConfirmFunc is asked before a key that requires confirmation is used. The
+// comment is the name of the secret the key was loaded from.
+type ConfirmFunc func(comment string) bool
+
+// Agent is an SSH agent. Keys are kept in an in-memory keyring.
+type Agent struct {
+	keyring agent.ExtendedAgent
+	confirm ConfirmFunc
+
+	mu sync.Mutex
+	// needsConfirm holds the public keys, in wire format, that must be
+	// confirmed before use.
+	needsConfirm map[string]bool
+}
+
+// New creates an empty agent. Keys that require confirmation are refused
+// if confirm is nil.
+func New(confirm ConfirmFunc) *Agent {
+	kr, _ := agent.NewKeyring().(agent.ExtendedAgent)
+
+	return &Agent{
+		keyring:      kr,
+		confirm:      confirm,
+		needsConfirm: make(map[string]bool),
+	}
+}
+
+// List returns the identities known to the agent.
+func (a *Agent) List() ([]*agent.Key, error) {
+	return a.keyring.List() //nolint:wrapcheck
+}
+
+// Sign has the agent sign the data using a protocol 2 key as defined
+// in [PROTOCOL.agent] section 2.6.2.
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags signs like Sign, but allows for additional flags.
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if err := a.confirmUse(key); err != nil {
+		return nil, err
+	}
+
+	return a.keyring.SignWithFlags(key, data, flags) //nolint:wrapcheck
+}
+
+// confirmUse asks for confirmation if the key requires it. Only one
+// confirmation is asked at a time.
+func (a *Agent) confirmUse(key ssh.PublicKey) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.needsConfirm[string(key.Marshal())] {
+		return nil
+	}
+
+	comment := ssh.FingerprintSHA256(key)
+	if keys, err := a.keyring.List(); err == nil {
+		for _, k := range keys {
+			if string(k.Marshal()) == string(key.Marshal()) {
+				comment = k.Comment
+			}
+		}
+	}
+
+	if a.confirm == nil || !a.confirm(comment) {
+		debug.Log("use of %s denied", comment)
+
+		return ErrDenied
+	}
+
+	return nil
+}
+
+// Add adds a private key to the agent.
+func (a *Agent) Add(key agent.AddedKey) error {
+	signer, err := ssh.NewSignerFromKey(key.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+
+	if err := a.keyring.Add(key); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.needsConfirm[string(signer.PublicKey().Marshal())] = key.ConfirmBeforeUse
+
+	return nil
+}
+
+// Remove removes all identities with the given public key.
+func (a *Agent) Remove(key ssh.PublicKey) error {
+	a.mu.Lock()
+	delete(a.needsConfirm, string(key.Marshal()))
+	a.mu.Unlock()
+
+	return a.keyring.Remove(key) //nolint:wrapcheck
+}
+
+// RemoveAll removes all identities.
+func (a *Agent) RemoveAll() error {
+	a.mu.Lock()
+	a.needsConfirm = make(map[string]bool)
+	a.mu.Unlock()
+
+	return a.keyring.RemoveAll() //nolint:wrapcheck
+}
+
+// Lock locks the agent. Sign, Remove and Add will fail, and List will
+// return an empty list.
+func (a *Agent) Lock(passphrase []byte) error {
+	return a.keyring.Lock(passphrase) //nolint:wrapcheck
+}
+
+// Unlock undoes the effect of Lock.
+func (a *Agent) Unlock(passphrase []byte) error {
+	return a.keyring.Unlock(passphrase) //nolint:wrapcheck
+}
+
+// Signers returns signers for all the known keys.
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return a.keyring.Signers() //nolint:wrapcheck
+}
+
+// Extension processes a custom extension request. No extensions are
+// supported.
+func (a *Agent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+// Serve handles connections on l until ctx is canceled.
+func (a *Agent) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		go func() {
+			defer func() {
+				_ = c.Close()
+			}()
+
+			if err := agent.ServeAgent(a, c); err != nil && !errors.Is(err, io.EOF) {
+				debug.Log("agent connection failed: %s", err)
+			}
+		}()
+	}
+}
+
+// DefaultSocket returns the default location of the agent socket. It is
+// placed in the user's runtime dir, if there is one, and in the cache dir
+// otherwise.
+func DefaultSocket() string {
+	if rd := os.Getenv("XDG_RUNTIME_DIR"); rd != "" {
+		return filepath.Join(rd, appdir.Name, "ssh-agent.sock")
+	}
+
+	return filepath.Join(appdir.UserCache(), "ssh-agent.sock")
+}
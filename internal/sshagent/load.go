@@ -0,0 +1,149 @@
+package sshagent
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const (
+	// TypeKey is the key that marks the type of a secret.
+	TypeKey = "type"
+	// TypeSSHKey marks secrets that contain an SSH private key.
+	TypeSSHKey = "ssh-key"
+	// PrivateKey holds the private key of secrets created by
+	// gopass generate --type.
+	PrivateKey = "private"
+	// PassphraseKey holds the passphrase of an encrypted private key.
+	PassphraseKey = "passphrase"
+)
+
+// Options control which keys are loaded and how they may be used.
+type Options struct {
+	// Prefix loads all secrets below it that contain a private key. Without
+	// a prefix all secrets marked with "type: ssh-key" are loaded, which
+	// requires decrypting every secret.
+	Prefix string
+	// Lifetime removes the keys from the agent after this duration. Zero
+	// keeps them until the agent exits.
+	Lifetime time.Duration
+	// Confirm requires a confirmation before every use of a key.
+	Confirm bool
+}
+
+// Load adds the keys from the store to the agent. It returns the names of
+// the secrets the keys were loaded from.
+func (a *Agent) Load(ctx context.Context, store gopass.Store, opts Options) ([]string, error) {
+	names, err := store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	prefix := strings.Trim(opts.Prefix, "/")
+
+	var loaded []string
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return loaded, ctx.Err() //nolint:wrapcheck
+		}
+
+		if prefix != "" && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+
+		sec, err := store.Get(ctx, name, "latest")
+		if err != nil {
+			if prefix == "" {
+				debug.Log("skipping %s: %s", name, err)
+
+				continue
+			}
+
+			return loaded, fmt.Errorf("failed to get %s: %w", name, err)
+		}
+
+		marked := isMarked(sec)
+		if prefix == "" && !marked {
+			continue
+		}
+
+		key, err := parseKey(sec)
+		if err != nil {
+			if marked {
+				return loaded, fmt.Errorf("failed to load %s: %w", name, err)
+			}
+			debug.Log("skipping %s: %s", name, err)
+
+			continue
+		}
+
+		if err := a.Add(agent.AddedKey{
+			PrivateKey:       key,
+			Comment:          name,
+			LifetimeSecs:     uint32(opts.Lifetime / time.Second),
+			ConfirmBeforeUse: opts.Confirm,
+		}); err != nil {
+			return loaded, fmt.Errorf("failed to add %s: %w", name, err)
+		}
+
+		debug.Log("loaded key from %s", name)
+		loaded = append(loaded, name)
+	}
+
+	return loaded, nil
+}
+
+// pemHeaders returns the encryption headers of a PEM block with their
+// canonical names. Secrets store keys in lower case and may add other
+// key-value lines, e.g. the type marker, in front of the key data.
+func pemHeaders(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		for _, h := range []string{"Proc-Type", "DEK-Info"} {
+			if strings.EqualFold(k, h) {
+				out[h] = v
+			}
+		}
+	}
+
+	return out
+}
+
+func isMarked(sec gopass.Secret) bool {
+	typ, found := sec.Get(TypeKey)
+
+	return found && typ == TypeSSHKey
+}
+
+// parseKey parses the first PEM block in the private key or the content of
+// the secret as a private key. The key may be encrypted with the passphrase
+// stored in the secret.
+func parseKey(sec gopass.Secret) (any, error) {
+	// the PEM headers of encrypted keys are parsed as key-value lines, so
+	// they are only kept by the serialized secret.
+	content := string(sec.Bytes())
+	if pk, found := sec.Get(PrivateKey); found {
+		content = pk
+	}
+
+	block, _ := pem.Decode([]byte(content))
+	if block == nil {
+		return nil, fmt.Errorf("no private key found")
+	}
+
+	block.Headers = pemHeaders(block.Headers)
+
+	buf := pem.EncodeToMemory(block)
+	if pp, found := sec.Get(PassphraseKey); found {
+		return ssh.ParseRawPrivateKeyWithPassphrase(buf, []byte(pp)) //nolint:wrapcheck
+	}
+
+	return ssh.ParseRawPrivateKey(buf) //nolint:wrapcheck
+}
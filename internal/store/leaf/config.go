@@ -0,0 +1,23 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kpitt/gopass/internal/config"
+)
+
+// StoreConfig reads the config file inside of this store. It returns nil if
+// the store does not have one.
+func (s *Store) StoreConfig(ctx context.Context) (*config.StoreConfig, error) {
+	if s.storage == nil || !s.storage.Exists(ctx, config.StoreConfigFile) {
+		return nil, nil //nolint:nilnil
+	}
+
+	buf, err := s.storage.Get(ctx, config.StoreConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", config.StoreConfigFile, err)
+	}
+
+	return config.ParseStoreConfig(buf)
+}
@@ -0,0 +1,54 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreConfig(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	_, _, err = createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+	ctx = backend.WithStorageBackendString(ctx, "fs")
+	s, err := New(ctx, "", tempdir)
+	require.NoError(t, err)
+
+	sc, err := s.StoreConfig(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, sc)
+
+	fn := filepath.Join(tempdir, config.StoreConfigFile)
+	require.NoError(t, os.WriteFile(fn, []byte("cliptimeout: 10\n"), 0o644))
+
+	sc, err = s.StoreConfig(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cliptimeout": "10"}, sc.Map())
+
+	// the config file is not a secret.
+	lst, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.NotContains(t, lst, ".gopass")
+
+	require.NoError(t, os.WriteFile(fn, []byte("cliptimeout: [\n"), 0o644))
+	_, err = s.StoreConfig(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,430 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/recipients"
+	"github.com/kpitt/gopass/internal/set"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/termio"
+	"golang.org/x/exp/maps"
+)
+
+// convertStateFile records an unfinished conversion. It is never committed.
+const convertStateFile = ".gopass-convert"
+
+type convertState struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Keep bool   `json:"keep,omitempty"`
+}
+
+// Convert re-encrypts all secrets of this store with another crypto
+// backend. The recipients of every recipients file are replaced using the
+// mapping from old to new recipients.
+//
+// The new secrets are written next to the old ones, so an interrupted
+// conversion can be resumed by running it again. Only when all secrets
+// are converted the old secrets and recipients files are removed and all
+// changes are committed at once. If keep is true the old files are kept
+// and the store is left in transition mode with both backends.
+func (s *Store) Convert(ctx context.Context, cb backend.CryptoBackend, mapping map[string][]string, keep bool) error {
+	from, err := s.convertSource(ctx, cb, keep)
+	if err != nil {
+		return err
+	}
+
+	to, err := backend.NewCrypto(ctx, cb)
+	if err != nil {
+		return fmt.Errorf("failed to initialize crypto backend %s: %w", cb, err)
+	}
+
+	if from.Ext() == to.Ext() || from.IDFile() == to.IDFile() {
+		return fmt.Errorf("can not convert from %s to %s", from.Name(), to.Name())
+	}
+
+	files, err := s.storage.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list store: %w", err)
+	}
+
+	// map all recipients first, so a missing mapping fails early. Existing
+	// recipients files of the new backend, e.g. in transition mode, are kept.
+	idFiles := map[string][]string{}
+	for _, f := range files {
+		if path.Base(f) != from.IDFile() {
+			continue
+		}
+
+		if nf := path.Join(path.Dir(f), to.IDFile()); s.storage.Exists(ctx, nf) {
+			nrs, err := s.getRecipients(ctx, nf)
+			if err != nil {
+				return err
+			}
+			idFiles[f] = nrs
+
+			continue
+		}
+
+		rs, err := s.getRecipients(ctx, f)
+		if err != nil {
+			return err
+		}
+
+		nrs, err := mapRecipients(ctx, from, rs, mapping)
+		if err != nil {
+			return fmt.Errorf("failed to map recipients of %s: %w", f, err)
+		}
+
+		idFiles[f] = nrs
+	}
+
+	if rs, found := idFiles[from.IDFile()]; found {
+		if err := checkOurIdentity(ctx, to, rs); err != nil {
+			return err
+		}
+	}
+
+	state, err := json.Marshal(convertState{From: from.Name(), To: to.Name(), Keep: keep})
+	if err != nil {
+		return fmt.Errorf("failed to encode conversion state: %w", err)
+	}
+
+	if err := s.storage.Set(ctx, convertStateFile, state); err != nil {
+		return fmt.Errorf("failed to write conversion state: %w", err)
+	}
+
+	secrets := make([]string, 0, len(files))
+	fExt := "." + from.Ext()
+	for _, f := range files {
+		if strings.HasSuffix(f, fExt) {
+			secrets = append(secrets, strings.TrimSuffix(f, fExt))
+		}
+	}
+
+	if err := s.convertSecrets(ctx, from, to, secrets, idFiles); err != nil {
+		return err
+	}
+
+	// write the new recipients files, then remove the old files. The root
+	// recipients file decides which backend is detected, it goes last.
+	for _, f := range sortedIDFiles(idFiles) {
+		nf := path.Join(path.Dir(f), to.IDFile())
+		if err := s.storage.Set(ctx, nf, recipients.Marshal(idFiles[f])); err != nil {
+			return fmt.Errorf("failed to write recipients file %s: %w", nf, err)
+		}
+	}
+
+	if !keep {
+		for _, name := range secrets {
+			if err := s.deleteIfExists(ctx, name+fExt); err != nil {
+				return err
+			}
+		}
+
+		for _, f := range sortedIDFiles(idFiles) {
+			if err := s.deleteIfExists(ctx, f); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.storage.Delete(ctx, convertStateFile); err != nil {
+		return fmt.Errorf("failed to remove conversion state: %w", err)
+	}
+
+	msg := fmt.Sprintf("Converted store from %s to %s", from.Name(), to.Name())
+	if keep {
+		msg = fmt.Sprintf("Started transition from %s to %s", from.Name(), to.Name())
+		s.crypto, s.secondary = from, to
+	} else {
+		s.crypto, s.secondary = to, nil
+	}
+
+	return s.convertCommit(ctx, msg, changedDirs(files, from, to))
+}
+
+// convertSource returns the backend to convert from. That is the backend of
+// an unfinished conversion, if any, or the current backend.
+func (s *Store) convertSource(ctx context.Context, cb backend.CryptoBackend, keep bool) (backend.Crypto, error) {
+	if !s.storage.Exists(ctx, convertStateFile) {
+		if cur, err := backend.CryptoRegistry.Backend(s.crypto.Name()); err == nil && cur == cb {
+			return nil, fmt.Errorf("store already uses %s", s.crypto.Name())
+		}
+
+		if keep && s.secondary != nil {
+			return nil, fmt.Errorf("store is already in transition mode from %s to %s", s.crypto.Name(), s.secondary.Name())
+		}
+
+		return s.crypto, nil
+	}
+
+	buf, err := s.storage.Get(ctx, convertStateFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversion state: %w", err)
+	}
+
+	var st convertState
+	if err := json.Unmarshal(buf, &st); err != nil {
+		return nil, fmt.Errorf("failed to decode conversion state: %w", err)
+	}
+
+	to, err := backend.CryptoRegistry.Backend(st.To)
+	if err != nil || to != cb || st.Keep != keep {
+		return nil, fmt.Errorf("unfinished conversion from %s to %s, resume it first", st.From, st.To)
+	}
+
+	fb, err := backend.CryptoRegistry.Backend(st.From)
+	if err != nil {
+		return nil, fmt.Errorf("unknown crypto backend %q: %w", st.From, err)
+	}
+
+	debug.Log("resuming conversion from %s to %s", st.From, st.To)
+
+	if s.crypto.Name() == st.From {
+		return s.crypto, nil
+	}
+
+	return backend.NewCrypto(ctx, fb)
+}
+
+// convertSecrets encrypts every secret that was not converted yet with the
+// new backend. Existing files of the new backend, e.g. from an interrupted
+// run or transition mode, are only kept if they decrypt to the same secret.
+func (s *Store) convertSecrets(ctx context.Context, from, to backend.Crypto, secrets []string, idFiles map[string][]string) error {
+	bar := termio.NewProgressBar("Converting secrets", int64(len(secrets)))
+	bar.Hidden = !ctxutil.IsTerminal(ctx) || ctxutil.IsHidden(ctx)
+	defer bar.Done()
+
+	for _, name := range secrets {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context canceled")
+		default:
+		}
+
+		bar.Inc()
+
+		ciphertext, err := s.storage.Get(ctx, name+"."+from.Ext())
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		plaintext, err := from.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+
+		nf := name + "." + to.Ext()
+		if s.isConverted(ctx, to, nf, plaintext) {
+			debug.Log("skipping %s - already converted", name)
+
+			continue
+		}
+
+		ciphertext, err = to.Encrypt(ctx, plaintext, idFiles[closestIDFile(name, from.IDFile(), idFiles)])
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", name, err)
+		}
+
+		if err := s.storage.Set(ctx, nf, ciphertext); err != nil {
+			return fmt.Errorf("failed to write %s: %w", nf, err)
+		}
+	}
+
+	return nil
+}
+
+// isConverted returns true if nf exists and decrypts to plaintext.
+func (s *Store) isConverted(ctx context.Context, to backend.Crypto, nf string, plaintext []byte) bool {
+	if !s.storage.Exists(ctx, nf) {
+		return false
+	}
+
+	ciphertext, err := s.storage.Get(ctx, nf)
+	if err != nil {
+		debug.Log("failed to read %s: %s", nf, err)
+
+		return false
+	}
+
+	buf, err := to.Decrypt(ctx, ciphertext)
+	if err != nil {
+		debug.Log("failed to decrypt %s: %s", nf, err)
+
+		return false
+	}
+
+	if !bytes.Equal(buf, plaintext) {
+		debug.Log("%s differs from the secret, converting it again", nf)
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Store) deleteIfExists(ctx context.Context, name string) error {
+	if !s.storage.Exists(ctx, name) {
+		return nil
+	}
+
+	if err := s.storage.Delete(ctx, name); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (s *Store) convertCommit(ctx context.Context, msg string, dirs []string) error {
+	if err := s.storage.Add(ctx, dirs...); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			debug.Log("skipping git add - git not initialized")
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to add changes to git: %w", err)
+	}
+
+	if err := s.storage.Commit(ctx, msg); err != nil {
+		switch {
+		case errors.Is(err, store.ErrGitNotInit):
+			debug.Log("skipping git commit - git not initialized")
+		case errors.Is(err, store.ErrGitNothingToCommit):
+			debug.Log("skipping git commit - nothing to commit")
+		default:
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+
+	return s.reencryptGitPush(ctx)
+}
+
+// changedDirs returns the folders that contain converted files. Adding the
+// folders stages the removed files, too, even those that were removed by an
+// interrupted run.
+func changedDirs(files []string, from, to backend.Crypto) []string {
+	dirs := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		switch {
+		case strings.HasSuffix(f, "."+from.Ext()), strings.HasSuffix(f, "."+to.Ext()):
+		case path.Base(f) == from.IDFile(), path.Base(f) == to.IDFile():
+		default:
+			continue
+		}
+		dirs[path.Dir(f)] = struct{}{}
+	}
+
+	return set.Sorted(maps.Keys(dirs))
+}
+
+// mapRecipients replaces every recipient with the new recipients from the
+// mapping. A recipient matches a mapping entry if either one is a suffix of
+// the other, ignoring case and a 0x prefix, so long GPG key IDs can be
+// mapped by fingerprint and vice versa.
+func mapRecipients(ctx context.Context, from backend.Crypto, rs []string, mapping map[string][]string) ([]string, error) {
+	out := make([]string, 0, len(rs))
+	missing := make([]string, 0, len(rs))
+
+	for _, r := range rs {
+		nrs := lookupRecipient(mapping, r, from.Fingerprint(ctx, r))
+		if len(nrs) < 1 {
+			missing = append(missing, r)
+
+			continue
+		}
+		out = append(out, nrs...)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no mapping for %s", strings.Join(missing, ", "))
+	}
+
+	return out, nil
+}
+
+func lookupRecipient(mapping map[string][]string, ids ...string) []string {
+	for _, id := range ids {
+		if nrs, found := mapping[id]; found {
+			return nrs
+		}
+	}
+
+	norm := func(id string) string {
+		return strings.TrimPrefix(strings.ToUpper(id), "0X")
+	}
+
+	for k, nrs := range mapping {
+		nk := norm(k)
+		for _, id := range ids {
+			nid := norm(id)
+			if len(nk) < 8 || len(nid) < 8 {
+				continue
+			}
+			if strings.HasSuffix(nid, nk) || strings.HasSuffix(nk, nid) {
+				return nrs
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkOurIdentity returns an error if none of the recipients is one of our
+// identities of the backend, so the converted store could not be decrypted
+// by us anymore.
+func checkOurIdentity(ctx context.Context, to backend.Crypto, rs []string) error {
+	ids, err := to.FindIdentities(ctx, rs...)
+	if err != nil {
+		return fmt.Errorf("failed to find %s identities: %w", to.Name(), err)
+	}
+
+	if len(ids) < 1 {
+		return fmt.Errorf("none of the new recipients %s is one of your %s identities, map one of your keys to it", strings.Join(rs, ", "), to.Name())
+	}
+
+	return nil
+}
+
+// closestIDFile returns the recipients file that applies to name.
+func closestIDFile(name, idFile string, idFiles map[string][]string) string {
+	dir := path.Dir(name)
+	for {
+		f := path.Join(dir, idFile)
+		if _, found := idFiles[f]; found {
+			return f
+		}
+
+		if dir == "." || dir == "/" {
+			return idFile
+		}
+
+		dir = path.Dir(dir)
+	}
+}
+
+// sortedIDFiles returns the recipients files, the root file last.
+func sortedIDFiles(idFiles map[string][]string) []string {
+	fs := make([]string, 0, len(idFiles))
+	for f := range idFiles {
+		fs = append(fs, f)
+	}
+
+	sort.Slice(fs, func(i, j int) bool {
+		return strings.Count(fs[i], "/") > strings.Count(fs[j], "/") ||
+			(strings.Count(fs[i], "/") == strings.Count(fs[j], "/") && fs[i] < fs[j])
+	})
+
+	return fs
+}
@@ -0,0 +1,165 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/kpitt/gopass/internal/backend"
+	agecrypto "github.com/kpitt/gopass/internal/backend/crypto/age"
+	"github.com/kpitt/gopass/internal/backend/crypto/plain"
+	"github.com/kpitt/gopass/internal/recipients"
+	"github.com/kpitt/gopass/pkg/appdir"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) { //nolint:paralleltest
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	tempdir := t.TempDir()
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+	t.Setenv("GOPASS_HOMEDIR", tempdir)
+
+	sd := filepath.Join(tempdir, "sub")
+	require.NoError(t, os.WriteFile(filepath.Join(sd, "baz", plain.IDFile), []byte("0xFEEDBEEF\n"), 0o600))
+
+	sec := secrets.NewKV()
+	sec.SetPassword("hunter2")
+	require.NoError(t, s.Set(ctx, "foo/bar/baz", sec))
+	require.NoError(t, s.Set(ctx, "baz/ing/a", sec))
+
+	id1, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	id2, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	// a missing mapping fails before anything is written.
+	err = s.Convert(ctx, backend.Age, map[string][]string{
+		"DEADBEEF": {id1.Recipient().String()},
+	}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "0xFEEDBEEF")
+	assert.NoFileExists(t, filepath.Join(sd, convertStateFile))
+
+	mapping := map[string][]string{
+		"DEADBEEF":   {id1.Recipient().String()},
+		"0xFEEDBEEF": {id2.Recipient().String()},
+	}
+
+	// converting to recipients we have no identity for would lock us out.
+	err = s.Convert(ctx, backend.Age, mapping, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none of the new recipients")
+	assert.NoFileExists(t, filepath.Join(sd, convertStateFile))
+
+	ctx = withAgeIdentity(ctx, t, id1)
+
+	// an interrupted conversion leaves the store untouched.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.Error(t, s.Convert(cctx, backend.Age, mapping, false))
+	assert.FileExists(t, filepath.Join(sd, convertStateFile))
+	assert.FileExists(t, filepath.Join(sd, plain.IDFile))
+	assert.Equal(t, plain.Name, s.Crypto().Name())
+
+	// converting to another backend must finish the first conversion.
+	require.Error(t, s.Convert(ctx, backend.GPGCLI, mapping, false))
+
+	// a truncated file of an interrupted run is converted again.
+	require.NoError(t, os.WriteFile(filepath.Join(sd, "foo", "bar", "baz."+agecrypto.Ext), []byte("age-encryption.org/v1\n"), 0o644))
+
+	require.NoError(t, s.Convert(ctx, backend.Age, mapping, false))
+	assert.Equal(t, "age", s.Crypto().Name())
+	assert.NoFileExists(t, filepath.Join(sd, convertStateFile))
+	assert.NoFileExists(t, filepath.Join(sd, plain.IDFile))
+	assert.NoFileExists(t, filepath.Join(sd, "baz", plain.IDFile))
+	assert.NoFileExists(t, filepath.Join(sd, "foo", "bar", "baz."+plain.Ext))
+
+	buf, err := os.ReadFile(filepath.Join(sd, agecrypto.IDFile))
+	require.NoError(t, err)
+	assert.Equal(t, string(recipients.Marshal([]string{id1.Recipient().String(), id2.Recipient().String()})), string(buf))
+
+	buf, err = os.ReadFile(filepath.Join(sd, "baz", agecrypto.IDFile))
+	require.NoError(t, err)
+	assert.Equal(t, id2.Recipient().String()+"\n", string(buf))
+
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "foo", "bar", "baz."+agecrypto.Ext), id1))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "baz", "ing", "a."+agecrypto.Ext), id2))
+
+	list, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baz/ing/a", "foo/bar/baz"}, list)
+
+	require.Error(t, s.Convert(ctx, backend.Age, mapping, false), "already converted")
+}
+
+// withAgeIdentity stores id as our age identity below GOPASS_HOMEDIR and
+// returns a context that can unlock it.
+func withAgeIdentity(ctx context.Context, t *testing.T, id *age.X25519Identity) context.Context {
+	t.Helper()
+
+	r, err := age.NewScryptRecipient("test")
+	require.NoError(t, err)
+	r.SetWorkFactor(10)
+
+	buf := &bytes.Buffer{}
+	w, err := age.Encrypt(buf, r)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(id.String() + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	fn := filepath.Join(appdir.UserConfig(), "age", "identities")
+	require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0o700))
+	require.NoError(t, os.WriteFile(fn, buf.Bytes(), 0o600))
+
+	return ctxutil.WithPasswordCallback(ctx, func(string, bool) ([]byte, error) {
+		return []byte("test"), nil
+	})
+}
+
+func decryptAge(t *testing.T, path string, id age.Identity) []byte {
+	t.Helper()
+
+	ciphertext, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), id)
+	require.NoError(t, err)
+
+	buf, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return buf
+}
+
+func TestLookupRecipient(t *testing.T) {
+	t.Parallel()
+
+	mapping := map[string][]string{
+		"0xDEADBEEF": {"age1a"},
+		"4A5B6C7D8E9F0011223344556677889900AABBCC": {"age1b"},
+		"alice@example.org":                        {"age1c"},
+	}
+
+	for id, want := range map[string][]string{
+		"0xDEADBEEF":         {"age1a"},
+		"deadbeef":           {"age1a"},
+		"0x1234567ADEADBEEF": {"age1a"},
+		"0x9900AABBCC":       {"age1b"},
+		"alice@example.org":  {"age1c"},
+		"bob@example.org":    nil,
+		"BEEF":               nil,
+	} {
+		assert.Equal(t, want, lookupRecipient(mapping, id), id)
+	}
+}
@@ -0,0 +1,183 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/debug"
+)
+
+// A store in transition mode has the root recipients files of two crypto
+// backends, e.g. .gpg-id and .age-recipients. Every secret is encrypted by
+// both backends and stored side by side, e.g. foo.gpg and foo.age, so the
+// members of a team can move to the new backend one by one. The detected
+// backend is the primary one, the other one is the secondary backend.
+
+// initSecondaryCrypto detects the secondary crypto backend, if any.
+func (s *Store) initSecondaryCrypto(ctx context.Context) error {
+	if s.crypto == nil {
+		return nil
+	}
+
+	primary, err := backend.CryptoRegistry.Backend(s.crypto.Name())
+	if err != nil {
+		return nil //nolint:nilerr
+	}
+
+	for _, be := range backend.CryptoRegistry.Prioritized() {
+		if cb, err := backend.CryptoRegistry.Backend(be.String()); err != nil || cb == primary {
+			continue
+		}
+
+		if err := be.Handles(ctx, s.storage); err != nil {
+			continue
+		}
+
+		debug.Log("Using %s as secondary crypto backend for %s", be, s.storage)
+		sc, err := be.New(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize secondary crypto backend %s: %w", be, err)
+		}
+		s.secondary = sc
+
+		return nil
+	}
+
+	return nil
+}
+
+// Secondary returns the secondary crypto backend of a store in transition
+// mode, or nil.
+func (s *Store) Secondary() backend.Crypto {
+	return s.secondary
+}
+
+// secondaryPassfile returns the name of the secondary copy of a secret.
+func (s *Store) secondaryPassfile(name string) string {
+	return passfile(name, s.secondary)
+}
+
+// secondaryPassfileIfExists returns the name of the secondary copy of a
+// secret, if there is one.
+func (s *Store) secondaryPassfileIfExists(ctx context.Context, name string) string {
+	if s.secondary == nil {
+		return ""
+	}
+
+	p := s.secondaryPassfile(name)
+	if !s.storage.Exists(ctx, p) {
+		return ""
+	}
+
+	return p
+}
+
+// encryptSecondary encrypts the secondary copy of a secret.
+func (s *Store) encryptSecondary(ctx context.Context, name string, plaintext []byte) ([]byte, error) {
+	p := s.secondaryPassfile(name)
+
+	recipients, err := s.getRecipients(ctx, s.findIDFile(ctx, s.secondary, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s recipients for %q: %w", s.secondary.Name(), p, err)
+	}
+
+	ciphertext, err := s.secondary.Encrypt(ctx, plaintext, recipients)
+	if err != nil {
+		debug.Log("Failed to encrypt secret with %s: %s", s.secondary.Name(), err)
+
+		return nil, store.ErrEncrypt
+	}
+
+	return ciphertext, nil
+}
+
+// getSecondaryRevision decrypts the secondary copy of a secret at the given
+// revision.
+func (s *Store) getSecondaryRevision(ctx context.Context, name, revision string) ([]byte, error) {
+	p := s.secondaryPassfile(name)
+
+	ciphertext, err := s.storage.GetRevision(ctx, p, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ciphertext of %q@%q: %w", name, revision, err)
+	}
+
+	content, err := s.secondary.Decrypt(ctx, ciphertext)
+	if err != nil {
+		debug.Log("Failed to decrypt %s with %s: %s", p, s.secondary.Name(), err)
+
+		return nil, store.ErrDecrypt
+	}
+
+	return content, nil
+}
+
+// getSecondary decrypts the secondary copy of a secret.
+func (s *Store) getSecondary(ctx context.Context, name string) ([]byte, error) {
+	p := s.secondaryPassfile(name)
+
+	ciphertext, err := s.storage.Get(ctx, p)
+	if err != nil {
+		debug.Log("File %s not found: %s", p, err)
+
+		return nil, store.ErrNotFound
+	}
+
+	content, err := s.secondary.Decrypt(ctx, ciphertext)
+	if err != nil {
+		debug.Log("Failed to decrypt %s with %s: %s", p, s.secondary.Name(), err)
+
+		return nil, store.ErrDecrypt
+	}
+
+	return content, nil
+}
+
+// passfiles returns the names of all copies of a secret.
+func (s *Store) passfiles(name string) []string {
+	if s.secondary == nil {
+		return []string{s.Passfile(name)}
+	}
+
+	return []string{s.Passfile(name), s.secondaryPassfile(name)}
+}
+
+// fsckCheckSecondary makes sure that both copies of a secret exist and, if
+// both can be decrypted, that they are in sync.
+func (s *Store) fsckCheckSecondary(ctx context.Context, name string, decrypt bool) error {
+	var missing []string
+	for _, p := range s.passfiles(name) {
+		if !s.storage.Exists(ctx, p) {
+			missing = append(missing, p)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing copies %+v", missing)
+	}
+
+	if !decrypt {
+		return nil
+	}
+
+	primary, err := s.getPrimary(ctx, name)
+	if err != nil {
+		debug.Log("can not compare copies of %s: %s", name, err)
+
+		return nil
+	}
+
+	secondary, err := s.getSecondary(ctx, name)
+	if err != nil {
+		debug.Log("can not compare copies of %s: %s", name, err)
+
+		return nil
+	}
+
+	if string(primary) != string(secondary) {
+		return fmt.Errorf("the %s and %s copies differ", s.crypto.Name(), s.secondary.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/kpitt/gopass/internal/backend"
+	agecrypto "github.com/kpitt/gopass/internal/backend/crypto/age"
+	"github.com/kpitt/gopass/internal/backend/crypto/plain"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitionMode(t *testing.T) { //nolint:paralleltest
+	td := t.TempDir()
+	t.Setenv("GOPASS_HOMEDIR", td)
+
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = backend.WithStorageBackendString(ctx, "fs")
+
+	// the age identity is not available, only the plain copies can be read.
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	sd := filepath.Join(td, "store")
+	require.NoError(t, os.MkdirAll(sd, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(sd, agecrypto.IDFile), []byte(id.Recipient().String()+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sd, plain.IDFile), []byte("0xDEADBEEF\n"), 0o600))
+
+	s, err := New(ctx, "", sd)
+	require.NoError(t, err)
+	require.NotNil(t, s.Secondary())
+	assert.Equal(t, "age", s.Crypto().Name())
+	assert.Equal(t, plain.Name, s.Secondary().Name())
+
+	sec := secrets.NewKV()
+	sec.SetPassword("hunter2")
+	require.NoError(t, s.Set(ctx, "foo", sec))
+	assert.FileExists(t, filepath.Join(sd, "foo."+agecrypto.Ext))
+	assert.FileExists(t, filepath.Join(sd, "foo."+plain.Ext))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "foo."+agecrypto.Ext), id))
+
+	// a secondary copy that can not be encrypted leaves both copies alone.
+	require.NoError(t, os.Rename(filepath.Join(sd, plain.IDFile), filepath.Join(td, plain.IDFile)))
+	changed := secrets.NewKV()
+	changed.SetPassword("changed")
+	require.Error(t, s.Set(ctx, "foo", changed))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "foo."+agecrypto.Ext), id))
+	require.NoError(t, os.Rename(filepath.Join(td, plain.IDFile), filepath.Join(sd, plain.IDFile)))
+
+	got, err := s.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", got.Password())
+
+	require.NoError(t, s.Move(ctx, "foo", "bar"))
+	assert.NoFileExists(t, filepath.Join(sd, "foo."+plain.Ext))
+	assert.FileExists(t, filepath.Join(sd, "bar."+agecrypto.Ext))
+	assert.FileExists(t, filepath.Join(sd, "bar."+plain.Ext))
+
+	// a secret with only a secondary copy exists.
+	require.NoError(t, os.Remove(filepath.Join(sd, "bar."+agecrypto.Ext)))
+	assert.True(t, s.Exists(ctx, "bar"))
+	list, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar"}, list)
+
+	// fsck restores a missing copy.
+	require.NoError(t, s.Fsck(WithFsckDecrypt(ctx, true), ""))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "bar."+agecrypto.Ext), id))
+
+	require.NoError(t, s.Delete(ctx, "bar"))
+	assert.NoFileExists(t, filepath.Join(sd, "bar."+agecrypto.Ext))
+	assert.NoFileExists(t, filepath.Join(sd, "bar."+plain.Ext))
+	assert.False(t, s.Exists(ctx, "bar"))
+
+	// a secondary copy alone can be removed, too.
+	require.NoError(t, os.WriteFile(filepath.Join(sd, "baz."+plain.Ext), sec.Bytes(), 0o644))
+	require.NoError(t, s.Delete(ctx, "baz"))
+	assert.NoFileExists(t, filepath.Join(sd, "baz."+plain.Ext))
+}
+
+func TestConvertKeep(t *testing.T) { //nolint:paralleltest
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	td := t.TempDir()
+	s, err := createSubStore(td)
+	require.NoError(t, err)
+	t.Setenv("GOPASS_HOMEDIR", td)
+
+	sd := filepath.Join(td, "sub")
+	sec := secrets.NewKV()
+	sec.SetPassword("hunter2")
+	require.NoError(t, s.Set(ctx, "foo/bar/baz", sec))
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	ctx = withAgeIdentity(ctx, t, id)
+
+	mapping := map[string][]string{
+		"0xDEADBEEF": {id.Recipient().String()},
+		"0xFEEDBEEF": {id.Recipient().String()},
+	}
+
+	require.NoError(t, s.Convert(ctx, backend.Age, mapping, true))
+	require.NotNil(t, s.Secondary())
+	assert.Equal(t, plain.Name, s.Crypto().Name())
+	assert.Equal(t, "age", s.Secondary().Name())
+	assert.FileExists(t, filepath.Join(sd, plain.IDFile))
+	assert.FileExists(t, filepath.Join(sd, agecrypto.IDFile))
+	assert.FileExists(t, filepath.Join(sd, "foo", "bar", "baz."+plain.Ext))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "foo", "bar", "baz."+agecrypto.Ext), id))
+
+	require.Error(t, s.Convert(ctx, backend.Age, mapping, true), "already in transition mode")
+
+	// new secrets are written with both backends.
+	require.NoError(t, s.Set(ctx, "new", sec))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "new."+agecrypto.Ext), id))
+
+	// a secondary copy that drifted from the primary one is replaced.
+	drifted := &bytes.Buffer{}
+	w, err := age.Encrypt(drifted, id.Recipient())
+	require.NoError(t, err)
+	_, err = w.Write([]byte("outdated\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(sd, "new."+agecrypto.Ext), drifted.Bytes(), 0o644))
+
+	// finishing the transition keeps the age recipients and needs no mapping.
+	require.NoError(t, s.Convert(ctx, backend.Age, nil, false))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "new."+agecrypto.Ext), id))
+	assert.Nil(t, s.Secondary())
+	assert.Equal(t, "age", s.Crypto().Name())
+	assert.NoFileExists(t, filepath.Join(sd, plain.IDFile))
+	assert.NoFileExists(t, filepath.Join(sd, "new."+plain.Ext))
+	assert.FileExists(t, filepath.Join(sd, agecrypto.IDFile))
+}
+
+func TestTransitionUndelete(t *testing.T) { //nolint:paralleltest
+	td := t.TempDir()
+	t.Setenv("GOPASS_HOMEDIR", td)
+
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithGitCommit(ctx, true)
+	ctx = ctxutil.WithUsername(ctx, "foo")
+	ctx = ctxutil.WithEmail(ctx, "foo@example.org")
+	ctx = backend.WithStorageBackendString(ctx, "fs")
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	sd := filepath.Join(td, "store")
+	require.NoError(t, os.MkdirAll(sd, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(sd, agecrypto.IDFile), []byte(id.Recipient().String()+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sd, plain.IDFile), []byte("0xDEADBEEF\n"), 0o600))
+
+	s, err := New(ctx, "", sd)
+	require.NoError(t, err)
+	require.NotNil(t, s.Secondary())
+	require.NoError(t, s.GitInit(ctx))
+
+	// a secret with only a secondary copy is listed and restored, too.
+	sec := secrets.NewKV()
+	sec.SetPassword("hunter2")
+	require.NoError(t, os.WriteFile(filepath.Join(sd, "foo."+plain.Ext), sec.Bytes(), 0o644))
+	require.NoError(t, s.storage.Add(ctx, "foo."+plain.Ext))
+	require.NoError(t, s.storage.Commit(ctx, "add foo"))
+	require.NoError(t, s.Delete(ctx, "foo"))
+
+	dels, err := s.Deleted(ctx)
+	require.NoError(t, err)
+	require.Len(t, dels, 1)
+	assert.Equal(t, "foo", dels[0].Name)
+
+	require.NoError(t, s.Undelete(ctx, "foo"))
+	assert.FileExists(t, filepath.Join(sd, "foo."+agecrypto.Ext))
+	assert.Equal(t, sec.Bytes(), decryptAge(t, filepath.Join(sd, "foo."+agecrypto.Ext), id))
+}
@@ -73,6 +73,12 @@ func (s *Store) fsckCheckEntry(ctx context.Context, name string) error {
 		out.Warningf(ctx, "Checking recipients for %s failed: %s", name, err)
 	}
 
+	if s.secondary != nil {
+		if err := s.fsckCheckSecondary(ctx, name, IsFsckDecrypt(ctx)); err != nil {
+			out.Errorf(ctx, "Copies of %s are out of sync: %s\nRun fsck with the --decrypt flag to re-encrypt it automatically, or edit this secret yourself.", name, err)
+		}
+	}
+
 	// make sure we can actually decode this secret
 	// if this fails there is no way we could fix this
 	if !IsFsckDecrypt(ctx) {
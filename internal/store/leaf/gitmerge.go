@@ -0,0 +1,89 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/debug"
+)
+
+// The git merge driver gets the versions of a secret file as plain files,
+// e.g. foo.gpg or foo.age in transition mode, and must return a file
+// encrypted with the same backend.
+
+// fileCrypto returns the crypto backend of a secret file and the name of
+// the secret.
+func (s *Store) fileCrypto(file string) (backend.Crypto, string, error) {
+	file = filepath.ToSlash(file)
+
+	for _, c := range []backend.Crypto{s.crypto, s.secondary} {
+		if c == nil {
+			continue
+		}
+
+		if ext := "." + c.Ext(); strings.HasSuffix(file, ext) {
+			return c, strings.TrimSuffix(file, ext), nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("%s is not a secret", file)
+}
+
+// DecryptFile decrypts one version of a secret file. An empty file, e.g. a
+// missing common ancestor, decrypts to an empty secret.
+func (s *Store) DecryptFile(ctx context.Context, file string, ciphertext []byte) ([]byte, error) {
+	c, _, err := s.fileCrypto(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < 1 {
+		return nil, nil
+	}
+
+	content, err := c.Decrypt(ctx, ciphertext)
+	if err != nil {
+		debug.Log("Failed to decrypt %s with %s: %s", file, c.Name(), err)
+
+		return nil, store.ErrDecrypt
+	}
+
+	return content, nil
+}
+
+// EncryptFile encrypts a secret file for the current recipients.
+func (s *Store) EncryptFile(ctx context.Context, file string, plaintext []byte) ([]byte, error) {
+	c, name, err := s.fileCrypto(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	if c == s.crypto {
+		recipients, err = s.useableKeys(ctx, name)
+	} else {
+		recipients, err = s.getRecipients(ctx, s.findIDFile(ctx, c, name))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s recipients for %q: %w", c.Name(), file, err)
+	}
+
+	if c == s.crypto {
+		// make sure the encryptor can decrypt later
+		recipients = s.ensureOurKeyID(ctx, recipients)
+	}
+
+	ciphertext, err := c.Encrypt(ctx, plaintext, recipients)
+	if err != nil {
+		debug.Log("Failed to encrypt %s with %s: %s", file, c.Name(), err)
+
+		return nil, store.ErrEncrypt
+	}
+
+	return ciphertext, nil
+}
@@ -0,0 +1,34 @@
+package leaf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecryptFile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	s, err := createSubStore(t.TempDir())
+	require.NoError(t, err)
+
+	ciphertext, err := s.EncryptFile(ctx, "foo/bar.txt", []byte("secret\n"))
+	require.NoError(t, err)
+
+	plaintext, err := s.DecryptFile(ctx, "foo/bar.txt", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret\n", string(plaintext))
+
+	plaintext, err = s.DecryptFile(ctx, "foo/bar.txt", nil)
+	require.NoError(t, err)
+	assert.Empty(t, plaintext)
+
+	_, err = s.EncryptFile(ctx, ".gitattributes", []byte("secret\n"))
+	assert.Error(t, err)
+}
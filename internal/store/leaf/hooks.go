@@ -0,0 +1,94 @@
+package leaf
+
+import (
+	"context"
+
+	"github.com/kpitt/gopass/internal/hook"
+	"github.com/kpitt/gopass/internal/out"
+	"github.com/kpitt/gopass/internal/queue"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets/secparse"
+)
+
+// hooked runs fn between the pre and post hooks of ev. A failing pre hook
+// aborts the operation. The post hook is added to the queue so that it runs
+// after the commit that fn might have queued.
+func (s *Store) hooked(ctx context.Context, ev *hook.Event, fn func() error) error {
+	if err := hook.Run(ctx, hook.Pre, ev); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	t := queue.GetQueue(ctx).Add(func(ctx context.Context) error {
+		hook.RunPost(ctx, ev)
+
+		return nil
+	})
+
+	return t(ctx)
+}
+
+// syncHooked runs push between the sync hooks for name. A failing pre-sync
+// hook only skips the push, the change is already committed at this point.
+func (s *Store) syncHooked(ctx context.Context, name string, push func() error) error {
+	ev := s.hookEvent(hook.OpSync, name)
+	if err := hook.Run(ctx, hook.Pre, ev); err != nil {
+		out.Warningf(ctx, "Skipped push: %s", err)
+
+		return nil
+	}
+
+	if err := push(); err != nil {
+		return err
+	}
+
+	hook.RunPost(ctx, ev)
+
+	return nil
+}
+
+func (s *Store) hookEvent(op, name string) *hook.Event {
+	return &hook.Event{
+		Op:    op,
+		Mount: s.alias,
+		Name:  name,
+	}
+}
+
+// setEvent returns the event for writing sec to name. The changed keys are
+// only included if they are enabled in the hook config.
+func (s *Store) setEvent(ctx context.Context, name string, sec gopass.Byter) *hook.Event {
+	ev := s.hookEvent(hook.OpSet, name)
+	if !hook.Enabled(ctx, hook.OpSet) || !hook.GetConfig(ctx).Changes {
+		return ev
+	}
+
+	ns, ok := sec.(gopass.Secret)
+	if !ok {
+		var err error
+		if ns, err = secparse.Parse(sec.Bytes()); err != nil {
+			debug.Log("failed to parse %s: %s", name, err)
+
+			return ev
+		}
+	}
+
+	var old gopass.Secret
+	if s.Exists(ctx, name) {
+		var err error
+		if old, err = s.Get(ctxutil.WithShowParsing(ctx, true), name); err != nil {
+			debug.Log("failed to decrypt %s: %s", name, err)
+
+			return ev
+		}
+	}
+
+	ev.Changes = hook.Diff(old, ns)
+
+	return ev
+}
@@ -0,0 +1,142 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/hook"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) { //nolint:paralleltest
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+
+	buf := &bytes.Buffer{}
+	hook.Stderr = buf
+
+	defer func() {
+		hook.Stderr = os.Stderr
+	}()
+
+	tempdir := t.TempDir()
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+	ctx = backend.WithStorageBackendString(ctx, "fs")
+	ctx = ctxutil.WithShowParsing(ctx, true)
+	s, err := New(ctx, "work", tempdir)
+	require.NoError(t, err)
+
+	// the pre-set hook rejects all names below forbidden/.
+	policy := filepath.Join(t.TempDir(), "policy.sh")
+	require.NoError(t, os.WriteFile(policy, []byte(`#!/bin/sh
+case "$GOPASS_HOOK_NAME" in forbidden/*) echo "not allowed"; exit 1;; esac
+`), 0o755))
+
+	ctx = hook.WithConfig(ctx, &hook.Config{
+		PreSet:     policy,
+		PostSet:    "cat",
+		PostMove:   "echo {{ .Op }} {{ .From }} {{ .Name }}",
+		PostDelete: "echo {{ .Op }} {{ .Mount }}/{{ .Name }}",
+		Changes:    true,
+	})
+
+	sec := secrets.NewKV()
+	sec.SetPassword("foo")
+	require.NoError(t, sec.Set("user", "bar"))
+
+	t.Run("pre hook aborts", func(t *testing.T) { //nolint:paralleltest
+		buf.Reset()
+
+		assert.Error(t, s.Set(ctx, "forbidden/foo", sec))
+		assert.False(t, s.Exists(ctx, "forbidden/foo"))
+		assert.Equal(t, "not allowed\n", buf.String())
+	})
+
+	t.Run("changed keys", func(t *testing.T) { //nolint:paralleltest
+		buf.Reset()
+
+		require.NoError(t, s.Set(ctx, "hooked/a", sec))
+		assert.Equal(t, `{"op":"set","stage":"post","mount":"work","name":"hooked/a",`+
+			`"changes":{"created":true,"password":false,"body":false,"added":["user"]}}`+"\n", buf.String())
+
+		buf.Reset()
+		require.NoError(t, sec.Set("user", "baz"))
+		require.NoError(t, s.Set(ctx, "hooked/a", sec))
+		assert.Equal(t, `{"op":"set","stage":"post","mount":"work","name":"hooked/a",`+
+			`"changes":{"created":false,"password":false,"body":false,"changed":["user"]}}`+"\n", buf.String())
+	})
+
+	t.Run("move and delete", func(t *testing.T) { //nolint:paralleltest
+		buf.Reset()
+
+		require.NoError(t, s.Move(ctx, "hooked/a", "hooked/b"))
+		require.NoError(t, s.Delete(ctx, "hooked/b"))
+		assert.Equal(t, "move hooked/a hooked/b\ndelete work/hooked/b\n", buf.String())
+	})
+}
+
+func TestSyncHooks(t *testing.T) { //nolint:paralleltest
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+
+	buf := &bytes.Buffer{}
+	hook.Stderr = buf
+
+	defer func() {
+		hook.Stderr = os.Stderr
+	}()
+
+	tempdir := t.TempDir()
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+	ctx = backend.WithStorageBackendString(ctx, "fs")
+	ctx = ctxutil.WithGitCommit(ctx, true)
+	ctx = ctxutil.WithUsername(ctx, "foo")
+	ctx = ctxutil.WithEmail(ctx, "foo@example.org")
+	s, err := New(ctx, "work", tempdir)
+	require.NoError(t, err)
+
+	require.NoError(t, s.GitInit(ctx))
+
+	ctx = hook.WithConfig(ctx, &hook.Config{
+		PreSync:  "sh -c 'echo no sync; exit 1'",
+		PostSet:  "echo {{ .Stage }} {{ .Op }} {{ .Name }}",
+		PostSync: "echo {{ .Stage }} {{ .Op }}",
+	})
+
+	sec := secrets.NewKV()
+	sec.SetPassword("foo")
+
+	// the pre-sync hook only skips the push, the secret is committed and
+	// nothing is left staged.
+	require.NoError(t, s.Set(ctx, "synced", sec))
+	assert.Equal(t, "no sync\npost set synced\n", buf.String())
+
+	revs, err := s.ListRevisions(ctx, "synced")
+	require.NoError(t, err)
+	assert.Len(t, revs, 1)
+
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = tempdir
+	status, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Empty(t, string(status))
+}
@@ -26,7 +26,15 @@ func (s *Store) Link(ctx context.Context, from, to string) error {
 
 	debug.Log("created symlink from %q to %q", from, to)
 
-	if err := s.storage.Add(ctx, s.Passfile(to)); err != nil {
+	paths := []string{s.Passfile(to)}
+	if spFrom := s.secondaryPassfileIfExists(ctx, from); spFrom != "" {
+		if err := s.storage.Link(ctx, spFrom, s.secondaryPassfile(to)); err != nil {
+			return fmt.Errorf("failed to create symlink from %q to %q: %w", from, to, err)
+		}
+		paths = append(paths, s.secondaryPassfile(to))
+	}
+
+	if err := s.storage.Add(ctx, paths...); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -4,15 +4,32 @@ import (
 	"context"
 	"strings"
 
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/set"
 	"github.com/kpitt/gopass/pkg/debug"
 )
 
 // Sep is the separator used in lists to separate folders from entries.
 var Sep = "/"
 
-// List will list all entries in this store.
+// List will list all entries in this store. In transition mode this
+// includes secrets that only have a secondary copy.
 func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
-	if s.storage == nil || s.crypto == nil {
+	names, err := s.list(ctx, prefix, s.crypto)
+	if err != nil || s.secondary == nil {
+		return names, err
+	}
+
+	snames, err := s.list(ctx, prefix, s.secondary)
+	if err != nil {
+		return nil, err
+	}
+
+	return set.Sorted(append(names, snames...)), nil
+}
+
+func (s *Store) list(ctx context.Context, prefix string, crypto backend.Crypto) ([]string, error) {
+	if s.storage == nil || crypto == nil {
 		return nil, nil
 	}
 
@@ -23,7 +40,7 @@ func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 
 	debug.Log("Listing %s: %+v\n", prefix, lst)
 	out := make([]string, 0, len(lst))
-	cExt := "." + s.crypto.Ext()
+	cExt := "." + crypto.Ext()
 	for _, path := range lst {
 		if !strings.HasSuffix(path, cExt) {
 			continue
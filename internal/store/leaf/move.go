@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/kpitt/gopass/internal/hook"
 	"github.com/kpitt/gopass/internal/queue"
 	"github.com/kpitt/gopass/internal/store"
 	"github.com/kpitt/gopass/pkg/ctxutil"
@@ -22,6 +23,12 @@ func (s *Store) Copy(ctx context.Context, from, to string) error {
 		return fmt.Errorf("recursive operations are not supported")
 	}
 
+	return s.hooked(ctx, s.hookEvent(hook.OpSet, to), func() error {
+		return s.copy(ctx, from, to)
+	})
+}
+
+func (s *Store) copy(ctx context.Context, from, to string) error {
 	// try direct copy first
 	err := s.directMove(ctx, from, to, false)
 	if err == nil {
@@ -37,7 +44,7 @@ func (s *Store) Copy(ctx context.Context, from, to string) error {
 		return fmt.Errorf("failed to get %q from store: %w", from, err)
 	}
 
-	if err := s.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Copied from %s to %s", from, to)), to, content); err != nil {
+	if err := s.set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Copied from %s to %s", from, to)), to, content); err != nil {
 		return fmt.Errorf("failed to save %q to store: %w", to, err)
 	}
 
@@ -54,6 +61,15 @@ func (s *Store) Move(ctx context.Context, from, to string) error {
 		return fmt.Errorf("recursive operations are not supported")
 	}
 
+	ev := s.hookEvent(hook.OpMove, to)
+	ev.From = from
+
+	return s.hooked(ctx, ev, func() error {
+		return s.move(ctx, from, to)
+	})
+}
+
+func (s *Store) move(ctx context.Context, from, to string) error {
 	// try direct move first
 	err := s.directMove(ctx, from, to, true)
 	if err == nil {
@@ -70,11 +86,11 @@ func (s *Store) Move(ctx context.Context, from, to string) error {
 		return fmt.Errorf("failed to decrypt %q: %w", from, err)
 	}
 
-	if err := s.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Move from %s to %s", from, to)), to, content); err != nil {
+	if err := s.set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Move from %s to %s", from, to)), to, content); err != nil {
 		return fmt.Errorf("failed to write %q: %w", to, err)
 	}
 
-	if err := s.Delete(ctx, from); err != nil {
+	if err := s.delete(ctx, from, false); err != nil {
 		return fmt.Errorf("failed to delete %q: %w", from, err)
 	}
 
@@ -92,6 +108,16 @@ func (s *Store) directMove(ctx context.Context, from, to string, del bool) error
 		return fmt.Errorf("failed to move %q to %q: %w", from, to, err)
 	}
 
+	paths := []string{pFrom, pTo}
+	// fsck restores the secondary copy if it is missing.
+	if spFrom := s.secondaryPassfileIfExists(ctx, from); spFrom != "" {
+		spTo := s.secondaryPassfile(to)
+		if err := s.storage.Move(ctx, spFrom, spTo, del); err != nil {
+			return fmt.Errorf("failed to move %q to %q: %w", spFrom, spTo, err)
+		}
+		paths = append(paths, spFrom, spTo)
+	}
+
 	// It is not possible to perform concurrent git add and git commit commands
 	// so we need to skip this step when using concurrency and perform them
 	// at the end of the batch processing.
@@ -101,7 +127,7 @@ func (s *Store) directMove(ctx context.Context, from, to string, del bool) error
 		return nil
 	}
 
-	if err := s.storage.Add(ctx, pFrom, pTo); err != nil {
+	if err := s.storage.Add(ctx, paths...); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -124,12 +150,16 @@ func (s *Store) directMove(ctx context.Context, from, to string, del bool) error
 
 // Delete will remove an single entry from the store.
 func (s *Store) Delete(ctx context.Context, name string) error {
-	return s.delete(ctx, name, false)
+	return s.hooked(ctx, s.hookEvent(hook.OpDelete, name), func() error {
+		return s.delete(ctx, name, false)
+	})
 }
 
 // Prune will remove a subtree from the Store.
 func (s *Store) Prune(ctx context.Context, tree string) error {
-	return s.delete(ctx, tree, true)
+	return s.hooked(ctx, s.hookEvent(hook.OpDelete, tree), func() error {
+		return s.delete(ctx, tree, true)
+	})
 }
 
 // delete will either delete one file or an directory tree depending on the
@@ -144,8 +174,15 @@ func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
 	}
 	if err := s.deleteSingle(ctx, path); err != nil {
 		// might fail if we deleted the root of a tree which isn't a secret
-		// itself
-		if !recurse {
+		// itself or if the secret only has a secondary copy
+		secondaryOnly := errors.Is(err, store.ErrNotFound) && s.secondaryPassfileIfExists(ctx, name) != ""
+		if !recurse && !secondaryOnly {
+			return err
+		}
+	}
+
+	if s.secondary != nil {
+		if err := s.deleteSingle(ctx, s.secondaryPassfile(name)); err != nil && !errors.Is(err, store.ErrNotFound) {
 			return err
 		}
 	}
@@ -154,6 +191,10 @@ func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
 		return nil
 	}
 
+	return s.commitAndPushDelete(ctx, name)
+}
+
+func (s *Store) commitAndPushDelete(ctx context.Context, name string) error {
 	if err := s.storage.Commit(ctx, fmt.Sprintf("Remove %s from store.", name)); err != nil {
 		switch {
 		case errors.Is(err, store.ErrGitNotInit):
@@ -165,15 +206,17 @@ func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
 		}
 	}
 
-	if err := s.storage.Push(ctx, "", ""); err != nil {
-		if errors.Is(err, store.ErrGitNotInit) || errors.Is(err, store.ErrGitNoRemote) {
-			return nil
-		}
+	return s.syncHooked(ctx, name, func() error {
+		if err := s.storage.Push(ctx, "", ""); err != nil {
+			if errors.Is(err, store.ErrGitNotInit) || errors.Is(err, store.ErrGitNoRemote) {
+				return nil
+			}
 
-		return fmt.Errorf("failed to push change to git remote: %w", err)
-	}
+			return fmt.Errorf("failed to push change to git remote: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (s *Store) deleteRecurse(ctx context.Context, name, path string) error {
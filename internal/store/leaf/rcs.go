@@ -13,8 +13,14 @@ import (
 
 // GitInit initializes the git storage.
 func (s *Store) GitInit(ctx context.Context) error {
-	// The desired storage type for `GitInit` is always GitFS.
-	storage, err := backend.InitStorage(ctx, backend.GitFS, s.path)
+	// The desired storage type for `GitInit` is GitFS unless the pure Go
+	// implementation was requested explicitly.
+	be := backend.GitFS
+	if backend.GetStorageBackend(ctx) == backend.GoGit {
+		be = backend.GoGit
+	}
+
+	storage, err := backend.InitStorage(ctx, be, s.path)
 	if err != nil {
 		return err
 	}
@@ -32,6 +38,20 @@ func (s *Store) ListRevisions(ctx context.Context, name string) ([]backend.Revis
 
 // GetRevision will retrieve a single revision from the backend.
 func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.Secret, error) {
+	content, err := s.getRevision(ctx, name, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	sec, err := secparse.Parse(content)
+	if err != nil {
+		debug.Log("Failed to parse YAML: %s", err)
+	}
+
+	return sec, nil
+}
+
+func (s *Store) getRevision(ctx context.Context, name, revision string) ([]byte, error) {
 	p := s.Passfile(name)
 	ciphertext, err := s.storage.GetRevision(ctx, p, revision)
 	if err != nil {
@@ -45,10 +65,5 @@ func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.
 		return nil, store.ErrDecrypt
 	}
 
-	sec, err := secparse.Parse(content)
-	if err != nil {
-		debug.Log("Failed to parse YAML: %s", err)
-	}
-
-	return sec, nil
+	return content, nil
 }
@@ -2,6 +2,7 @@ package leaf
 
 import (
 	"context"
+	"errors"
 
 	"github.com/kpitt/gopass/internal/out"
 	"github.com/kpitt/gopass/internal/store"
@@ -14,17 +15,21 @@ import (
 
 // Get returns the plaintext of a single key.
 func (s *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
-	p := s.Passfile(name)
-
-	ciphertext, err := s.storage.Get(ctx, p)
-	if err != nil {
-		debug.Log("File %s not found: %s", p, err)
-
-		return nil, store.ErrNotFound
+	content, err := s.getPrimary(ctx, name)
+	if err != nil && s.secondary != nil {
+		// in transition mode the secret can be decrypted with either backend.
+		debug.Log("Failed to get %s with %s, trying %s: %s", name, s.crypto.Name(), s.secondary.Name(), err)
+
+		if sc, serr := s.getSecondary(ctx, name); serr == nil {
+			content, err = sc, nil
+		}
 	}
 
-	content, err := s.crypto.Decrypt(ctx, ciphertext)
 	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+
 		out.Errorf(ctx, "Decryption failed: %s\n%s", err, string(content))
 
 		return nil, store.ErrDecrypt
@@ -36,3 +41,17 @@ func (s *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
 
 	return secparse.Parse(content)
 }
+
+func (s *Store) getPrimary(ctx context.Context, name string) ([]byte, error) {
+	p := s.Passfile(name)
+
+	ciphertext, err := s.storage.Get(ctx, p)
+	if err != nil {
+		debug.Log("File %s not found: %s", p, err)
+
+		return nil, store.ErrNotFound
+	}
+
+	// the content is returned with the error, it may contain details.
+	return s.crypto.Decrypt(ctx, ciphertext)
+}
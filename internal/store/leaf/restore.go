@@ -0,0 +1,107 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/store"
+	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
+)
+
+// Restore writes the content of an older revision of a secret as a new
+// revision, so the history is kept.
+func (s *Store) Restore(ctx context.Context, name, revision string) error {
+	content, err := s.getRevision(ctx, name, revision)
+	if err != nil {
+		return err
+	}
+
+	ctx = ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Restored revision %s", revision))
+
+	return s.Set(ctx, name, secrets.ParsePlain(content))
+}
+
+// Deleted lists the secrets that were deleted and do not exist anymore,
+// newest first. Only the last deletion of every secret is included. In
+// transition mode secrets of which only the secondary copy was deleted are
+// included as well.
+func (s *Store) Deleted(ctx context.Context) ([]backend.Deletion, error) {
+	dels, err := s.storage.Deleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exts := []string{"." + s.crypto.Ext()}
+	if s.secondary != nil {
+		exts = append(exts, "."+s.secondary.Ext())
+	}
+
+	seen := make(map[string]struct{}, len(dels))
+	out := make([]backend.Deletion, 0, len(dels))
+
+	for _, d := range dels {
+		name, ok := trimExt(d.Name, exts)
+		if !ok {
+			continue
+		}
+
+		if _, found := seen[name]; found {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		if s.Exists(ctx, name) {
+			continue
+		}
+
+		d.Name = name
+		out = append(out, d)
+	}
+
+	return out, nil
+}
+
+// Undelete restores a deleted secret with the content it had before it was
+// deleted.
+func (s *Store) Undelete(ctx context.Context, name string) error {
+	name = strings.TrimPrefix(name, "/")
+
+	dels, err := s.Deleted(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range dels {
+		if d.Name != name {
+			continue
+		}
+
+		content, err := s.getRevision(ctx, name, d.Revision.Hash+"^")
+		if err != nil && s.secondary != nil {
+			content, err = s.getSecondaryRevision(ctx, name, d.Revision.Hash+"^")
+		}
+		if err != nil {
+			return err
+		}
+
+		ctx = ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Restored deleted secret from revision %s", d.Revision.Hash))
+
+		return s.Set(ctx, name, secrets.ParsePlain(content))
+	}
+
+	return store.ErrNotFound
+}
+
+// trimExt removes the first matching extension from name.
+func trimExt(name string, exts []string) (string, bool) {
+	for _, ext := range exts {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext), true
+		}
+	}
+
+	return name, false
+}
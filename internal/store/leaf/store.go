@@ -13,10 +13,11 @@ import (
 
 // Store is a password store.
 type Store struct {
-	alias   string
-	path    string
-	crypto  backend.Crypto
-	storage backend.Storage
+	alias     string
+	path      string
+	crypto    backend.Crypto
+	secondary backend.Crypto
+	storage   backend.Storage
 }
 
 // Init initializes this sub store.
@@ -70,6 +71,10 @@ func New(ctx context.Context, alias, path string) (*Store, error) {
 
 	debug.Log("Crypto for %s => %s initialized as %v", alias, path, s.crypto)
 
+	if err := s.initSecondaryCrypto(ctx); err != nil {
+		return nil, fmt.Errorf("failed to init secondary crypto backend: %w", err)
+	}
+
 	return s, nil
 }
 
@@ -77,7 +82,13 @@ func New(ctx context.Context, alias, path string) (*Store, error) {
 // it walks up from the given filename until it finds a directory containing
 // a gpg id file or it leaves the scope of storage.
 func (s *Store) idFile(ctx context.Context, name string) string {
-	if s.crypto == nil {
+	return s.findIDFile(ctx, s.crypto, name)
+}
+
+// findIDFile returns the path to the recipient list of the given crypto
+// backend for name.
+func (s *Store) findIDFile(ctx context.Context, crypto backend.Crypto, name string) string {
+	if crypto == nil {
 		return ""
 	}
 
@@ -95,7 +106,7 @@ func (s *Store) idFile(ctx context.Context, name string) string {
 			break
 		}
 
-		gfn := filepath.Join(fn, s.crypto.IDFile())
+		gfn := filepath.Join(fn, crypto.IDFile())
 		if s.storage.Exists(ctx, gfn) {
 			return gfn
 		}
@@ -103,7 +114,7 @@ func (s *Store) idFile(ctx context.Context, name string) string {
 		fn = filepath.Dir(fn)
 	}
 
-	return s.crypto.IDFile()
+	return crypto.IDFile()
 }
 
 // idFiles returns the path to all id files in this store.
@@ -151,9 +162,10 @@ func (s *Store) IsDir(ctx context.Context, name string) bool {
 	return s.storage.IsDir(ctx, name)
 }
 
-// Exists checks the existence of a single entry.
+// Exists checks the existence of a single entry. In transition mode an
+// entry with only a secondary copy exists, too.
 func (s *Store) Exists(ctx context.Context, name string) bool {
-	return s.storage.Exists(ctx, s.Passfile(name))
+	return s.storage.Exists(ctx, s.Passfile(name)) || s.secondaryPassfileIfExists(ctx, name) != ""
 }
 
 func (s *Store) useableKeys(ctx context.Context, name string) ([]string, error) {
@@ -176,7 +188,11 @@ func (s *Store) useableKeys(ctx context.Context, name string) ([]string, error)
 
 // Passfile returns the name of gpg file on disk, for the given key/name.
 func (s *Store) Passfile(name string) string {
-	return strings.TrimPrefix(name+"."+s.crypto.Ext(), "/")
+	return passfile(name, s.crypto)
+}
+
+func passfile(name string, crypto backend.Crypto) string {
+	return strings.TrimPrefix(name+"."+crypto.Ext(), "/")
 }
 
 // String implement fmt.Stringer.
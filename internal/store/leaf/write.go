@@ -20,6 +20,12 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return fmt.Errorf("invalid secret name: %s", name)
 	}
 
+	return s.hooked(ctx, s.setEvent(ctx, name, sec), func() error {
+		return s.set(ctx, name, sec)
+	})
+}
+
+func (s *Store) set(ctx context.Context, name string, sec gopass.Byter) error {
 	p := s.Passfile(name)
 
 	recipients, err := s.useableKeys(ctx, name)
@@ -37,10 +43,29 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return store.ErrEncrypt
 	}
 
+	// encrypt the secondary copy before writing anything, so a failure does
+	// not leave two copies with different content behind.
+	var secondary []byte
+	if s.secondary != nil {
+		secondary, err = s.encryptSecondary(ctx, name, sec.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := s.storage.Set(ctx, p, ciphertext); err != nil {
 		return fmt.Errorf("failed to write secret: %w", err)
 	}
 
+	paths := []string{p}
+	if s.secondary != nil {
+		sp := s.secondaryPassfile(name)
+		if err := s.storage.Set(ctx, sp, secondary); err != nil {
+			return fmt.Errorf("failed to write secret: %w", err)
+		}
+		paths = append(paths, sp)
+	}
+
 	// It is not possible to perform concurrent git add and git commit commands
 	// so we need to skip this step when using concurrency and perform them
 	// at the end of the batch processing.
@@ -50,7 +75,7 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return nil
 	}
 
-	if err := s.storage.Add(ctx, p); err != nil {
+	if err := s.storage.Add(ctx, paths...); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -75,14 +100,20 @@ func (s *Store) gitCommitAndPush(ctx context.Context, name string) error {
 	if err := s.storage.Commit(ctx, fmt.Sprintf("Save secret to %s: %s", name, ctxutil.GetCommitMessage(ctx))); err != nil {
 		switch {
 		case errors.Is(err, store.ErrGitNotInit):
-			debug.Log("commitAndPush - skipping git commit - git not initialized")
+			debug.Log("gitCommitAndPush - skipping git commit - git not initialized")
 		case errors.Is(err, store.ErrGitNothingToCommit):
-			debug.Log("commitAndPush - skipping git commit - nothing to commit")
+			debug.Log("gitCommitAndPush - skipping git commit - nothing to commit")
 		default:
 			return fmt.Errorf("failed to commit changes to git: %w", err)
 		}
 	}
 
+	return s.syncHooked(ctx, name, func() error {
+		return s.push(ctx)
+	})
+}
+
+func (s *Store) push(ctx context.Context) error {
 	debug.Log("syncing with remote...")
 
 	if err := s.storage.Push(ctx, "", ""); err != nil {
@@ -207,6 +207,11 @@ func (m *InMem) GetRevision(context.Context, string, string) ([]byte, error) {
 	return []byte("foo\nbar"), nil
 }
 
+// Deleted is not implemented.
+func (m *InMem) Deleted(context.Context) ([]backend.Deletion, error) {
+	return nil, nil
+}
+
 // Compact is not implemented.
 func (m *InMem) Compact(context.Context) error {
 	return nil
@@ -0,0 +1,26 @@
+package root
+
+import (
+	"context"
+
+	"github.com/kpitt/gopass/internal/config"
+	"github.com/kpitt/gopass/internal/out"
+)
+
+// MountConfig returns the effective config of a mount. It merges the
+// global config, the config file inside of the mount and the section of
+// the mount in the global config. An invalid config file inside of the
+// mount is ignored.
+func (r *Store) MountConfig(ctx context.Context, mp string) *config.Config {
+	sub, err := r.GetSubStore(mp)
+	if err != nil {
+		return r.cfg.ForMount(mp, nil)
+	}
+
+	sc, err := sub.StoreConfig(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Ignoring config of store %q: %s", mp, err)
+	}
+
+	return r.cfg.ForMount(mp, sc)
+}
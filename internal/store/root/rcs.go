@@ -2,10 +2,18 @@ package root
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
 
 	"github.com/kpitt/gopass/internal/backend"
+	"github.com/kpitt/gopass/internal/set"
+	"github.com/kpitt/gopass/internal/store/leaf"
 	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/debug"
 	"github.com/kpitt/gopass/pkg/gopass"
+	"golang.org/x/exp/maps"
 )
 
 // RCSInit initializes the version control repo.
@@ -59,3 +67,51 @@ func (r *Store) GetRevision(ctx context.Context, name, revision string) (context
 
 	return ctx, sec, err
 }
+
+// Restore writes an older revision of a secret as a new revision.
+func (r *Store) Restore(ctx context.Context, name, revision string) error {
+	store, name := r.getStore(name)
+
+	return store.Restore(ctx, name, revision)
+}
+
+// Deleted lists the deleted secrets of all stores, newest first. Stores
+// without history are skipped.
+func (r *Store) Deleted(ctx context.Context) ([]backend.Deletion, error) {
+	stores := map[string]*leaf.Store{"": r.store}
+	for alias, sub := range r.mounts {
+		stores[alias] = sub
+	}
+
+	var out []backend.Deletion
+	for _, alias := range set.Sorted(maps.Keys(stores)) {
+		dels, err := stores[alias].Deleted(ctx)
+		if err != nil {
+			if errors.Is(err, backend.ErrNotSupported) {
+				debug.Log("skipping store %q: %s", alias, err)
+
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to list deleted secrets of %q: %w", alias, err)
+		}
+
+		for _, d := range dels {
+			d.Name = path.Join(alias, d.Name)
+			out = append(out, d)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Revision.Date.After(out[j].Revision.Date)
+	})
+
+	return out, nil
+}
+
+// Undelete restores a deleted secret.
+func (r *Store) Undelete(ctx context.Context, name string) error {
+	store, name := r.getStore(name)
+
+	return store.Undelete(ctx, name)
+}
@@ -3,9 +3,12 @@ package root
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/kpitt/gopass/internal/backend"
 	"github.com/kpitt/gopass/pkg/ctxutil"
+	"github.com/kpitt/gopass/pkg/gopass/secrets"
 	"github.com/kpitt/gopass/tests/gptest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,3 +32,41 @@ func TestRCS(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(revs))
 }
+
+func TestDeletedOrder(t *testing.T) {
+	t.Parallel()
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithGitCommit(ctx, true)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+	require.NoError(t, u.InitStore("sub"))
+	require.NoError(t, rs.AddMount(ctx, "sub", u.StoreDir("sub")))
+
+	for _, alias := range []string{"", "sub"} {
+		require.NoError(t, rs.RCSInit(ctx, alias, "foo", "foo@example.org"))
+	}
+
+	sec := secrets.NewKV()
+	sec.SetPassword("hunter2")
+	require.NoError(t, rs.Set(ctx, "old", sec))
+	require.NoError(t, rs.Set(ctx, "sub/new", sec))
+
+	// git dates have a resolution of one second.
+	require.NoError(t, rs.Delete(ctx, "old"))
+	time.Sleep(1100 * time.Millisecond)
+	require.NoError(t, rs.Delete(ctx, "sub/new"))
+
+	dels, err := rs.Deleted(ctx)
+	require.NoError(t, err)
+	require.Len(t, dels, 2)
+	assert.Equal(t, "sub/new", dels[0].Name)
+	assert.Equal(t, "old", dels[1].Name)
+}
@@ -0,0 +1,255 @@
+package tpl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kpitt/gopass/pkg/gopass"
+)
+
+// Ext is the extension of template files. It is removed from the name of
+// the rendered file.
+const Ext = ".tpl"
+
+// File is a rendered template file.
+type File struct {
+	// Source is the path of the template.
+	Source string
+	// Target is the path of the rendered file.
+	Target  string
+	Mode    fs.FileMode
+	Content []byte
+	// Secrets are the fingerprints of all secrets the template used, by
+	// name.
+	Secrets map[string][32]byte
+	// Lists are the fingerprints of the names returned by list, by prefix.
+	Lists map[string][32]byte
+}
+
+// recorder remembers the secrets and lists used by a template.
+type recorder struct {
+	kv      kvstore
+	secrets map[string][32]byte
+	lists   map[string][32]byte
+}
+
+func (r *recorder) Get(ctx context.Context, name string) (gopass.Secret, error) {
+	sec, err := r.kv.Get(ctx, name)
+	r.secrets[name] = fingerprint(sec, err)
+
+	return sec, err //nolint:wrapcheck
+}
+
+// List passes through to the wrapped store, so list works in templates.
+func (r *recorder) List(ctx context.Context, maxDepth int) ([]string, error) {
+	l, ok := r.kv.(lister)
+	if !ok {
+		return nil, fmt.Errorf("%s is not supported", FuncList)
+	}
+
+	return l.List(ctx, maxDepth) //nolint:wrapcheck
+}
+
+// GetTemplate passes through to the wrapped store, so include works in
+// templates.
+func (r *recorder) GetTemplate(ctx context.Context, name string) ([]byte, error) {
+	tg, ok := r.kv.(templateGetter)
+	if !ok {
+		return nil, fmt.Errorf("%s is not supported", FuncInclude)
+	}
+
+	return tg.GetTemplate(ctx, name) //nolint:wrapcheck
+}
+
+// fingerprint returns the hash of the secret. Secrets that could not be
+// read have the zero fingerprint, so the template is re-rendered once they
+// become available.
+func fingerprint(sec gopass.Secret, err error) [32]byte {
+	if err != nil || sec == nil {
+		return [32]byte{}
+	}
+
+	return sha256.Sum256(sec.Bytes())
+}
+
+// fingerprintNames returns the hash of a list of names. Lists that failed
+// have the zero fingerprint.
+func fingerprintNames(names []string, err error) [32]byte {
+	if err != nil {
+		return [32]byte{}
+	}
+
+	return sha256.Sum256([]byte(strings.Join(names, "\n")))
+}
+
+// RenderDir renders all template files below src. The rendered files are
+// placed at the same relative path below dst, without the template
+// extension. Other files are ignored.
+func RenderDir(ctx context.Context, src, dst string, kv kvstore) ([]*File, error) {
+	var files []*File
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), Ext) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path of %s: %w", path, err)
+		}
+
+		f, err := RenderFile(ctx, path, filepath.Join(dst, strings.TrimSuffix(rel, Ext)), kv)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Target < files[j].Target
+	})
+
+	return files, nil
+}
+
+// RenderFile renders a single template file. The rendered file has the same
+// permissions as the template.
+func RenderFile(ctx context.Context, src, dst string, kv kvstore) (*File, error) {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+
+	buf, err := os.ReadFile(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	rec := &recorder{
+		kv:      kv,
+		secrets: make(map[string][32]byte),
+		lists:   make(map[string][32]byte),
+	}
+
+	content, err := Execute(ctx, string(buf), src, nil, rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", src, err)
+	}
+
+	return &File{
+		Source:  src,
+		Target:  dst,
+		Mode:    fi.Mode().Perm(),
+		Content: content,
+		Secrets: rec.secrets,
+		Lists:   rec.lists,
+	}, nil
+}
+
+// Write writes the rendered file. The file is replaced atomically, so
+// readers never see a partially written file.
+func (f *File) Write() error {
+	dir := filepath.Dir(f.Target)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(f.Target)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(f.Content); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Chmod(tmp.Name(), f.Mode); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.Target); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Target, err)
+	}
+
+	return nil
+}
+
+// Drift compares the rendered file with the file on disk. It returns a
+// description of the difference, or an empty string if they match.
+func (f *File) Drift() (string, error) {
+	fi, err := os.Stat(f.Target)
+	if os.IsNotExist(err) {
+		return "missing", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", f.Target, err)
+	}
+
+	buf, err := os.ReadFile(f.Target)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", f.Target, err)
+	}
+
+	var reasons []string
+	if !bytes.Equal(buf, f.Content) {
+		reasons = append(reasons, "content differs")
+	}
+
+	if mode := fi.Mode().Perm(); mode != f.Mode {
+		reasons = append(reasons, fmt.Sprintf("mode is %04o, want %04o", mode, f.Mode))
+	}
+
+	return strings.Join(reasons, ", "), nil
+}
+
+// Stale returns true if any secret used by the template has changed, was
+// added or was removed since it was rendered. Secrets added or removed below
+// a listed prefix make the template stale as well.
+func (f *File) Stale(ctx context.Context, kv kvstore) (bool, error) {
+	for name, sum := range f.Secrets {
+		if ctx.Err() != nil {
+			return false, ctx.Err() //nolint:wrapcheck
+		}
+
+		if fingerprint(kv.Get(ctx, name)) != sum {
+			return true, nil
+		}
+	}
+
+	for prefix, sum := range f.Lists {
+		if ctx.Err() != nil {
+			return false, ctx.Err() //nolint:wrapcheck
+		}
+
+		if fingerprintNames(listBelow(ctx, kv, prefix)) != sum {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
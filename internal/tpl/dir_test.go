@@ -0,0 +1,170 @@
+package tpl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/kpitt/gopass/pkg/gopass"
+	"github.com/kpitt/gopass/pkg/gopass/secrets/secparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapKV map[string]string
+
+func (m mapKV) Get(ctx context.Context, name string) (gopass.Secret, error) {
+	content, found := m[name]
+	if !found {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+
+	return secparse.Parse([]byte(content)) //nolint:wrapcheck
+}
+
+// listKV is a mapKV that supports list.
+type listKV struct{ mapKV }
+
+func (l listKV) List(ctx context.Context, maxDepth int) ([]string, error) {
+	names := make([]string, 0, len(l.mapKV))
+	for name := range l.mapKV {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func TestRenderDir(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	kv := mapKV{"db": "s3cr3t\nuser: admin\n", "api": "t0ken\n"}
+
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "out")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "app", "db.conf.tpl"), []byte(`{{ getval "db" "user" }}:{{ getpw "db" }}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "api.env.tpl"), []byte(`TOKEN={{ getpw "api" }}`), 0o640))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "static.conf"), []byte("static"), 0o644))
+
+	files, err := RenderDir(ctx, src, dst, kv)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	assert.Equal(t, filepath.Join(dst, "api.env"), files[0].Target)
+	assert.Equal(t, "TOKEN=t0ken", string(files[0].Content))
+	assert.Equal(t, os.FileMode(0o640), files[0].Mode)
+	assert.Equal(t, []string{"api"}, keys(files[0].Secrets))
+
+	assert.Equal(t, filepath.Join(dst, "app", "db.conf"), files[1].Target)
+	assert.Equal(t, "admin:s3cr3t", string(files[1].Content))
+	assert.Equal(t, os.FileMode(0o600), files[1].Mode)
+
+	drift, err := files[1].Drift()
+	require.NoError(t, err)
+	assert.Equal(t, "missing", drift)
+
+	for _, f := range files {
+		require.NoError(t, f.Write())
+	}
+
+	fi, err := os.Stat(files[1].Target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	drift, err = files[1].Drift()
+	require.NoError(t, err)
+	assert.Equal(t, "", drift)
+
+	require.NoError(t, os.WriteFile(files[1].Target, []byte("edited"), 0o644))
+	require.NoError(t, os.Chmod(files[1].Target, 0o644))
+	drift, err = files[1].Drift()
+	require.NoError(t, err)
+	assert.Equal(t, "content differs, mode is 0644, want 0600", drift)
+
+	entries, err := os.ReadDir(filepath.Join(dst, "app"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp files left")
+}
+
+func TestStale(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	kv := mapKV{"db": "s3cr3t\n", "new": "added\n"}
+
+	src := filepath.Join(t.TempDir(), "db.conf.tpl")
+	require.NoError(t, os.WriteFile(src, []byte(`{{ getpw "db" }} {{ getpw "new" }}`), 0o600))
+
+	f, err := RenderFile(ctx, src, src+".out", kv)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "new"}, keys(f.Secrets))
+
+	stale, err := f.Stale(ctx, kv)
+	require.NoError(t, err)
+	assert.False(t, stale)
+
+	kv["db"] = "changed\n"
+	stale, err = f.Stale(ctx, kv)
+	require.NoError(t, err)
+	assert.True(t, stale)
+
+	kv["db"] = "s3cr3t\n"
+	delete(kv, "new")
+	stale, err = f.Stale(ctx, kv)
+	require.NoError(t, err)
+	assert.True(t, stale, "removed secret")
+
+	_, err = RenderFile(ctx, src, src+".out", kv)
+	assert.Error(t, err, "missing secrets fail the render")
+}
+
+func TestStaleList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := mapKV{"hosts/a": "x\n", "hosts/b": "y\n", "other": "z\n"}
+	kv := listKV{m}
+
+	src := filepath.Join(t.TempDir(), "hosts.tpl")
+	require.NoError(t, os.WriteFile(src, []byte(`{{ range list "hosts" }}{{ . }} {{ end }}`), 0o600))
+
+	f, err := RenderFile(ctx, src, src+".out", kv)
+	require.NoError(t, err)
+	assert.Equal(t, "hosts/a hosts/b ", string(f.Content))
+	assert.Equal(t, []string{"hosts"}, keys(f.Lists))
+
+	stale, err := f.Stale(ctx, kv)
+	require.NoError(t, err)
+	assert.False(t, stale)
+
+	m["other2"] = "w\n"
+	stale, err = f.Stale(ctx, kv)
+	require.NoError(t, err)
+	assert.False(t, stale, "added outside the prefix")
+
+	m["hosts/c"] = "w\n"
+	stale, err = f.Stale(ctx, kv)
+	require.NoError(t, err)
+	assert.True(t, stale, "added below the prefix")
+
+	delete(m, "hosts/c")
+	delete(m, "hosts/a")
+	stale, err = f.Stale(ctx, kv)
+	require.NoError(t, err)
+	assert.True(t, stale, "removed below the prefix")
+}
+
+func keys(m map[string][32]byte) []string {
+	k := make([]string, 0, len(m))
+	for name := range m {
+		k = append(k, name)
+	}
+	sort.Strings(k)
+
+	return k
+}
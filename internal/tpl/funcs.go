@@ -1,12 +1,19 @@
 package tpl
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/jsimonetti/pwscheme/md5crypt"
 	"github.com/jsimonetti/pwscheme/ssha"
@@ -15,7 +22,12 @@ import (
 	"github.com/kpitt/gopass/internal/pwschemes/argon2i"
 	"github.com/kpitt/gopass/internal/pwschemes/argon2id"
 	"github.com/kpitt/gopass/internal/pwschemes/bcrypt"
+	"github.com/kpitt/gopass/internal/tree"
 	"github.com/kpitt/gopass/pkg/debug"
+	"github.com/kpitt/gopass/pkg/otp"
+	"github.com/kpitt/gopass/pkg/pwgen"
+	"github.com/pquerna/otp/totp"
+	"gopkg.in/yaml.v3"
 )
 
 // These constants defined the template function names used.
@@ -33,16 +45,50 @@ const (
 	FuncArgon2i     = "argon2i"
 	FuncArgon2id    = "argon2id"
 	FuncBcrypt      = "bcrypt"
+	FuncB64Enc      = "b64enc"
+	FuncB64Dec      = "b64dec"
+	FuncHexEnc      = "hexenc"
+	FuncHexDec      = "hexdec"
+	FuncJSONQuote   = "jsonquote"
+	FuncYAMLQuote   = "yamlquote"
+	FuncTOTP        = "totp"
+	FuncGenPW       = "genpw"
+	FuncEnv         = "env"
+	FuncDefault     = "default"
+	FuncList        = "list"
+	FuncInclude     = "include"
 )
 
+// maxIncludeDepth limits nested includes, so templates that include each
+// other fail instead of recursing forever.
+const maxIncludeDepth = 8
+
+// lister is implemented by stores that can list their secrets.
+type lister interface {
+	List(context.Context, int) ([]string, error)
+}
+
+// templateGetter is implemented by stores that can return stored templates.
+type templateGetter interface {
+	GetTemplate(context.Context, string) ([]byte, error)
+}
+
 func md5sum() func(...string) (string, error) {
 	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncMd5sum)
+		}
+
 		return fmt.Sprintf("%x", md5.Sum([]byte(s[0]))), nil
 	}
 }
 
 func sha1sum() func(...string) (string, error) {
 	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncSha1sum)
+		}
+
 		return fmt.Sprintf("%x", sha1.Sum([]byte(s[0]))), nil
 	}
 }
@@ -154,7 +200,7 @@ func bcryptFunc() func(...string) (string, error) {
 func get(ctx context.Context, kv kvstore) func(...string) (string, error) {
 	return func(s ...string) (string, error) {
 		if len(s) < 1 {
-			return "", nil
+			return "", fmt.Errorf("usage: %s <secret>", FuncGet)
 		}
 
 		if kv == nil {
@@ -163,7 +209,7 @@ func get(ctx context.Context, kv kvstore) func(...string) (string, error) {
 
 		sec, err := kv.Get(ctx, s[0])
 		if err != nil {
-			return err.Error(), nil
+			return "", fmt.Errorf("failed to get %q: %w", s[0], err)
 		}
 
 		return string(sec.Bytes()), nil
@@ -173,7 +219,7 @@ func get(ctx context.Context, kv kvstore) func(...string) (string, error) {
 func getPassword(ctx context.Context, kv kvstore) func(...string) (string, error) {
 	return func(s ...string) (string, error) {
 		if len(s) < 1 {
-			return "", nil
+			return "", fmt.Errorf("usage: %s <secret>", FuncGetPassword)
 		}
 
 		if kv == nil {
@@ -182,7 +228,7 @@ func getPassword(ctx context.Context, kv kvstore) func(...string) (string, error
 
 		sec, err := kv.Get(ctx, s[0])
 		if err != nil {
-			return err.Error(), nil
+			return "", fmt.Errorf("failed to get %q: %w", s[0], err)
 		}
 
 		return sec.Password(), nil
@@ -192,7 +238,7 @@ func getPassword(ctx context.Context, kv kvstore) func(...string) (string, error
 func getValue(ctx context.Context, kv kvstore) func(...string) (string, error) {
 	return func(s ...string) (string, error) {
 		if len(s) < 2 {
-			return "", nil
+			return "", fmt.Errorf("usage: %s <secret> <key>", FuncGetValue)
 		}
 
 		if kv == nil {
@@ -201,7 +247,7 @@ func getValue(ctx context.Context, kv kvstore) func(...string) (string, error) {
 
 		sec, err := kv.Get(ctx, s[0])
 		if err != nil {
-			return err.Error(), nil
+			return "", fmt.Errorf("failed to get %q: %w", s[0], err)
 		}
 
 		sv, found := sec.Get(s[1])
@@ -216,7 +262,7 @@ func getValue(ctx context.Context, kv kvstore) func(...string) (string, error) {
 func getValues(ctx context.Context, kv kvstore) func(...string) ([]string, error) {
 	return func(s ...string) ([]string, error) {
 		if len(s) < 2 {
-			return nil, nil
+			return nil, fmt.Errorf("usage: %s <secret> <key>", FuncGetValues)
 		}
 
 		if kv == nil {
@@ -237,7 +283,253 @@ func getValues(ctx context.Context, kv kvstore) func(...string) ([]string, error
 	}
 }
 
-func funcMap(ctx context.Context, kv kvstore) template.FuncMap {
+func b64enc() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncB64Enc)
+		}
+
+		return base64.StdEncoding.EncodeToString([]byte(s[0])), nil
+	}
+}
+
+func b64dec() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncB64Dec)
+		}
+
+		buf, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s[0]))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64: %w", err)
+		}
+
+		return string(buf), nil
+	}
+}
+
+func hexenc() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncHexEnc)
+		}
+
+		return hex.EncodeToString([]byte(s[0])), nil
+	}
+}
+
+func hexdec() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncHexDec)
+		}
+
+		buf, err := hex.DecodeString(strings.TrimSpace(s[0]))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode hex: %w", err)
+		}
+
+		return string(buf), nil
+	}
+}
+
+// jsonQuote returns the input as a JSON string, including the quotes.
+func jsonQuote() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncJSONQuote)
+		}
+
+		buf := &bytes.Buffer{}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(s[0]); err != nil {
+			return "", fmt.Errorf("failed to quote: %w", err)
+		}
+
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	}
+}
+
+// yamlQuote returns the input as a double quoted YAML scalar.
+func yamlQuote() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <input>", FuncYAMLQuote)
+		}
+
+		buf, err := yaml.Marshal(&yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Style: yaml.DoubleQuotedStyle,
+			Tag:   "!!str",
+			Value: s[0],
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to quote: %w", err)
+		}
+
+		return strings.TrimSuffix(string(buf), "\n"), nil
+	}
+}
+
+// totpFunc returns the current TOTP token of a secret.
+func totpFunc(ctx context.Context, kv kvstore) func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <secret>", FuncTOTP)
+		}
+
+		if kv == nil {
+			return "", fmt.Errorf("KV is nil")
+		}
+
+		sec, err := kv.Get(ctx, s[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to get %q: %w", s[0], err)
+		}
+
+		key, err := otp.Calculate(s[0], sec)
+		if err != nil {
+			return "", fmt.Errorf("no OTP entry found for %q: %w", s[0], err)
+		}
+
+		if key.Type() != "totp" {
+			return "", fmt.Errorf("%q is not a TOTP secret", s[0])
+		}
+
+		token, err := totp.GenerateCodeCustom(key.Secret(), time.Now(), totp.ValidateOpts{
+			Period:    uint(key.Period()),
+			Skew:      1,
+			Digits:    otp.ParseDigits(key.URL()),
+			Algorithm: otp.ParseAlgorithm(key.URL()),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to compute OTP token for %q: %w", s[0], err)
+		}
+
+		return token, nil
+	}
+}
+
+func genpw() func(...string) (string, error) {
+	// parameters: s[0] = length, s[1] = charset (optional)
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <length> [charset]", FuncGenPW)
+		}
+
+		length, err := strconv.Atoi(s[0])
+		if err != nil || length < 1 {
+			return "", fmt.Errorf("invalid length %q", s[0])
+		}
+
+		if len(s) > 1 && s[1] != "" {
+			return pwgen.GeneratePasswordCharset(length, s[1]), nil
+		}
+
+		return pwgen.GeneratePassword(length, true), nil
+	}
+}
+
+// env returns the value of an environment variable. Unset variables are
+// empty, use default to provide a fallback.
+func env() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <name>", FuncEnv)
+		}
+
+		return os.Getenv(s[0]), nil
+	}
+}
+
+func defaultFunc() func(...string) (string, error) {
+	// parameters: s[0] = default, s[-1] = value
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <default> [value]", FuncDefault)
+		}
+
+		if len(s) > 1 && s[len(s)-1] != "" {
+			return s[len(s)-1], nil
+		}
+
+		return s[0], nil
+	}
+}
+
+// list returns the names of all secrets below a prefix.
+func list(ctx context.Context, kv kvstore) func(...string) ([]string, error) {
+	return func(s ...string) ([]string, error) {
+		prefix := ""
+		if len(s) > 0 {
+			prefix = strings.TrimSuffix(s[0], "/")
+		}
+
+		names, err := listBelow(ctx, kv, prefix)
+		if rec, ok := kv.(*recorder); ok {
+			rec.lists[prefix] = fingerprintNames(names, err)
+		}
+
+		return names, err
+	}
+}
+
+// listBelow returns the names of all secrets below prefix, or all secrets
+// if prefix is empty.
+func listBelow(ctx context.Context, kv kvstore, prefix string) ([]string, error) {
+	l, ok := kv.(lister)
+	if !ok {
+		return nil, fmt.Errorf("%s is not supported", FuncList)
+	}
+
+	all, err := l.List(ctx, tree.INF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	names := make([]string, 0, len(all))
+	for _, name := range all {
+		if prefix == "" || strings.HasPrefix(name, prefix+"/") {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// include renders a stored template with the same data as the including
+// template.
+func include(ctx context.Context, kv kvstore, pl payload, depth int) func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		if len(s) < 1 {
+			return "", fmt.Errorf("usage: %s <template>", FuncInclude)
+		}
+
+		if depth >= maxIncludeDepth {
+			return "", fmt.Errorf("failed to include %q: too many nested includes", s[0])
+		}
+
+		tg, ok := kv.(templateGetter)
+		if !ok {
+			return "", fmt.Errorf("%s is not supported", FuncInclude)
+		}
+
+		tpl, err := tg.GetTemplate(ctx, s[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to get template %q: %w", s[0], err)
+		}
+
+		buf, err := execute(ctx, string(tpl), pl, kv, depth+1)
+		if err != nil {
+			return "", fmt.Errorf("failed to include %q: %w", s[0], err)
+		}
+
+		return string(buf), nil
+	}
+}
+
+func funcMap(ctx context.Context, kv kvstore, pl payload, depth int) template.FuncMap {
 	return template.FuncMap{
 		FuncGet:         get(ctx, kv),
 		FuncGetPassword: getPassword(ctx, kv),
@@ -252,5 +544,17 @@ func funcMap(ctx context.Context, kv kvstore) template.FuncMap {
 		FuncArgon2i:     argon2iFunc(),
 		FuncArgon2id:    argon2idFunc(),
 		FuncBcrypt:      bcryptFunc(),
+		FuncB64Enc:      b64enc(),
+		FuncB64Dec:      b64dec(),
+		FuncHexEnc:      hexenc(),
+		FuncHexDec:      hexdec(),
+		FuncJSONQuote:   jsonQuote(),
+		FuncYAMLQuote:   yamlQuote(),
+		FuncTOTP:        totpFunc(ctx, kv),
+		FuncGenPW:       genpw(),
+		FuncEnv:         env(),
+		FuncDefault:     defaultFunc(),
+		FuncList:        list(ctx, kv),
+		FuncInclude:     include(ctx, kv, pl, depth),
 	}
 }
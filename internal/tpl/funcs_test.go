@@ -0,0 +1,110 @@
+package tpl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storeMock is a kvstore that can also list secrets and return templates.
+type storeMock struct {
+	mapKV
+	templates map[string]string
+}
+
+func (s storeMock) List(ctx context.Context, maxDepth int) ([]string, error) {
+	names := make([]string, 0, len(s.mapKV))
+	for name := range s.mapKV {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (s storeMock) GetTemplate(ctx context.Context, name string) ([]byte, error) {
+	content, found := s.templates[name]
+	if !found {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+
+	return []byte(content), nil
+}
+
+func TestFuncs(t *testing.T) {
+	t.Setenv("GOPASS_TPL_TEST", "from-env")
+
+	ctx := context.Background()
+	kv := storeMock{
+		mapKV: mapKV{
+			"db/prod":  "s3cr3t\nuser: admin\n",
+			"db/stage": "st4ge\n",
+			"api":      "t0ken\n",
+			"otp":      "pw\ntotp: GJWTGMTNN5YWW2TNPJXWG2DHMIFA\n",
+			"hotp":     "pw\nhotp: GJWTGMTNN5YWW2TNPJXWG2DHMIFA\n",
+		},
+		templates: map[string]string{
+			"db":   `{{ getval "db/prod" "user" }}@{{ .Name }}`,
+			"loop": `{{ include "loop" }}`,
+		},
+	}
+
+	for _, tc := range []struct {
+		Template   string
+		Output     string
+		ShouldFail bool
+	}{
+		{Template: `{{ "foo:bar" | b64enc }}`, Output: "Zm9vOmJhcg=="},
+		{Template: `{{ "Zm9vOmJhcg==" | b64dec }}`, Output: "foo:bar"},
+		{Template: `{{ "%%%" | b64dec }}`, ShouldFail: true},
+		{Template: `{{ "foo" | hexenc }}`, Output: "666f6f"},
+		{Template: `{{ "666f6f" | hexdec }}`, Output: "foo"},
+		{Template: `{{ "xyz" | hexdec }}`, ShouldFail: true},
+		{Template: `{{ "a \"b\" <c>\n" | jsonquote }}`, Output: `"a \"b\" <c>\n"`},
+		{Template: `{{ "a: b\n" | yamlquote }}`, Output: `"a: b\n"`},
+		{Template: `{{ genpw "24" | len }}`, Output: "24"},
+		{Template: `{{ genpw "8" "a" }}`, Output: "aaaaaaaa"},
+		{Template: `{{ genpw "zero" }}`, ShouldFail: true},
+		{Template: `{{ env "GOPASS_TPL_TEST" }}`, Output: "from-env"},
+		{Template: `{{ env "GOPASS_TPL_UNSET" | default "fallback" }}`, Output: "fallback"},
+		{Template: `{{ env "GOPASS_TPL_TEST" | default "fallback" }}`, Output: "from-env"},
+		{Template: `{{ range list "db" }}{{ . }} {{ end }}`, Output: "db/prod db/stage "},
+		{Template: `{{ list | len }}`, Output: "5"},
+		{Template: `{{ include "db" }}`, Output: "admin@example"},
+		{Template: `{{ include "missing" }}`, ShouldFail: true},
+		{Template: `{{ include "loop" }}`, ShouldFail: true},
+		{Template: `{{ totp "otp" | len }}`, Output: "6"},
+		{Template: `{{ totp "hotp" }}`, ShouldFail: true},
+		{Template: `{{ getpw "missing" }}`, ShouldFail: true},
+		{Template: `{{ get "missing" }}`, ShouldFail: true},
+		{Template: `{{ getval "missing" "user" }}`, ShouldFail: true},
+		{Template: `{{ getval "db/prod" }}`, ShouldFail: true},
+		{Template: `{{ md5sum }}`, ShouldFail: true},
+	} {
+		buf, err := Execute(ctx, tc.Template, "example", nil, kv)
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Template)
+
+			continue
+		}
+		require.NoError(t, err, tc.Template)
+		assert.Equal(t, tc.Output, string(buf), tc.Template)
+	}
+}
+
+func TestFuncsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	kv := mapKV{"api": "t0ken\n"}
+
+	_, err := Execute(ctx, `{{ list }}`, "example", nil, kv)
+	assert.Error(t, err)
+
+	_, err = Execute(ctx, `{{ include "db" }}`, "example", nil, kv)
+	assert.Error(t, err)
+}
@@ -22,8 +22,9 @@ type payload struct {
 }
 
 // Execute executes the given template.
+// Any function that fails, e.g. because a secret does not exist, fails the
+// whole template.
 func Execute(ctx context.Context, tpl, name string, content []byte, s kvstore) ([]byte, error) {
-	funcs := funcMap(ctx, s)
 	pl := payload{
 		Dir:     filepath.Dir(name),
 		Path:    name,
@@ -31,6 +32,13 @@ func Execute(ctx context.Context, tpl, name string, content []byte, s kvstore) (
 		Content: string(content),
 	}
 
+	return execute(ctx, tpl, pl, s, 0)
+}
+
+// execute executes the template at the given include depth.
+func execute(ctx context.Context, tpl string, pl payload, s kvstore, depth int) ([]byte, error) {
+	funcs := funcMap(ctx, s, pl, depth)
+
 	tmpl, err := template.New(tpl).Funcs(funcs).Parse(tpl)
 	if err != nil {
 		return []byte{}, fmt.Errorf("failed to parse template: %w", err)
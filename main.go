@@ -12,6 +12,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"runtime/pprof"
@@ -85,7 +86,7 @@ func main() {
 	ctx = queue.WithQueue(ctx, q)
 	ctx, app := setupApp(ctx, buildVersion, buildDate)
 
-	if err := app.RunContext(ctx, os.Args); err != nil {
+	if err := app.RunContext(ctx, helperArgs(os.Args)); err != nil {
 		log.Fatal(err)
 	}
 
@@ -95,6 +96,21 @@ func main() {
 	writeMemProfile()
 }
 
+// helperArgs maps invocations through a symlink named after a credential
+// helper, e.g. docker-credential-gopass, to the matching command.
+func helperArgs(args []string) []string {
+	if len(args) < 1 {
+		return args
+	}
+
+	base := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	if base != "docker-credential-"+name {
+		return args
+	}
+
+	return append([]string{args[0], "docker-credential"}, args[1:]...)
+}
+
 //nolint:wrapcheck
 func setupApp(ctx context.Context, buildVersion, buildDate string) (context.Context, *cli.App) {
 	// try to read config (if it exists)
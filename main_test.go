@@ -41,6 +41,15 @@ func TestSetupApp(t *testing.T) {
 	assert.NotNil(t, app)
 }
 
+// commandsBlocking is a list of commands that run until they are
+// interrupted. They are not invoked.
+var commandsBlocking = set.Map([]string{
+	".agent",
+	".jsonapi.listen",
+	".serve",
+	".ssh-agent",
+})
+
 // commandsWithError is a list of commands that return an error when
 // invoked without arguments.
 var commandsWithError = set.Map([]string{
@@ -49,10 +58,13 @@ var commandsWithError = set.Map([]string{
 	".audit",
 	".cat",
 	".clone",
+	".convert",
 	".copy",
 	".create",
 	".delete",
+	".docker-credential",
 	".edit",
+	".env",
 	".find",
 	".fscopy",
 	".fsmove",
@@ -63,8 +75,14 @@ var commandsWithError = set.Map([]string{
 	".git.status",
 	".git.remote.add",
 	".git.remote.remove",
+	".git-credential.erase",
+	".git-credential.get",
+	".git-credential.store",
+	".git-merge-driver",
+	".git-textconv",
 	".grep",
 	".history",
+	".import",
 	".init",
 	".insert",
 	".link",
@@ -76,6 +94,7 @@ var commandsWithError = set.Map([]string{
 	".process",
 	".recipients.add",
 	".recipients.remove",
+	".restore",
 	".show",
 	".sum",
 	".templates.edit",
@@ -117,7 +136,7 @@ func TestGetCommands(t *testing.T) { //nolint:paralleltest
 	c.Context = ctx
 
 	commands := getCommands(act, app)
-	assert.Equal(t, 35, len(commands))
+	assert.Equal(t, 50, len(commands))
 
 	prefix := ""
 	testCommands(t, c, commands, prefix)
@@ -143,6 +162,10 @@ func testCommands(t *testing.T, c *cli.Context, commands []*cli.Command, prefix
 
 		if cmd.Action != nil {
 			fullName := prefix + "." + cmd.Name
+			if _, found := commandsBlocking[fullName]; found {
+				continue
+			}
+
 			if _, found := commandsWithError[fullName]; found {
 				assert.Error(t, cmd.Action(c), fullName)
 
@@ -166,3 +189,11 @@ func TestInitContext(t *testing.T) {
 	ctx = initContext(ctx, cfg)
 	assert.Equal(t, true, gpg.IsAlwaysTrust(ctx))
 }
+
+func TestHelperArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"gopass", "show", "foo"}, helperArgs([]string{"gopass", "show", "foo"}))
+	assert.Equal(t, []string{"/usr/bin/docker-credential-gopass", "docker-credential", "get"}, helperArgs([]string{"/usr/bin/docker-credential-gopass", "get"}))
+	assert.Equal(t, []string{}, helperArgs([]string{}))
+}
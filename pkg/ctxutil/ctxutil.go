@@ -32,6 +32,7 @@ const (
 	ctxKeyShowParsing
 	ctxKeyHidden
 	ctxKeySpinner
+	ctxKeyJSON
 )
 
 // ErrNoCallback is returned when no callback is set in the context.
@@ -40,11 +41,22 @@ var ErrNoCallback = fmt.Errorf("no callback")
 // WithGlobalFlags parses any global flags from the cli context and returns
 // a regular context.
 func WithGlobalFlags(c *cli.Context) context.Context {
+	ctx := c.Context
 	if c.Bool("yes") {
-		return WithAlwaysYes(c.Context, true)
+		ctx = WithAlwaysYes(ctx, true)
 	}
 
-	return c.Context
+	// --json may be given globally or per command. A command level flag
+	// shadows the global one, so we need to check the whole lineage.
+	for _, lc := range c.Lineage() {
+		if lc.Bool("json") {
+			ctx = WithJSON(ctx, true)
+
+			break
+		}
+	}
+
+	return ctx
 }
 
 // ProgressCallback is a callback for updateing progress.
@@ -407,6 +419,24 @@ func IsHidden(ctx context.Context) bool {
 	return bv
 }
 
+// WithJSON returns a context with the flag value for JSON output set.
+func WithJSON(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyJSON, bv)
+}
+
+// HasJSON returns true if a value for JSON output has been set in this context.
+func HasJSON(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxKeyJSON).(bool)
+
+	return ok
+}
+
+// IsJSON returns true if commands should print machine-readable JSON
+// documents instead of human-oriented text.
+func IsJSON(ctx context.Context) bool {
+	return is(ctx, ctxKeyJSON, false)
+}
+
 // WithSpinner returns a context with a progress spinner showing the specified `msg`.
 func WithSpinner(ctx context.Context, msg string) context.Context {
 	sp := spinner.New(spinner.CharSets[14], 40*time.Millisecond)
@@ -179,6 +179,36 @@ func TestGlobalFlags(t *testing.T) {
 	c.Context = ctx
 
 	assert.Equal(t, true, IsAlwaysYes(WithGlobalFlags(c)))
+	assert.Equal(t, false, IsJSON(WithGlobalFlags(c)))
+}
+
+func TestGlobalFlagsJSON(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	app := cli.NewApp()
+
+	// --json given before the sub command.
+	gfs := flag.NewFlagSet("global", flag.ContinueOnError)
+	jf := cli.BoolFlag{
+		Name:  "json",
+		Usage: "json",
+	}
+	assert.NoError(t, jf.Apply(gfs))
+	assert.NoError(t, gfs.Parse([]string{"--json"}))
+	gc := cli.NewContext(app, gfs, nil)
+	gc.Context = ctx
+
+	// the sub command has it's own (unset) --json flag.
+	fs := flag.NewFlagSet("default", flag.ContinueOnError)
+	assert.NoError(t, jf.Apply(fs))
+	assert.NoError(t, fs.Parse([]string{}))
+	c := cli.NewContext(app, fs, gc)
+	c.Context = ctx
+
+	assert.Equal(t, true, IsJSON(WithGlobalFlags(c)))
+	assert.Equal(t, true, HasJSON(WithJSON(ctx, false)))
+	assert.Equal(t, false, IsJSON(ctx))
 }
 
 func TestImportFunc(t *testing.T) {
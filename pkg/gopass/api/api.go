@@ -2,14 +2,17 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/kpitt/gopass/internal/backend"
 	// load crypto backends.
 	_ "github.com/kpitt/gopass/internal/backend/crypto"
 	// load storage backends.
 	_ "github.com/kpitt/gopass/internal/backend/storage"
 	"github.com/kpitt/gopass/internal/config"
 	"github.com/kpitt/gopass/internal/queue"
+	"github.com/kpitt/gopass/internal/store"
 	"github.com/kpitt/gopass/internal/store/root"
 	"github.com/kpitt/gopass/internal/tree"
 	"github.com/kpitt/gopass/pkg/gopass"
@@ -56,7 +59,13 @@ func (g *Gopass) List(ctx context.Context) ([]string, error) {
 
 // Get returns a single, encrypted secret. It must be unwrapped before use.
 func (g *Gopass) Get(ctx context.Context, name, revision string) (gopass.Secret, error) {
-	return g.rs.Get(ctx, name) //nolint:wrapcheck
+	if revision == "" || revision == "latest" {
+		return g.rs.Get(ctx, name) //nolint:wrapcheck
+	}
+
+	_, sec, err := g.rs.GetRevision(ctx, name, revision)
+
+	return sec, err //nolint:wrapcheck
 }
 
 // Set adds a new revision to an existing secret or creates a new one.
@@ -79,14 +88,42 @@ func (g *Gopass) Rename(ctx context.Context, src, dest string) error {
 	return g.rs.Move(ctx, src, dest) //nolint:wrapcheck
 }
 
-// Sync synchronizes a secret with a remote.
+// Sync pushes the root store and all mounted sub stores to their remotes.
+// Stores without a remote are skipped.
 func (g *Gopass) Sync(ctx context.Context) error {
-	return ErrNotImplemented
+	for _, mp := range append([]string{""}, g.rs.MountPoints()...) {
+		sub, err := g.rs.GetSubStore(mp)
+		if err != nil {
+			return fmt.Errorf("failed to get sub store %q: %w", mp, err)
+		}
+
+		err = sub.Storage().Push(ctx, "", "")
+		switch {
+		case err == nil:
+		case errors.Is(err, store.ErrGitNoRemote):
+		case errors.Is(err, store.ErrGitNotInit):
+		case errors.Is(err, backend.ErrNotSupported):
+		default:
+			return fmt.Errorf("failed to sync %q: %w", mp, err)
+		}
+	}
+
+	return nil
 }
 
 // Revisions lists all revisions of this secret.
 func (g *Gopass) Revisions(ctx context.Context, name string) ([]string, error) {
-	return nil, ErrNotImplemented
+	rs, err := g.rs.ListRevisions(ctx, name)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	revs := make([]string, 0, len(rs))
+	for _, r := range rs {
+		revs = append(revs, r.Hash)
+	}
+
+	return revs, nil
 }
 
 func (g *Gopass) String() string {
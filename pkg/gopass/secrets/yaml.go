@@ -45,6 +45,13 @@ type YAML struct {
 	body     string
 }
 
+// NewYAML creates a new YAML secret.
+func NewYAML() *YAML {
+	return &YAML{
+		data: make(map[string]any, 10),
+	}
+}
+
 // Keys returns all keys.
 func (y *YAML) Keys() []string {
 	keys := maps.Keys(y.data)
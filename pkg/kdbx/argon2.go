@@ -0,0 +1,295 @@
+package kdbx
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// golang.org/x/crypto/argon2 only exposes Argon2i and Argon2id, but most
+// KeePass databases use Argon2d. This is a straightforward implementation
+// of RFC 9106 that supports all three variants.
+
+const (
+	argon2d  = 0
+	argon2i  = 1
+	argon2id = 2
+
+	argon2Version = 0x13
+
+	blockLength = 128
+	syncPoints  = 4
+)
+
+type block [blockLength]uint64
+
+// argon2Key derives a key of keyLen bytes. memory is given in KiB.
+func argon2Key(mode int, password, salt, secret, data []byte, time, memory, threads, keyLen uint32) []byte {
+	if memory < 2*syncPoints*threads {
+		memory = 2 * syncPoints * threads
+	}
+
+	h0 := argon2InitHash(mode, password, salt, secret, data, time, memory, threads, keyLen)
+	memory = memory / (syncPoints * threads) * (syncPoints * threads)
+
+	B := argon2InitBlocks(&h0, memory, threads)
+	argon2ProcessBlocks(B, mode, time, memory, threads)
+
+	return argon2Extract(B, memory, threads, keyLen)
+}
+
+func argon2InitHash(mode int, password, salt, secret, data []byte, time, memory, threads, keyLen uint32) [blake2b.Size + 8]byte {
+	var h0 [blake2b.Size + 8]byte
+
+	b2, _ := blake2b.New512(nil)
+	for _, v := range []uint32{threads, keyLen, memory, time, argon2Version, uint32(mode)} {
+		writeUint32(b2, v)
+	}
+	for _, v := range [][]byte{password, salt, secret, data} {
+		writeUint32(b2, uint32(len(v)))
+		_, _ = b2.Write(v)
+	}
+	b2.Sum(h0[:0])
+
+	return h0
+}
+
+func argon2InitBlocks(h0 *[blake2b.Size + 8]byte, memory, threads uint32) []block {
+	var buf [1024]byte
+	B := make([]block, memory)
+
+	for lane := uint32(0); lane < threads; lane++ {
+		j := lane * (memory / threads)
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.Size:], i)
+			blake2bHash(buf[:], h0[:])
+			for k := range B[j+i] {
+				B[j+i][k] = binary.LittleEndian.Uint64(buf[k*8:])
+			}
+		}
+	}
+
+	return B
+}
+
+func argon2ProcessBlocks(B []block, mode int, time, memory, threads uint32) {
+	laneLen := memory / threads
+	segLen := laneLen / syncPoints
+
+	processSegment := func(pass, slice, lane uint32, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		var addresses, in, zero block
+		dataIndependent := mode == argon2i || (mode == argon2id && pass == 0 && slice < syncPoints/2)
+		if dataIndependent {
+			in[0] = uint64(pass)
+			in[1] = uint64(lane)
+			in[2] = uint64(slice)
+			in[3] = uint64(memory)
+			in[4] = uint64(time)
+			in[5] = uint64(mode)
+		}
+
+		index := uint32(0)
+		if pass == 0 && slice == 0 {
+			// the first two blocks of each lane are already initialized.
+			index = 2
+			if dataIndependent {
+				in[6]++
+				processBlock(&addresses, &in, &zero, false)
+				processBlock(&addresses, &addresses, &zero, false)
+			}
+		}
+
+		offset := lane*laneLen + slice*segLen + index
+		for index < segLen {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				// the last block of the lane.
+				prev += laneLen
+			}
+
+			var random uint64
+			if dataIndependent {
+				if index%blockLength == 0 {
+					in[6]++
+					processBlock(&addresses, &in, &zero, false)
+					processBlock(&addresses, &addresses, &zero, false)
+				}
+				random = addresses[index%blockLength]
+			} else {
+				random = B[prev][0]
+			}
+
+			ref := argon2IndexAlpha(random, laneLen, segLen, threads, pass, slice, lane, index)
+			processBlock(&B[offset], &B[prev], &B[ref], true)
+
+			index++
+			offset++
+		}
+	}
+
+	for pass := uint32(0); pass < time; pass++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < threads; lane++ {
+				wg.Add(1)
+				go processSegment(pass, slice, lane, &wg)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+// argon2IndexAlpha maps the pseudo random value to the index of the
+// reference block (RFC 9106, section 3.4.1.2).
+func argon2IndexAlpha(random uint64, laneLen, segLen, threads, pass, slice, lane, index uint32) uint32 {
+	refLane := uint32(random>>32) % threads
+	if pass == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	size, start := 3*segLen, ((slice+1)%syncPoints)*segLen
+	if lane == refLane {
+		size += index
+	}
+	if pass == 0 {
+		size, start = slice*segLen, 0
+		if slice == 0 || lane == refLane {
+			size += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		size--
+	}
+
+	x := random & 0xFFFFFFFF
+	x = (x * x) >> 32
+	x = (x * uint64(size)) >> 32
+
+	return refLane*laneLen + uint32((uint64(start)+uint64(size)-(x+1))%uint64(laneLen))
+}
+
+func argon2Extract(B []block, memory, threads, keyLen uint32) []byte {
+	laneLen := memory / threads
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range B[(lane*laneLen)+laneLen-1] {
+			B[memory-1][i] ^= v
+		}
+	}
+
+	var buf [1024]byte
+	for i, v := range B[memory-1] {
+		binary.LittleEndian.PutUint64(buf[i*8:], v)
+	}
+
+	key := make([]byte, keyLen)
+	blake2bHash(key, buf[:])
+
+	return key
+}
+
+// processBlock computes the compression function G of in1 and in2. The
+// result is XORed into out if xor is set, as required for all passes but
+// the first.
+func processBlock(out, in1, in2 *block, xor bool) {
+	var t block
+	for i := range t {
+		t[i] = in1[i] ^ in2[i]
+	}
+
+	for i := 0; i < blockLength; i += 16 {
+		blamka(&t[i+0], &t[i+1], &t[i+2], &t[i+3],
+			&t[i+4], &t[i+5], &t[i+6], &t[i+7],
+			&t[i+8], &t[i+9], &t[i+10], &t[i+11],
+			&t[i+12], &t[i+13], &t[i+14], &t[i+15])
+	}
+	for i := 0; i < blockLength/8; i += 2 {
+		blamka(&t[i], &t[i+1], &t[16+i], &t[16+i+1],
+			&t[32+i], &t[32+i+1], &t[48+i], &t[48+i+1],
+			&t[64+i], &t[64+i+1], &t[80+i], &t[80+i+1],
+			&t[96+i], &t[96+i+1], &t[112+i], &t[112+i+1])
+	}
+
+	for i := range t {
+		v := in1[i] ^ in2[i] ^ t[i]
+		if xor {
+			out[i] ^= v
+		} else {
+			out[i] = v
+		}
+	}
+}
+
+// blamka is the BLAKE2b round function with the multiplications added by
+// Argon2.
+func blamka(t00, t01, t02, t03, t04, t05, t06, t07, t08, t09, t10, t11, t12, t13, t14, t15 *uint64) {
+	gb(t00, t04, t08, t12)
+	gb(t01, t05, t09, t13)
+	gb(t02, t06, t10, t14)
+	gb(t03, t07, t11, t15)
+
+	gb(t00, t05, t10, t15)
+	gb(t01, t06, t11, t12)
+	gb(t02, t07, t08, t13)
+	gb(t03, t04, t09, t14)
+}
+
+func gb(a, b, c, d *uint64) {
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -32)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -24)
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -16)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -63)
+}
+
+// blake2bHash is the variable length hash function H' of RFC 9106.
+func blake2bHash(out []byte, in []byte) {
+	var b2 hash.Hash
+	if n := len(out); n < blake2b.Size {
+		b2, _ = blake2b.New(n, nil)
+	} else {
+		b2, _ = blake2b.New512(nil)
+	}
+
+	writeUint32(b2, uint32(len(out)))
+	_, _ = b2.Write(in)
+
+	if len(out) <= blake2b.Size {
+		b2.Sum(out[:0])
+
+		return
+	}
+
+	var buf [blake2b.Size]byte
+	b2.Sum(buf[:0])
+	copy(out, buf[:32])
+	out = out[32:]
+
+	for len(out) > blake2b.Size {
+		b2.Reset()
+		_, _ = b2.Write(buf[:])
+		b2.Sum(buf[:0])
+		copy(out, buf[:32])
+		out = out[32:]
+	}
+
+	// the last hash has the remaining length.
+	b2, _ = blake2b.New(len(out), nil)
+	_, _ = b2.Write(buf[:])
+	b2.Sum(out[:0])
+}
+
+func writeUint32(h hash.Hash, v uint32) {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	_, _ = h.Write(buf[:])
+}
@@ -0,0 +1,45 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/argon2"
+)
+
+func TestArgon2(t *testing.T) {
+	t.Parallel()
+
+	// test vectors from RFC 9106, section 5.
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+
+	for _, tc := range []struct {
+		name string
+		mode int
+		want string
+	}{
+		{"argon2d", argon2d, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{"argon2i", argon2i, "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+		{"argon2id", argon2id, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			key := argon2Key(tc.mode, password, salt, secret, data, 3, 32, 4, 32)
+			assert.Equal(t, tc.want, hex.EncodeToString(key))
+		})
+	}
+
+	t.Run("x/crypto", func(t *testing.T) {
+		t.Parallel()
+
+		want := argon2.IDKey([]byte("password"), []byte("somesalt"), 2, 256, 2, 100)
+		assert.Equal(t, want, argon2Key(argon2id, []byte("password"), []byte("somesalt"), nil, nil, 2, 256, 2, 100))
+	})
+}
@@ -0,0 +1,247 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/twofish"
+)
+
+// The UUIDs of the supported ciphers and key derivation functions.
+var (
+	cipherAES256   = uuid("31c1f2e6bf714350be5805216afc5aff")
+	cipherChaCha20 = uuid("d6038a2b8b6f4cb5a524339a31dbb59a")
+	cipherTwofish  = uuid("ad68f29f576f4bb9a36ad47af965346c")
+
+	kdfAES      = uuid("c9d9f39a628a4460bf740d08c18a4fea")
+	kdfArgon2d  = uuid("ef636ddf8c29444b91f7a9a403e30a0c")
+	kdfArgon2id = uuid("9e298b1956db4773b23dfc3ec6f0a1e6")
+)
+
+// innerStreamChaCha20 is the only inner random stream used by KDBX 4.
+const innerStreamChaCha20 = 3
+
+func uuid(s string) [16]byte {
+	var u [16]byte
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+// transformKey derives the transformed key from the composite key using
+// the key derivation function described by params.
+func transformKey(composite []byte, params variantDict) ([]byte, error) {
+	raw, _ := params["$UUID"].([]byte)
+	if len(raw) != 16 {
+		return nil, fmt.Errorf("missing KDF UUID")
+	}
+
+	var id [16]byte
+	copy(id[:], raw)
+	salt, _ := params["S"].([]byte)
+
+	switch id {
+	case kdfAES:
+		rounds, _ := params["R"].(uint64)
+		if len(salt) != 32 {
+			return nil, fmt.Errorf("invalid AES-KDF seed")
+		}
+
+		return aesKDF(composite, salt, rounds)
+	case kdfArgon2d, kdfArgon2id:
+		mode := argon2d
+		if id == kdfArgon2id {
+			mode = argon2id
+		}
+
+		iterations, _ := params["I"].(uint64)
+		memory, _ := params["M"].(uint64)
+		parallelism, _ := params["P"].(uint32)
+		secret, _ := params["K"].([]byte)
+		data, _ := params["A"].([]byte)
+		if iterations < 1 || memory < 1024 || parallelism < 1 {
+			return nil, fmt.Errorf("invalid Argon2 parameters")
+		}
+
+		return argon2Key(mode, composite, salt, secret, data, uint32(iterations), uint32(memory/1024), parallelism, 32), nil
+	default:
+		return nil, fmt.Errorf("unsupported KDF %x", id)
+	}
+}
+
+func aesKDF(composite, seed []byte, rounds uint64) ([]byte, error) {
+	c, err := aes.NewCipher(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	key := make([]byte, len(composite))
+	copy(key, composite)
+
+	for i := uint64(0); i < rounds; i++ {
+		c.Encrypt(key[:16], key[:16])
+		c.Encrypt(key[16:], key[16:])
+	}
+
+	sum := sha256.Sum256(key)
+
+	return sum[:], nil
+}
+
+// deriveKeys returns the key for the payload cipher and the base key for
+// the HMACs.
+func deriveKeys(masterSeed, transformed []byte) ([]byte, []byte) {
+	ck := sha256.New()
+	_, _ = ck.Write(masterSeed)
+	_, _ = ck.Write(transformed)
+
+	hk := sha512.New()
+	_, _ = hk.Write(masterSeed)
+	_, _ = hk.Write(transformed)
+	_, _ = hk.Write([]byte{0x01})
+
+	return ck.Sum(nil), hk.Sum(nil)
+}
+
+// hmacKey returns the HMAC key for the block with the given index. The
+// header uses the index 2^64-1.
+func hmacKey(base []byte, index uint64) []byte {
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], index)
+
+	h := sha512.New()
+	_, _ = h.Write(idx[:])
+	_, _ = h.Write(base)
+
+	return h.Sum(nil)
+}
+
+// headerHMAC computes the HMAC of the raw header.
+func headerHMAC(base, header []byte) []byte {
+	mac := hmac.New(sha256.New, hmacKey(base, ^uint64(0)))
+	_, _ = mac.Write(header)
+
+	return mac.Sum(nil)
+}
+
+// blockHMAC computes the HMAC of a single block of the payload.
+func blockHMAC(base []byte, index uint64, data []byte) []byte {
+	var hdr [12]byte
+	binary.LittleEndian.PutUint64(hdr[:8], index)
+	binary.LittleEndian.PutUint32(hdr[8:], uint32(len(data)))
+
+	mac := hmac.New(sha256.New, hmacKey(base, index))
+	_, _ = mac.Write(hdr[:])
+	_, _ = mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// readBlocks reads the HMAC block stream that follows the header.
+func readBlocks(buf, hmacBase []byte) ([]byte, error) {
+	var out bytes.Buffer
+
+	for index := uint64(0); ; index++ {
+		if len(buf) < 36 {
+			return nil, fmt.Errorf("truncated block %d: %w", index, ErrInvalidFile)
+		}
+
+		mac := buf[:32]
+		size := binary.LittleEndian.Uint32(buf[32:36])
+		buf = buf[36:]
+		if uint64(size) > uint64(len(buf)) {
+			return nil, fmt.Errorf("truncated block %d: %w", index, ErrInvalidFile)
+		}
+
+		data := buf[:size]
+		buf = buf[size:]
+		if !hmac.Equal(mac, blockHMAC(hmacBase, index, data)) {
+			return nil, fmt.Errorf("invalid HMAC for block %d: %w", index, ErrInvalidFile)
+		}
+
+		if size == 0 {
+			return out.Bytes(), nil
+		}
+
+		out.Write(data)
+	}
+}
+
+// decryptPayload decrypts the payload with the cipher given in the header.
+func decryptPayload(cipherID [16]byte, key, iv, payload []byte) ([]byte, error) {
+	switch cipherID {
+	case cipherChaCha20:
+		c, err := chacha20.NewUnauthenticatedCipher(key, iv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+
+		out := make([]byte, len(payload))
+		c.XORKeyStream(out, payload)
+
+		return out, nil
+	case cipherAES256, cipherTwofish:
+		var b cipher.Block
+		var err error
+		if cipherID == cipherAES256 {
+			b, err = aes.NewCipher(key)
+		} else {
+			b, err = twofish.NewCipher(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+
+		if len(iv) != b.BlockSize() || len(payload)%b.BlockSize() != 0 {
+			return nil, fmt.Errorf("invalid payload size: %w", ErrInvalidFile)
+		}
+
+		out := make([]byte, len(payload))
+		cipher.NewCBCDecrypter(b, iv).CryptBlocks(out, payload)
+
+		return unpad(out, b.BlockSize())
+	default:
+		return nil, fmt.Errorf("unsupported cipher %x", cipherID)
+	}
+}
+
+func unpad(buf []byte, blockSize int) ([]byte, error) {
+	if len(buf) < 1 {
+		return nil, errors.New("empty payload")
+	}
+
+	n := int(buf[len(buf)-1])
+	if n < 1 || n > blockSize || n > len(buf) {
+		return nil, fmt.Errorf("invalid padding: %w", ErrInvalidFile)
+	}
+
+	return buf[:len(buf)-n], nil
+}
+
+// innerStream decrypts protected values. It returns the key stream of
+// ChaCha20 keyed with the SHA-512 hash of the inner stream key.
+func innerStream(id uint32, key []byte) (*chacha20.Cipher, error) {
+	if id != innerStreamChaCha20 {
+		return nil, fmt.Errorf("unsupported inner random stream %d", id)
+	}
+
+	h := sha512.Sum512(key)
+
+	c, err := chacha20.NewUnauthenticatedCipher(h[:32], h[32:44])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inner stream: %w", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,177 @@
+package kdbx
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	signature1 = 0x9AA2D903
+	signature2 = 0xB54BFB67
+
+	versionMajor = 4
+)
+
+// Outer header field IDs.
+const (
+	hdrEnd         = 0
+	hdrCipherID    = 2
+	hdrCompression = 3
+	hdrMasterSeed  = 4
+	hdrIV          = 7
+	hdrKDF         = 11
+)
+
+// Inner header field IDs.
+const (
+	innerEnd       = 0
+	innerStreamID  = 1
+	innerStreamKey = 2
+	innerBinary    = 3
+)
+
+// header is the unencrypted outer header.
+type header struct {
+	cipherID   [16]byte
+	compressed bool
+	masterSeed []byte
+	iv         []byte
+	kdf        variantDict
+}
+
+// readHeader parses the outer header and returns it together with its
+// raw bytes.
+func readHeader(buf []byte) (*header, []byte, error) {
+	if len(buf) < 12 || binary.LittleEndian.Uint32(buf) != signature1 || binary.LittleEndian.Uint32(buf[4:]) != signature2 {
+		return nil, nil, ErrInvalidFile
+	}
+
+	if major := binary.LittleEndian.Uint16(buf[10:]); major != versionMajor {
+		return nil, nil, ErrUnsupportedVersion
+	}
+
+	h := &header{}
+	pos := 12
+	for {
+		if len(buf) < pos+5 {
+			return nil, nil, fmt.Errorf("truncated header: %w", ErrInvalidFile)
+		}
+
+		id := buf[pos]
+		size := int(binary.LittleEndian.Uint32(buf[pos+1:]))
+		pos += 5
+		if size < 0 || len(buf) < pos+size {
+			return nil, nil, fmt.Errorf("truncated header: %w", ErrInvalidFile)
+		}
+
+		data := buf[pos : pos+size]
+		pos += size
+
+		switch id {
+		case hdrEnd:
+			if h.kdf == nil || len(h.masterSeed) != 32 {
+				return nil, nil, fmt.Errorf("incomplete header: %w", ErrInvalidFile)
+			}
+
+			return h, buf[:pos], nil
+		case hdrCipherID:
+			copy(h.cipherID[:], data)
+		case hdrCompression:
+			h.compressed = len(data) == 4 && binary.LittleEndian.Uint32(data) == 1
+		case hdrMasterSeed:
+			h.masterSeed = data
+		case hdrIV:
+			h.iv = data
+		case hdrKDF:
+			kdf, err := readVariantDict(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			h.kdf = kdf
+		}
+	}
+}
+
+// variantDict is the typed key-value store used for the KDF parameters.
+type variantDict map[string]any
+
+// Variant dictionary value types.
+const (
+	vdEnd       = 0x00
+	vdUint32    = 0x04
+	vdUint64    = 0x05
+	vdBool      = 0x08
+	vdInt32     = 0x0C
+	vdInt64     = 0x0D
+	vdString    = 0x18
+	vdByteArray = 0x42
+
+	vdVersion = 0x0100
+)
+
+func readVariantDict(buf []byte) (variantDict, error) {
+	errTrunc := fmt.Errorf("truncated KDF parameters: %w", ErrInvalidFile)
+
+	if len(buf) < 2 || binary.LittleEndian.Uint16(buf)&0xFF00 != vdVersion {
+		return nil, fmt.Errorf("unsupported KDF parameters: %w", ErrInvalidFile)
+	}
+
+	vd := variantDict{}
+	buf = buf[2:]
+	for {
+		if len(buf) < 1 {
+			return nil, errTrunc
+		}
+
+		typ := buf[0]
+		if typ == vdEnd {
+			return vd, nil
+		}
+
+		if len(buf) < 5 {
+			return nil, errTrunc
+		}
+		kl := int(binary.LittleEndian.Uint32(buf[1:]))
+		buf = buf[5:]
+		if kl < 0 || len(buf) < kl+4 {
+			return nil, errTrunc
+		}
+		key := string(buf[:kl])
+		vl := int(binary.LittleEndian.Uint32(buf[kl:]))
+		buf = buf[kl+4:]
+		if vl < 0 || len(buf) < vl {
+			return nil, errTrunc
+		}
+		val := buf[:vl]
+		buf = buf[vl:]
+
+		switch typ {
+		case vdUint32, vdInt32:
+			if vl != 4 {
+				return nil, errTrunc
+			}
+			if typ == vdUint32 {
+				vd[key] = binary.LittleEndian.Uint32(val)
+			} else {
+				vd[key] = int32(binary.LittleEndian.Uint32(val))
+			}
+		case vdUint64, vdInt64:
+			if vl != 8 {
+				return nil, errTrunc
+			}
+			if typ == vdUint64 {
+				vd[key] = binary.LittleEndian.Uint64(val)
+			} else {
+				vd[key] = int64(binary.LittleEndian.Uint64(val))
+			}
+		case vdBool:
+			vd[key] = vl == 1 && val[0] != 0
+		case vdString:
+			vd[key] = string(val)
+		case vdByteArray:
+			vd[key] = append([]byte{}, val...)
+		default:
+			return nil, fmt.Errorf("unknown KDF parameter type %#x: %w", typ, ErrInvalidFile)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package kdbx reads KeePass databases in the KDBX 4 format.
+//
+// Only the parts of the format that are needed to move secrets in and out
+// of gopass are supported: groups, entries with their string fields and
+// attachments. The entry history, icons and custom data are ignored.
+// Supported are the AES-256, ChaCha20 and Twofish ciphers and the
+// AES-KDF, Argon2d and Argon2id key derivation functions.
+package kdbx
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidFile is returned if the input is not a KeePass database.
+	ErrInvalidFile = errors.New("not a KeePass database")
+	// ErrUnsupportedVersion is returned for databases other than KDBX 4.
+	ErrUnsupportedVersion = errors.New("unsupported KDBX version, please save the database in the KDBX 4 format")
+	// ErrInvalidCredentials is returned if the database can not be
+	// decrypted with the given credentials.
+	ErrInvalidCredentials = errors.New("invalid credentials or corrupted database")
+)
+
+// Standard field names of an entry.
+const (
+	FieldTitle    = "Title"
+	FieldUserName = "UserName"
+	FieldPassword = "Password"
+	FieldURL      = "URL"
+	FieldNotes    = "Notes"
+)
+
+// Database is a decrypted KeePass database.
+type Database struct {
+	Name string
+	Root *Group
+}
+
+// Group is a folder in the database.
+type Group struct {
+	Name    string
+	Groups  []*Group
+	Entries []*Entry
+}
+
+// Entry is a single entry. Fields are kept in their original order.
+type Entry struct {
+	Fields      []Field
+	Attachments []Attachment
+}
+
+// Field is a string field of an entry.
+type Field struct {
+	Key       string
+	Value     string
+	Protected bool
+}
+
+// Attachment is a file attached to an entry.
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// Get returns the value of the given field.
+func (e *Entry) Get(key string) string {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+
+	return ""
+}
+
+// Credentials are used to unlock a database. KeyFile holds the content of
+// the key file, if any.
+type Credentials struct {
+	Password string
+	KeyFile  []byte
+}
+
+// compositeKey combines the password and the key file as described in
+// the KeePass documentation.
+func (c Credentials) compositeKey() ([]byte, error) {
+	h := sha256.New()
+
+	if c.Password != "" || len(c.KeyFile) < 1 {
+		pw := sha256.Sum256([]byte(c.Password))
+		_, _ = h.Write(pw[:])
+	}
+
+	if len(c.KeyFile) > 0 {
+		kf, err := keyFileHash(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = h.Write(kf)
+	}
+
+	return h.Sum(nil), nil
+}
+
+type keyFileXML struct {
+	Version string `xml:"Meta>Version"`
+	Data    string `xml:"Key>Data"`
+}
+
+// keyFileHash returns the 32 byte key from a key file. XML key files
+// (version 1.0 and 2.0), raw 32 byte keys and hex encoded keys are used
+// as they are, all other files are hashed.
+func keyFileHash(buf []byte) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(string(buf)), "<?xml") {
+		var kf keyFileXML
+		if err := xml.Unmarshal(buf, &kf); err != nil {
+			return nil, fmt.Errorf("failed to parse key file: %w", err)
+		}
+
+		if strings.HasPrefix(kf.Version, "2.") {
+			key, err := hex.DecodeString(strings.Join(strings.Fields(kf.Data), ""))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode key file: %w", err)
+			}
+
+			return key, nil
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(kf.Data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key file: %w", err)
+		}
+
+		return key, nil
+	}
+
+	if len(buf) == 32 {
+		return buf, nil
+	}
+
+	if len(buf) == 64 {
+		if key, err := hex.DecodeString(string(buf)); err == nil {
+			return key, nil
+		}
+	}
+
+	sum := sha256.Sum256(buf)
+
+	return sum[:], nil
+}
@@ -0,0 +1,108 @@
+package kdbx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// keep the tests fast.
+	argon2Params.iterations = 1
+	argon2Params.memory = 1 << 20
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	db := &Database{
+		Name: "test",
+		Root: &Group{
+			Name: "Root",
+			Entries: []*Entry{
+				{
+					Fields: []Field{
+						{Key: FieldTitle, Value: "example.org"},
+						{Key: FieldUserName, Value: "alice"},
+						{Key: FieldPassword, Value: "s3cr3t & <more>"},
+						{Key: "otp", Value: "otpauth://totp/example?secret=JBSWY3DPEHPK3PXP", Protected: true},
+					},
+					Attachments: []Attachment{{Name: "key.bin", Data: []byte{0, 1, 2, 3}}},
+				},
+			},
+			Groups: []*Group{
+				{
+					Name: "Internet",
+					Entries: []*Entry{
+						{Fields: []Field{{Key: FieldTitle, Value: "empty"}}},
+					},
+				},
+			},
+		},
+	}
+
+	creds := Credentials{Password: "foobar"}
+	buf := &bytes.Buffer{}
+	require.NoError(t, Encode(buf, db, creds))
+
+	_, err := Decode(bytes.NewReader(buf.Bytes()), Credentials{Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	got, err := Decode(bytes.NewReader(buf.Bytes()), creds)
+	require.NoError(t, err)
+	assert.Equal(t, "test", got.Name)
+	require.Len(t, got.Root.Entries, 1)
+
+	e := got.Root.Entries[0]
+	assert.Equal(t, "example.org", e.Get(FieldTitle))
+	assert.Equal(t, "alice", e.Get(FieldUserName))
+	assert.Equal(t, "s3cr3t & <more>", e.Get(FieldPassword))
+	assert.Equal(t, "otpauth://totp/example?secret=JBSWY3DPEHPK3PXP", e.Get("otp"))
+	assert.Equal(t, []Attachment{{Name: "key.bin", Data: []byte{0, 1, 2, 3}}}, e.Attachments)
+
+	require.Len(t, got.Root.Groups, 1)
+	assert.Equal(t, "Internet", got.Root.Groups[0].Name)
+	assert.Equal(t, "", got.Root.Groups[0].Entries[0].Get(FieldPassword))
+}
+
+func TestKeyFile(t *testing.T) {
+	t.Parallel()
+
+	db := &Database{Root: &Group{Name: "Root"}}
+
+	for _, kf := range [][]byte{
+		[]byte("some random key file content"),
+		bytes.Repeat([]byte{0x42}, 32),
+		[]byte(`<?xml version="1.0" encoding="utf-8"?>
+<KeyFile>
+	<Meta><Version>2.0</Version></Meta>
+	<Key><Data Hash="00000000">
+		A7007945 D07D54BA 28DF6434 1B4500FC
+		9750DFB1 D36ADA2D 9C32DC19 4C7AB01B
+	</Data></Key>
+</KeyFile>`),
+	} {
+		creds := Credentials{KeyFile: kf}
+		buf := &bytes.Buffer{}
+		require.NoError(t, Encode(buf, db, creds))
+
+		_, err := Decode(bytes.NewReader(buf.Bytes()), creds)
+		require.NoError(t, err)
+
+		_, err = Decode(bytes.NewReader(buf.Bytes()), Credentials{Password: "foo", KeyFile: kf})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Decode(bytes.NewReader([]byte("foo")), Credentials{})
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	// KDBX 3.1 header.
+	_, err = Decode(bytes.NewReader([]byte{0x03, 0xd9, 0xa2, 0x9a, 0x67, 0xfb, 0x4b, 0xb5, 0x01, 0x00, 0x03, 0x00}), Credentials{})
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
@@ -0,0 +1,265 @@
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// Decode reads and decrypts a KDBX 4 database. Entries in the recycle bin
+// are omitted.
+func Decode(r io.Reader, creds Credentials) (*Database, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database: %w", err)
+	}
+
+	hdr, raw, err := readHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	buf = buf[len(raw):]
+
+	if len(buf) < 64 {
+		return nil, fmt.Errorf("truncated header: %w", ErrInvalidFile)
+	}
+	if sum := sha256.Sum256(raw); !bytes.Equal(sum[:], buf[:32]) {
+		return nil, fmt.Errorf("header checksum mismatch: %w", ErrInvalidFile)
+	}
+
+	composite, err := creds.compositeKey()
+	if err != nil {
+		return nil, err
+	}
+
+	transformed, err := transformKey(composite, hdr.kdf)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherKey, hmacBase := deriveKeys(hdr.masterSeed, transformed)
+	if !hmac.Equal(buf[32:64], headerHMAC(hmacBase, raw)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	payload, err := readBlocks(buf[64:], hmacBase)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err = decryptPayload(hdr.cipherID, cipherKey, hdr.iv, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if hdr.compressed {
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress payload: %w", err)
+		}
+
+		payload, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress payload: %w", err)
+		}
+	}
+
+	stream, binaries, payload, err := readInnerHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeXML(payload, stream, binaries)
+}
+
+// readInnerHeader parses the inner header and returns the inner random
+// stream, the attachments and the remaining XML document.
+func readInnerHeader(buf []byte) (*chacha20.Cipher, [][]byte, []byte, error) {
+	var streamID uint32
+	var streamKey []byte
+	var binaries [][]byte
+
+	for {
+		if len(buf) < 5 {
+			return nil, nil, nil, fmt.Errorf("truncated inner header: %w", ErrInvalidFile)
+		}
+
+		id := buf[0]
+		size := int(binary.LittleEndian.Uint32(buf[1:]))
+		buf = buf[5:]
+		if size < 0 || len(buf) < size {
+			return nil, nil, nil, fmt.Errorf("truncated inner header: %w", ErrInvalidFile)
+		}
+
+		data := buf[:size]
+		buf = buf[size:]
+
+		switch id {
+		case innerEnd:
+			stream, err := innerStream(streamID, streamKey)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			return stream, binaries, buf, nil
+		case innerStreamID:
+			if len(data) == 4 {
+				streamID = binary.LittleEndian.Uint32(data)
+			}
+		case innerStreamKey:
+			streamKey = data
+		case innerBinary:
+			if len(data) < 1 {
+				return nil, nil, nil, fmt.Errorf("invalid attachment: %w", ErrInvalidFile)
+			}
+			// the first byte holds flags.
+			binaries = append(binaries, data[1:])
+		}
+	}
+}
+
+func decodeXML(buf []byte, stream *chacha20.Cipher, binaries [][]byte) (*Database, error) {
+	buf, err := unprotect(buf, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc xmlFile
+	if err := xml.Unmarshal(buf, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	recycleBin := ""
+	if doc.Meta.RecycleBinEnabled == "True" {
+		recycleBin = doc.Meta.RecycleBinUUID
+	}
+
+	root, err := convertGroup(doc.Root.Group, recycleBin, binaries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Database{
+		Name: doc.Meta.DatabaseName,
+		Root: root,
+	}, nil
+}
+
+func convertGroup(xg xmlGroup, recycleBin string, binaries [][]byte) (*Group, error) {
+	g := &Group{
+		Name: xg.Name,
+	}
+
+	for _, xe := range xg.Entries {
+		e := &Entry{}
+		for _, s := range xe.Strings {
+			e.Fields = append(e.Fields, Field{
+				Key:       s.Key,
+				Value:     s.Value.Value,
+				Protected: s.Value.Protected == "True",
+			})
+		}
+
+		for _, b := range xe.Binaries {
+			if b.Value.Ref < 0 || b.Value.Ref >= len(binaries) {
+				return nil, fmt.Errorf("invalid attachment reference %d: %w", b.Value.Ref, ErrInvalidFile)
+			}
+			e.Attachments = append(e.Attachments, Attachment{
+				Name: b.Key,
+				Data: binaries[b.Value.Ref],
+			})
+		}
+
+		g.Entries = append(g.Entries, e)
+	}
+
+	for _, sg := range xg.Groups {
+		if recycleBin != "" && sg.UUID == recycleBin {
+			continue
+		}
+
+		cg, err := convertGroup(sg, recycleBin, binaries)
+		if err != nil {
+			return nil, err
+		}
+		g.Groups = append(g.Groups, cg)
+	}
+
+	return g, nil
+}
+
+// unprotect replaces all protected values with their plain text. The
+// values are encrypted with a single key stream in document order, so
+// this has to happen before the document is unmarshaled.
+func unprotect(buf []byte, stream *chacha20.Cipher) ([]byte, error) {
+	return transformProtected(buf, func(in string) (string, error) {
+		ct, err := base64.StdEncoding.DecodeString(in)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode protected value: %w", err)
+		}
+		stream.XORKeyStream(ct, ct)
+
+		return string(ct), nil
+	})
+}
+
+// transformProtected applies fn to the content of all values with the
+// Protected attribute in document order.
+func transformProtected(buf []byte, fn func(string) (string, error)) ([]byte, error) {
+	var out bytes.Buffer
+
+	dec := xml.NewDecoder(bytes.NewReader(buf))
+	enc := xml.NewEncoder(&out)
+
+	protected := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			protected = false
+			if t.Name.Local == "Value" {
+				for _, a := range t.Attr {
+					if a.Name.Local == "Protected" && a.Value == "True" {
+						protected = true
+					}
+				}
+			}
+		case xml.EndElement:
+			protected = false
+		case xml.CharData:
+			if protected {
+				v, err := fn(string(t))
+				if err != nil {
+					return nil, err
+				}
+				tok = xml.CharData(v)
+			}
+		}
+
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return nil, fmt.Errorf("failed to encode XML: %w", err)
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to encode XML: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
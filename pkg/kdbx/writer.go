@@ -0,0 +1,398 @@
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// blockSize is the size of the blocks of the HMAC block stream.
+const blockSize = 1 << 20
+
+// argon2Params are the KDF parameters of new databases. These are the
+// defaults of KeePass.
+var argon2Params = struct {
+	iterations  uint64
+	memory      uint64
+	parallelism uint32
+}{
+	iterations:  2,
+	memory:      64 << 20,
+	parallelism: 2,
+}
+
+// Encode writes db as a KDBX 4 database encrypted with AES-256 and a key
+// derived with Argon2d. Password fields are marked as protected.
+func Encode(w io.Writer, db *Database, creds Credentials) error {
+	masterSeed, err := randomBytes(32)
+	if err != nil {
+		return err
+	}
+	iv, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	salt, err := randomBytes(32)
+	if err != nil {
+		return err
+	}
+	streamKey, err := randomBytes(64)
+	if err != nil {
+		return err
+	}
+
+	kdf := variantDict{
+		"$UUID": kdfArgon2d[:],
+		"S":     salt,
+		"I":     argon2Params.iterations,
+		"M":     argon2Params.memory,
+		"P":     argon2Params.parallelism,
+		"V":     uint32(argon2Version),
+	}
+
+	composite, err := creds.compositeKey()
+	if err != nil {
+		return err
+	}
+
+	transformed, err := transformKey(composite, kdf)
+	if err != nil {
+		return err
+	}
+	cipherKey, hmacBase := deriveKeys(masterSeed, transformed)
+
+	payload, err := encodePayload(db, streamKey)
+	if err != nil {
+		return err
+	}
+
+	payload, err = encryptPayload(cipherKey, iv, payload)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	hdr := writeHeader(masterSeed, iv, kdf)
+	out.Write(hdr)
+	sum := sha256.Sum256(hdr)
+	out.Write(sum[:])
+	out.Write(headerHMAC(hmacBase, hdr))
+	writeBlocks(&out, payload, hmacBase)
+
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("failed to write database: %w", err)
+	}
+
+	return nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	return buf, nil
+}
+
+func writeHeader(masterSeed, iv []byte, kdf variantDict) []byte {
+	var buf bytes.Buffer
+
+	writeUint32LE(&buf, signature1)
+	writeUint32LE(&buf, signature2)
+	writeUint32LE(&buf, versionMajor<<16)
+
+	field := func(id byte, data []byte) {
+		buf.WriteByte(id)
+		writeUint32LE(&buf, uint32(len(data)))
+		buf.Write(data)
+	}
+
+	var compression [4]byte
+	binary.LittleEndian.PutUint32(compression[:], 1)
+
+	field(hdrCipherID, cipherAES256[:])
+	field(hdrCompression, compression[:])
+	field(hdrMasterSeed, masterSeed)
+	field(hdrIV, iv)
+	field(hdrKDF, kdf.bytes())
+	field(hdrEnd, []byte("\r\n\r\n"))
+
+	return buf.Bytes()
+}
+
+// encodePayload returns the compressed inner header and XML document.
+func encodePayload(db *Database, streamKey []byte) ([]byte, error) {
+	var binaries [][]byte
+	doc := xmlFile{
+		Meta: xmlMeta{
+			Generator:         "gopass",
+			DatabaseName:      db.Name,
+			RecycleBinEnabled: "False",
+		},
+	}
+
+	root := db.Root
+	if root == nil {
+		root = &Group{Name: db.Name}
+	}
+
+	now := time.Now()
+	xg, err := newXMLGroup(root, now, &binaries)
+	if err != nil {
+		return nil, err
+	}
+	doc.Root.Group = xg
+
+	buf, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode XML: %w", err)
+	}
+
+	stream, err := innerStream(innerStreamChaCha20, streamKey)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err = transformProtected(append([]byte(xml.Header), buf...), func(in string) (string, error) {
+		ct := []byte(in)
+		stream.XORKeyStream(ct, ct)
+
+		return base64.StdEncoding.EncodeToString(ct), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var inner bytes.Buffer
+	field := func(id byte, data ...[]byte) {
+		size := 0
+		for _, d := range data {
+			size += len(d)
+		}
+		inner.WriteByte(id)
+		writeUint32LE(&inner, uint32(size))
+		for _, d := range data {
+			inner.Write(d)
+		}
+	}
+
+	var streamID [4]byte
+	binary.LittleEndian.PutUint32(streamID[:], innerStreamChaCha20)
+	field(innerStreamID, streamID[:])
+	field(innerStreamKey, streamKey)
+	for _, b := range binaries {
+		field(innerBinary, []byte{0x00}, b)
+	}
+	field(innerEnd)
+	inner.Write(buf)
+
+	var out bytes.Buffer
+	zw := gzip.NewWriter(&out)
+	if _, err := zw.Write(inner.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
+
+func newXMLGroup(g *Group, now time.Time, binaries *[][]byte) (xmlGroup, error) {
+	id, err := newUUID()
+	if err != nil {
+		return xmlGroup{}, err
+	}
+
+	xg := xmlGroup{
+		UUID:  id,
+		Name:  g.Name,
+		Times: newXMLTimes(now),
+	}
+
+	for _, e := range g.Entries {
+		id, err := newUUID()
+		if err != nil {
+			return xmlGroup{}, err
+		}
+
+		xe := xmlEntry{
+			UUID:  id,
+			Times: newXMLTimes(now),
+		}
+
+		for _, f := range e.Fields {
+			xs := xmlString{Key: f.Key, Value: xmlValue{Value: f.Value}}
+			if f.Protected || f.Key == FieldPassword {
+				xs.Value.Protected = "True"
+			}
+			xe.Strings = append(xe.Strings, xs)
+		}
+		// KeePass expects the standard fields to be present.
+		xe.Strings = addMissingFields(xe.Strings)
+
+		for _, a := range e.Attachments {
+			xe.Binaries = append(xe.Binaries, xmlBinary{Key: a.Name, Value: xmlBinRef{Ref: len(*binaries)}})
+			*binaries = append(*binaries, a.Data)
+		}
+
+		xg.Entries = append(xg.Entries, xe)
+	}
+
+	for _, sg := range g.Groups {
+		xsg, err := newXMLGroup(sg, now, binaries)
+		if err != nil {
+			return xmlGroup{}, err
+		}
+		xg.Groups = append(xg.Groups, xsg)
+	}
+
+	return xg, nil
+}
+
+func addMissingFields(fields []xmlString) []xmlString {
+	have := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		have[f.Key] = true
+	}
+
+	for _, k := range []string{FieldTitle, FieldUserName, FieldPassword, FieldURL, FieldNotes} {
+		if have[k] {
+			continue
+		}
+		xs := xmlString{Key: k}
+		if k == FieldPassword {
+			xs.Value.Protected = "True"
+		}
+		fields = append(fields, xs)
+	}
+
+	return fields
+}
+
+func newUUID() (string, error) {
+	buf, err := randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+func encryptPayload(key, iv, payload []byte) ([]byte, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	n := aes.BlockSize - len(payload)%aes.BlockSize
+	padded := make([]byte, len(payload)+n)
+	copy(padded, payload)
+	for i := len(payload); i < len(padded); i++ {
+		padded[i] = byte(n)
+	}
+
+	cipher.NewCBCEncrypter(b, iv).CryptBlocks(padded, padded)
+
+	return padded, nil
+}
+
+func writeBlocks(out *bytes.Buffer, payload, hmacBase []byte) {
+	for index := uint64(0); ; index++ {
+		n := len(payload)
+		if n > blockSize {
+			n = blockSize
+		}
+
+		data := payload[:n]
+		payload = payload[n:]
+
+		out.Write(blockHMAC(hmacBase, index, data))
+		writeUint32LE(out, uint32(len(data)))
+		out.Write(data)
+
+		if n == 0 {
+			return
+		}
+	}
+}
+
+func writeUint32LE(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+// bytes serializes the dictionary. The keys are sorted to get a stable
+// encoding.
+func (vd variantDict) bytes() []byte {
+	var buf bytes.Buffer
+
+	var version [2]byte
+	binary.LittleEndian.PutUint16(version[:], vdVersion)
+	buf.Write(version[:])
+
+	keys := make([]string, 0, len(vd))
+	for k := range vd {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var typ byte
+		var val []byte
+
+		switch v := vd[k].(type) {
+		case uint32:
+			typ = vdUint32
+			val = make([]byte, 4)
+			binary.LittleEndian.PutUint32(val, v)
+		case uint64:
+			typ = vdUint64
+			val = make([]byte, 8)
+			binary.LittleEndian.PutUint64(val, v)
+		case bool:
+			typ = vdBool
+			val = []byte{0}
+			if v {
+				val[0] = 1
+			}
+		case int32:
+			typ = vdInt32
+			val = make([]byte, 4)
+			binary.LittleEndian.PutUint32(val, uint32(v))
+		case int64:
+			typ = vdInt64
+			val = make([]byte, 8)
+			binary.LittleEndian.PutUint64(val, uint64(v))
+		case string:
+			typ = vdString
+			val = []byte(v)
+		case []byte:
+			typ = vdByteArray
+			val = v
+		default:
+			continue
+		}
+
+		buf.WriteByte(typ)
+		writeUint32LE(&buf, uint32(len(k)))
+		buf.WriteString(k)
+		writeUint32LE(&buf, uint32(len(val)))
+		buf.Write(val)
+	}
+	buf.WriteByte(vdEnd)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,91 @@
+package kdbx
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"time"
+)
+
+// The XML document inside of the encrypted payload. Only the elements
+// that are needed for the Database model are mapped.
+
+type xmlFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    xmlMeta  `xml:"Meta"`
+	Root    xmlRoot  `xml:"Root"`
+}
+
+type xmlMeta struct {
+	Generator         string `xml:"Generator,omitempty"`
+	DatabaseName      string `xml:"DatabaseName"`
+	RecycleBinEnabled string `xml:"RecycleBinEnabled,omitempty"`
+	RecycleBinUUID    string `xml:"RecycleBinUUID,omitempty"`
+}
+
+type xmlRoot struct {
+	Group xmlGroup `xml:"Group"`
+}
+
+type xmlGroup struct {
+	UUID    string     `xml:"UUID"`
+	Name    string     `xml:"Name"`
+	Times   *xmlTimes  `xml:"Times,omitempty"`
+	Entries []xmlEntry `xml:"Entry"`
+	Groups  []xmlGroup `xml:"Group"`
+}
+
+type xmlEntry struct {
+	UUID     string      `xml:"UUID"`
+	Times    *xmlTimes   `xml:"Times,omitempty"`
+	Strings  []xmlString `xml:"String"`
+	Binaries []xmlBinary `xml:"Binary"`
+}
+
+type xmlTimes struct {
+	CreationTime         string `xml:"CreationTime"`
+	LastModificationTime string `xml:"LastModificationTime"`
+	LastAccessTime       string `xml:"LastAccessTime"`
+	ExpiryTime           string `xml:"ExpiryTime"`
+	Expires              string `xml:"Expires"`
+	UsageCount           int    `xml:"UsageCount"`
+	LocationChanged      string `xml:"LocationChanged"`
+}
+
+type xmlString struct {
+	Key   string   `xml:"Key"`
+	Value xmlValue `xml:"Value"`
+}
+
+type xmlValue struct {
+	Protected string `xml:"Protected,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+type xmlBinary struct {
+	Key   string    `xml:"Key"`
+	Value xmlBinRef `xml:"Value"`
+}
+
+type xmlBinRef struct {
+	Ref int `xml:"Ref,attr"`
+}
+
+// epochOffset is the number of seconds between 0001-01-01 and the Unix
+// epoch. KDBX 4 stores times as seconds since 0001-01-01.
+const epochOffset = 62135596800
+
+func newXMLTimes(t time.Time) *xmlTimes {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(t.Unix()+epochOffset))
+	ts := base64.StdEncoding.EncodeToString(buf[:])
+
+	return &xmlTimes{
+		CreationTime:         ts,
+		LastModificationTime: ts,
+		LastAccessTime:       ts,
+		ExpiryTime:           ts,
+		Expires:              "False",
+		LocationChanged:      ts,
+	}
+}
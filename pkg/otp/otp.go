@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"image/png"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/kpitt/gopass/internal/out"
@@ -105,3 +107,63 @@ var (
 	// ErrType is returned when the secret is not a valid OTP type.
 	ErrType = fmt.Errorf("type assertion failed")
 )
+
+// ParseDigits returns the number of digits from an otpauth URL. It defaults
+// to six digits.
+//
+// ParseDigits and ParseAlgorithm can be replaced if https://github.com/pquerna/otp/pull/74 is merged.
+func ParseDigits(ku string) otp.Digits {
+	u, err := url.Parse(ku)
+	if err != nil {
+		debug.Log("Failed to parse key URL: %s", err)
+
+		// return the most common value
+		return otp.DigitsSix
+	}
+
+	q := u.Query()
+	iv, err := strconv.ParseUint(q.Get("digits"), 10, 64)
+	if err != nil {
+		debug.Log("Failed to parse digits param: %s", err)
+
+		// return the most common value
+		return otp.DigitsSix
+	}
+
+	switch iv {
+	case 6:
+		return otp.DigitsSix
+	case 8:
+		return otp.DigitsEight
+	default:
+		debug.Log("Unsupported digits value: %d", iv)
+
+		// return the most common value
+		return otp.DigitsSix
+	}
+}
+
+// ParseAlgorithm returns the hash algorithm from an otpauth URL. It defaults
+// to SHA-1.
+func ParseAlgorithm(ku string) otp.Algorithm {
+	u, err := url.Parse(ku)
+	if err != nil {
+		debug.Log("Failed to parse key URL: %s", err)
+
+		// return the most common value
+		return otp.AlgorithmSHA1
+	}
+
+	q := u.Query()
+	a := strings.ToLower(q.Get("algorithm"))
+	switch a {
+	case "md5":
+		return otp.AlgorithmMD5
+	case "sha256":
+		return otp.AlgorithmSHA256
+	case "sha512":
+		return otp.AlgorithmSHA512
+	default:
+		return otp.AlgorithmSHA1
+	}
+}